@@ -0,0 +1,127 @@
+package filebrowser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// activityLargest is the number of largest files listed by ActivityReport
+const activityLargest = 10
+
+// Activity summarizes the files below a directory added or modified in a time window,
+// as reported by ActivityReport
+type Activity struct {
+	Root  string    `json:"root"`
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"` // Time the report was made
+	Files int       `json:"files"`
+	Bytes int64     `json:"bytes"`
+	// Largest are the largest files of the window, largest first
+	Largest []ActivityFile `json:"largest"`
+	// Days are the files and bytes per day in the location of Since, oldest first. Days
+	// without activity are left out.
+	Days []ActivityDay `json:"days"`
+}
+
+// ActivityFile is a file added or modified in the window of an Activity
+type ActivityFile struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// ActivityDay is the activity of a day of an Activity
+type ActivityDay struct {
+	Date  string `json:"date"` // e.g. 2024-05-01
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// ActivityReport summarizes the files below root modified after since, e.g. for weekly
+// publishing reports. Filebrowser only reports modification times, so added and modified
+// files are not told apart.
+func (c *Client) ActivityReport(root string, since time.Time) (*Activity, error) {
+	changed, err := c.ListChangedSince(root, since)
+	if err != nil {
+		return nil, err
+	}
+	return newActivity(root, since, time.Now(), changed), nil
+}
+
+// newActivity summarizes the changed files of a window
+func newActivity(root string, since time.Time, until time.Time, changed []RespResource) *Activity {
+	activity := &Activity{Root: root, Since: since, Until: until, Largest: []ActivityFile{}, Days: []ActivityDay{}}
+
+	days := map[string]*ActivityDay{}
+	for _, resource := range changed {
+		activity.Files++
+		activity.Bytes += resource.Size
+		activity.Largest = append(activity.Largest, ActivityFile{Path: resource.Path, Size: resource.Size, Modified: resource.Modified})
+
+		date := resource.Modified.In(since.Location()).Format(time.DateOnly)
+		day, ok := days[date]
+		if !ok {
+			day = &ActivityDay{Date: date}
+			days[date] = day
+		}
+		day.Files++
+		day.Bytes += resource.Size
+	}
+
+	// Largest first, by path for files of the same size
+	sort.Slice(activity.Largest, func(i, j int) bool {
+		if activity.Largest[i].Size == activity.Largest[j].Size {
+			return activity.Largest[i].Path < activity.Largest[j].Path
+		}
+		return activity.Largest[i].Size > activity.Largest[j].Size
+	})
+	if len(activity.Largest) > activityLargest {
+		activity.Largest = activity.Largest[:activityLargest]
+	}
+
+	for _, day := range days {
+		activity.Days = append(activity.Days, *day)
+	}
+	sort.Slice(activity.Days, func(i, j int) bool { return activity.Days[i].Date < activity.Days[j].Date })
+	return activity
+}
+
+// WriteText writes the report as plain text, e.g. for an email
+func (a *Activity) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Activity in %s from %s to %s\n", a.Root, a.Since.Format(time.DateTime), a.Until.Format(time.DateTime))
+	fmt.Fprintf(&b, "%d files, %s\n", a.Files, formatBytes(a.Bytes))
+
+	if len(a.Days) > 0 {
+		b.WriteString("\nBy day:\n")
+		for _, day := range a.Days {
+			fmt.Fprintf(&b, "  %s  %5d files  %10s\n", day.Date, day.Files, formatBytes(day.Bytes))
+		}
+	}
+	if len(a.Largest) > 0 {
+		b.WriteString("\nLargest files:\n")
+		for _, file := range a.Largest {
+			fmt.Fprintf(&b, "  %10s  %s\n", formatBytes(file.Size), file.Path)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatBytes formats a byte count with binary units, e.g. "1.5 MiB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
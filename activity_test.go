@@ -0,0 +1,44 @@
+package filebrowser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActivityReport(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	server := newTreeServer(t, map[string]testFile{
+		"old.txt":          {content: []byte("old"), modified: since.Add(-time.Hour)},
+		"a.txt":            {content: []byte("alpha"), modified: since.Add(time.Hour)},
+		"videos/big.mp4":   {content: bytes.Repeat([]byte("v"), 2048), modified: since.Add(26 * time.Hour)},
+		"videos/small.mp4": {content: []byte("vv"), modified: since.Add(27 * time.Hour)},
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	activity, err := client.ActivityReport("/", since)
+	if err != nil {
+		t.Fatalf("ActivityReport() error = %v", err)
+	}
+	if activity.Files != 3 || activity.Bytes != 2055 {
+		t.Errorf("activity = %d files, %d bytes, want 3 files, 2055 bytes", activity.Files, activity.Bytes)
+	}
+	if len(activity.Largest) != 3 || activity.Largest[0].Path != "/videos/big.mp4" || activity.Largest[2].Path != "/videos/small.mp4" {
+		t.Errorf("largest = %+v", activity.Largest)
+	}
+	want := []ActivityDay{{Date: "2024-05-01", Files: 1, Bytes: 5}, {Date: "2024-05-02", Files: 2, Bytes: 2050}}
+	if len(activity.Days) != 2 || activity.Days[0] != want[0] || activity.Days[1] != want[1] {
+		t.Errorf("days = %+v, want %+v", activity.Days, want)
+	}
+
+	var text strings.Builder
+	if err := activity.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"3 files, 2.0 KiB", "2024-05-02      2 files     2.0 KiB", "     2.0 KiB  /videos/big.mp4"} {
+		if !strings.Contains(text.String(), line) {
+			t.Errorf("text report lacks %q:\n%s", line, text.String())
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// Uploader uploads files to Filebrowser. It is implemented by *Client; code depending on
+// the SDK can accept it instead, to substitute a fake in its tests.
+type Uploader interface {
+	Upload(localPath string, remotePath string) (*UploadResult, error)
+	UploadWithOptions(localPath string, remotePath string, opts UploadOptions) (*UploadResult, error)
+	UploadWithOptionsContext(ctx context.Context, localPath string, remotePath string, opts UploadOptions) (*UploadResult, error)
+	UploadReader(r io.Reader, size int64, remotePath string) error
+	UploadStream(r io.Reader, remotePath string) error
+}
+
+// Sharer creates and lists shares of remote files. It is implemented by *Client.
+type Sharer interface {
+	Share(remotePath string, expires int64, password string, unit string) (string, error)
+	ShareContext(ctx context.Context, remotePath string, expires int64, password string, unit string) (string, error)
+	ShareWithExpiry(remotePath string, expiry ShareExpiry, password string) (string, error)
+	ShareIfExists(remotePath string, params ShareParams) (string, error)
+	ListShares(remotePath string) ([]ShareLink, error)
+}
+
+// ResourceAPI reads and manages remote files and directories. It is implemented by
+// *Client.
+type ResourceAPI interface {
+	GetResource(remotePath string) (*RespResource, error)
+	GetResourceContext(ctx context.Context, remotePath string) (*RespResource, error)
+	Download(remotePath string, w io.Writer) error
+	DownloadWithOptions(remotePath string, w io.Writer, opts DownloadOptions) error
+	DeleteResource(remotePath string) error
+	DeleteResourceContext(ctx context.Context, remotePath string) error
+	Move(src string, dst string, overwrite bool) error
+	MoveContext(ctx context.Context, src string, dst string, overwrite bool) error
+	Copy(src string, dst string, overwrite bool) error
+	CopyContext(ctx context.Context, src string, dst string, overwrite bool) error
+	MkdirAll(dir string) error
+}
+
+// FileBrowserAPI covers the operations of the Filebrowser API. It is implemented by
+// *Client and accepted by SaveAndShareWithAPI and Pipeline.RunWithAPI, so the SDK can be
+// mocked, e.g. with gomock or testify, or wrapped with middleware. The helpers composed of
+// these operations, such as Walk-based listings, sync and quotas, remain methods of *Client.
+type FileBrowserAPI interface {
+	Uploader
+	Sharer
+	ResourceAPI
+
+	// ServerURL returns the URL of the Filebrowser server, from which share links are built
+	ServerURL() string
+	Login() error
+	GetChecksum(remotePath string, algo string) (string, error)
+	GetChecksumContext(ctx context.Context, remotePath string, algo string) (string, error)
+	GetPreview(remotePath string, size string) (*Preview, error)
+	DownloadArchive(remoteDir string, format ArchiveFormat, w io.Writer) error
+	UpdateResource(remotePath string, opts UpdateOptions) error
+	Append(remotePath string, data []byte) error
+	Chmod(remotePath string, mode fs.FileMode) error
+	Walk(root string, fn WalkFunc) error
+	AbortUpload(uploadURL string) error
+	GetSettings() (*Settings, error)
+	UpdateSettings(settings *Settings) error
+}
+
+// Client implements the interfaces
+var (
+	_ Uploader       = (*Client)(nil)
+	_ Sharer         = (*Client)(nil)
+	_ ResourceAPI    = (*Client)(nil)
+	_ FileBrowserAPI = (*Client)(nil)
+)
+
+// ServerURL returns the URL of the Filebrowser server
+func (c *Client) ServerURL() string {
+	return c.URL
+}
+
+// SaveAndShareWithAPI is like SaveAndShare, running against api instead of a client
+// created from credentials, e.g. a mock in tests or a *Client wrapped with middleware
+func SaveAndShareWithAPI(api FileBrowserAPI, externalURL string, remotePathFn func(string) string, actionParams ActionParams) (*ShareResult, error) {
+	return (&Pipeline{}).RunWithAPI(context.Background(), api, externalURL, remotePathFn, actionParams)
+}
+
+// RunWithAPI is like RunContext, running against api instead of a client created from
+// credentials. The client settings of actionParams, such as Retry or PreUploadHook, are
+// left to api. Unless api is a *Client, PipelineState.Client is nil, so custom stages
+// must use PipelineState.API.
+func (p *Pipeline) RunWithAPI(ctx context.Context, api FileBrowserAPI, externalURL string, remotePathFn func(string) string, actionParams ActionParams) (*ShareResult, error) {
+	if api == nil {
+		return nil, fmt.Errorf("API cannot be nil")
+	}
+	auth := FilebrowserAuth{URL: api.ServerURL()}
+	if c, ok := api.(*Client); ok {
+		auth.Username = c.Username
+	}
+	return p.runWith(ctx, api, auth, externalURL, remotePathFn, actionParams)
+}
+
+// apiLog returns the logger of api, which logs nothing unless it is a *Client
+func apiLog(api FileBrowserAPI) Logger {
+	if c, ok := api.(*Client); ok {
+		return c.log()
+	}
+	return NopLogger{}
+}
+
+// findResource retrieves a resource like GetResource, returning nil without an error if
+// it does not exist
+func findResource(ctx context.Context, api ResourceAPI, remotePath string) (*RespResource, error) {
+	resource, err := api.GetResourceContext(ctx, remotePath)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return resource, err
+}
@@ -0,0 +1,107 @@
+package filebrowser
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// publishReport is code under test depending on the SDK only through its interfaces
+func publishReport(uploader Uploader, sharer Sharer, report string) (string, error) {
+	if err := uploader.UploadReader(strings.NewReader(report), int64(len(report)), "reports/latest.txt"); err != nil {
+		return "", err
+	}
+	return sharer.ShareWithExpiry("reports/latest.txt", ExpireAfter(0), "")
+}
+
+// fakeSDK is a hand-written fake of the uploader and sharer
+type fakeSDK struct {
+	Uploader
+	Sharer
+	uploaded map[string]string
+}
+
+func (f *fakeSDK) UploadReader(r io.Reader, size int64, remotePath string) error {
+	data, err := io.ReadAll(r)
+	f.uploaded[remotePath] = string(data)
+	return err
+}
+
+func (f *fakeSDK) ShareWithExpiry(remotePath string, expiry ShareExpiry, password string) (string, error) {
+	return "fake-hash", nil
+}
+
+func TestInterfaces(t *testing.T) {
+	fake := &fakeSDK{uploaded: map[string]string{}}
+	if hash, err := publishReport(fake, fake, "all good"); err != nil || hash != "fake-hash" || fake.uploaded["reports/latest.txt"] != "all good" {
+		t.Errorf("publishReport() with fake = %v, %v, uploaded %v", hash, err, fake.uploaded)
+	}
+
+	// The client satisfies the same interfaces
+	server := newMemServer(t, nil)
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	if hash, err := publishReport(client, client, "all good"); err != nil || hash == "" {
+		t.Fatalf("publishReport() with client = %v, %v", hash, err)
+	}
+	if content, _ := server.file("reports/latest.txt"); string(content) != "all good" {
+		t.Errorf("uploaded content = %q", content)
+	}
+}
+
+// countingAPI is middleware counting the uploads and shares of the API it wraps
+type countingAPI struct {
+	FileBrowserAPI
+	uploads, shares int
+}
+
+func (a *countingAPI) UploadWithOptionsContext(ctx context.Context, localPath string, remotePath string, opts UploadOptions) (*UploadResult, error) {
+	a.uploads++
+	return a.FileBrowserAPI.UploadWithOptionsContext(ctx, localPath, remotePath, opts)
+}
+
+func (a *countingAPI) ShareContext(ctx context.Context, remotePath string, expires int64, password string, unit string) (string, error) {
+	a.shares++
+	return a.FileBrowserAPI.ShareContext(ctx, remotePath, expires, password, unit)
+}
+
+func TestSaveAndShareWithAPI(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("report"))
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, nil)
+	api := &countingAPI{FileBrowserAPI: &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}}
+	remotePathFn := func(name string) string { return "in/" + name }
+
+	result, err := SaveAndShareWithAPI(api, origin.URL+"/report.txt", remotePathFn, ActionParams{URLPolicy: localOrigin})
+	if err != nil {
+		t.Fatalf("SaveAndShareWithAPI() error = %v", err)
+	}
+	if api.uploads != 1 || api.shares != 1 {
+		t.Errorf("uploads = %d, shares = %d, want 1 each through the wrapper", api.uploads, api.shares)
+	}
+	if content, _ := server.file("in/report.txt"); string(content) != "report" {
+		t.Errorf("uploaded content = %q", content)
+	}
+	if !strings.HasPrefix(result.ViewUrl, server.URL+"/share/") {
+		t.Errorf("view URL = %q", result.ViewUrl)
+	}
+
+	// An existing file of the expected size is kept
+	if _, err := SaveAndShareWithAPI(api, origin.URL+"/report.txt", remotePathFn, ActionParams{URLPolicy: localOrigin, FileSize: 6}); err != nil {
+		t.Fatalf("SaveAndShareWithAPI() of existing file error = %v", err)
+	}
+	if api.uploads != 1 || api.shares != 2 {
+		t.Errorf("uploads = %d, shares = %d, want the file kept and shared again", api.uploads, api.shares)
+	}
+
+	if _, err := SaveAndShareWithAPI(nil, origin.URL+"/report.txt", remotePathFn, ActionParams{}); err == nil {
+		t.Error("SaveAndShareWithAPI() with nil API succeeded")
+	}
+}
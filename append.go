@@ -0,0 +1,80 @@
+package filebrowser
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+)
+
+// Append adds data to the end of a remote file, creating it if missing, e.g. for remote
+// logs or ledgers. The server cannot write at an offset of a finished file, so the content
+// is downloaded, merged and uploaded next to the file, then moved over it. If the file is
+// changed by someone else meanwhile, Append fails with an error matching
+// ErrConcurrentModification and leaves it untouched, so callers can retry. Appends to the
+// same file are serialized within the process.
+func (c *Client) Append(remotePath string, data []byte) (err error) {
+	_, start := c.startOp(context.Background(), OpAppend, remotePath)
+	defer func() {
+		err = c.finishOp(OpAppend, remotePath, int64(len(data)), start, err)
+		c.postOp(OpResult{Operation: OpAppend, Path: remotePath, Bytes: int64(len(data)), Err: err}, start.at)
+	}()
+
+	if remotePath == "" {
+		return fmt.Errorf("remote path cannot be empty")
+	}
+
+	unlock := saveLocks.lock(c.URL + "\x00" + remotePath)
+	defer unlock()
+
+	observed, err := c.lookupResource(remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to get resource info: %w", err)
+	}
+
+	var merged bytes.Buffer
+	if observed != nil {
+		if err := c.Download(remotePath, &merged); err != nil {
+			return fmt.Errorf("failed to read %s: %w", remotePath, err)
+		}
+		if int64(merged.Len()) != observed.Size {
+			return fmt.Errorf("%s changed during the operation: %w", remotePath, ErrConcurrentModification)
+		}
+	}
+	merged.Write(data)
+
+	tmpPath, err := tempSibling(remotePath, "append")
+	if err != nil {
+		return err
+	}
+	if err := c.UploadReader(bytes.NewReader(merged.Bytes()), int64(merged.Len()), tmpPath); err != nil {
+		return err
+	}
+
+	// Only replace the file if nobody else wrote it since it was read
+	err = checkUnchanged(c, remotePath, observed)
+	if err == nil {
+		err = c.Move(tmpPath, remotePath, true)
+	}
+	if err != nil {
+		if deleteErr := c.DeleteResource(tmpPath); deleteErr != nil {
+			c.log().Warn("Failed to clean up temporary file", "path", tmpPath, "error", deleteErr)
+		}
+		return err
+	}
+
+	c.log().Info("Appended to file", "path", remotePath, "bytes", len(data))
+	return nil
+}
+
+// tempSibling returns a random hidden path next to remotePath for content written before
+// it is moved over remotePath, e.g. ".name.append-0123456789abcdef"
+func tempSibling(remotePath string, purpose string) (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate temporary name: %w", err)
+	}
+	return path.Join(path.Dir(remotePath), "."+path.Base(remotePath)+"."+purpose+"-"+hex.EncodeToString(id)), nil
+}
@@ -0,0 +1,44 @@
+package filebrowser
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAppend(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	for _, line := range []string{"first\n", "second\n"} {
+		if err := client.Append("logs/ledger.txt", []byte(line)); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	if content, _ := server.file("logs/ledger.txt"); string(content) != "first\nsecond\n" {
+		t.Errorf("content = %q, want both lines", content)
+	}
+
+	// Another writer changes the file while the merged content is uploaded
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.Contains(r.URL.Path, ".append-") {
+			server.setFile("logs/ledger.txt", []byte("first\nsecond\nother\n"))
+		}
+		next.ServeHTTP(w, r)
+	})
+	if err := client.Append("logs/ledger.txt", []byte("third\n")); !errors.Is(err, ErrConcurrentModification) {
+		t.Fatalf("Append() with concurrent change error = %v, want ErrConcurrentModification", err)
+	}
+	if content, _ := server.file("logs/ledger.txt"); string(content) != "first\nsecond\nother\n" {
+		t.Errorf("content = %q, want the concurrent change kept", content)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	for name := range server.files {
+		if strings.Contains(name, ".append-") {
+			t.Errorf("temporary file %s left behind", name)
+		}
+	}
+}
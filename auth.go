@@ -0,0 +1,81 @@
+package filebrowser
+
+import (
+	"fmt"
+
+	"github.com/imroc/req/v3"
+)
+
+// defaultProxyHeader names the user with AuthProxy if no header is given
+const defaultProxyHeader = "X-Forwarded-User"
+
+// AuthMethod selects how a client obtains its token, matching the auth method configured on
+// the Filebrowser server
+type AuthMethod string
+
+const (
+	// AuthPassword logs in with the username and password (the server's "json" method)
+	AuthPassword AuthMethod = ""
+	// AuthProxy logs in as the user named by a header set by an authenticating proxy (the
+	// server's "proxy" method), without a password, see Client.ProxyHeader
+	AuthProxy AuthMethod = "proxy"
+	// AuthNone logs in without credentials (the server's "noauth" method)
+	AuthNone AuthMethod = "noauth"
+	// AuthToken uses the pre-issued Token and never logs in. Requests fail with an error
+	// matching ErrUnauthorized once it expires.
+	AuthToken AuthMethod = "token"
+)
+
+// WithToken authenticates with a pre-issued token instead of logging in, see AuthToken.
+// The username and password may then be empty.
+func WithToken(token string) Option {
+	return func(o *clientOptions) { o.auth, o.token = AuthToken, token }
+}
+
+// WithProxyAuth logs in through an authenticating proxy naming the user in header, see
+// AuthProxy. An empty header uses X-Forwarded-User. The password may then be empty.
+func WithProxyAuth(header string) Option {
+	return func(o *clientOptions) { o.auth, o.proxyHeader = AuthProxy, header }
+}
+
+// WithNoAuth logs in to a server without authentication, see AuthNone. The username and
+// password may then be empty.
+func WithNoAuth() Option {
+	return func(o *clientOptions) { o.auth = AuthNone }
+}
+
+// validateAuth checks the credentials needed by the auth method of the client
+func (c *Client) validateAuth() error {
+	switch c.Auth {
+	case AuthPassword:
+		if c.Username == "" {
+			return fmt.Errorf("username cannot be empty")
+		}
+		password, token := c.credentials()
+		if password == "" && token == "" && c.PasswordPrompt == nil && c.Tokens == nil {
+			return fmt.Errorf("password cannot be empty")
+		}
+	case AuthToken:
+		if c.token() == "" {
+			return fmt.Errorf("token cannot be empty")
+		}
+	case AuthProxy, AuthNone:
+	default:
+		return fmt.Errorf("unknown auth method %q", c.Auth)
+	}
+	return nil
+}
+
+// loginRequest adds the credentials of the auth method of the client other than the
+// password to a login request. With AuthProxy a proxy in front of the server may replace
+// the header, and sets it if the client has no username.
+func (c *Client) loginRequest(r *req.Request, password string) *req.Request {
+	if c.Auth == AuthProxy && c.Username != "" {
+		header := c.ProxyHeader
+		if header == "" {
+			header = defaultProxyHeader
+		}
+		r.SetHeader(header, c.Username)
+	}
+	return r.SetBody(ReqLogin{Username: c.Username, Password: password})
+}
@@ -0,0 +1,83 @@
+package filebrowser
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestAuthMethods(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("a")})
+
+	// Record the credentials of logins and the token of the other requests
+	var logins []ReqLogin
+	var proxyUsers, tokens []string
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/login" {
+			var login ReqLogin
+			json.NewDecoder(r.Body).Decode(&login)
+			logins = append(logins, login)
+			proxyUsers = append(proxyUsers, r.Header.Get("X-Remote-User"))
+		} else {
+			tokens = append(tokens, r.Header.Get("X-Auth"))
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	tests := []struct {
+		name      string
+		username  string
+		opts      []Option
+		wantLogin bool
+		wantProxy string
+		wantToken string
+	}{
+		{name: "proxy", username: "alice", opts: []Option{WithProxyAuth("X-Remote-User")}, wantLogin: true, wantProxy: "alice", wantToken: "test-token"},
+		{name: "proxy setting the user", opts: []Option{WithProxyAuth("X-Remote-User")}, wantLogin: true, wantToken: "test-token"},
+		{name: "noauth", opts: []Option{WithNoAuth()}, wantLogin: true, wantToken: "test-token"},
+		{name: "token", opts: []Option{WithToken("issued-token")}, wantToken: "issued-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logins, proxyUsers, tokens = nil, nil, nil
+			client, err := NewClient(server.URL, tt.username, "", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			if _, err := client.GetResource("a.txt"); err != nil {
+				t.Fatalf("GetResource() error = %v", err)
+			}
+			if got := len(logins) == 1; got != tt.wantLogin {
+				t.Fatalf("logins = %+v, want a login: %v", logins, tt.wantLogin)
+			}
+			if tt.wantLogin && (logins[0].Password != "" || proxyUsers[0] != tt.wantProxy) {
+				t.Errorf("login = %+v with proxy user %q, want no password and proxy user %q", logins[0], proxyUsers[0], tt.wantProxy)
+			}
+			if len(tokens) != 1 || tokens[0] != tt.wantToken {
+				t.Errorf("tokens = %v, want %q", tokens, tt.wantToken)
+			}
+		})
+	}
+
+	// A pre-issued token is never renewed with a login
+	client, err := NewClient(server.URL, "", "", WithToken("issued-token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Login(); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Login() with a pre-issued token error = %v, want ErrUnauthorized", err)
+	}
+
+	// The default method still needs credentials
+	if _, err := NewClient(server.URL, "", ""); err == nil {
+		t.Error("NewClient() without credentials should fail")
+	}
+	if _, err := NewClient(server.URL, "", "", WithToken("")); err == nil {
+		t.Error("NewClient() with an empty token should fail")
+	}
+	if err := (&Client{URL: server.URL, Auth: "ldap"}).Validate(); err == nil {
+		t.Error("Validate() with an unknown auth method should fail")
+	}
+}
@@ -0,0 +1,218 @@
+package filebrowser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBatchConcurrency is the number of items SaveAndShareBatch processes concurrently
+// if none is given
+const defaultBatchConcurrency = 4
+
+// BatchItem is an external URL published by SaveAndShareBatch
+type BatchItem struct {
+	ExternalURL string
+	// RemotePath is the destination of the file. If empty or ending with a slash, the file
+	// is saved in that directory under the name of the URL.
+	RemotePath string
+	// ShareParams, FileSize and Force replace those of BatchOptions.Params for the item
+	ShareParams ShareParams
+	FileSize    int64
+	Force       bool
+}
+
+// remotePathFn returns the remote path function of the item
+func (item BatchItem) remotePathFn(name string) string {
+	if item.RemotePath == "" || strings.HasSuffix(item.RemotePath, "/") {
+		return path.Join(item.RemotePath, name)
+	}
+	return item.RemotePath
+}
+
+// BatchOptions configures SaveAndShareBatch
+type BatchOptions struct {
+	// Params apply to every item, except for the fields set per item
+	Params ActionParams
+	// Pipeline runs every item, the default one of SaveAndShare if nil
+	Pipeline *Pipeline
+	// Concurrency is the number of items processed at the same time, 4 if zero
+	Concurrency int
+	// Rate, if positive, limits the items started per second
+	Rate float64
+	// Mode decides whether the batch goes on after an item failed
+	Mode BatchMode
+}
+
+// BatchItemResult is the outcome of one BatchItem
+type BatchItemResult struct {
+	Item   BatchItem
+	Result *ShareResult // Set if the item succeeded
+	Err    error
+}
+
+// BatchSummary sums up the outcomes of a batch
+type BatchSummary struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Aborted   int // Items not started, see BatchFailFast
+	Duration  time.Duration
+}
+
+// BatchReport is the outcome of SaveAndShareBatch
+type BatchReport struct {
+	Results []BatchItemResult // In the order of the items
+	Summary BatchSummary
+}
+
+// SaveAndShareBatch runs SaveAndShare for many external URLs concurrently, sharing one
+// client that logs in once. If some items fail, the error joins the errors of every
+// failed item; the report is returned in any case unless the login fails.
+func SaveAndShareBatch(auth FilebrowserAuth, items []BatchItem, opts BatchOptions) (*BatchReport, error) {
+	if err := auth.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid authentication: %w", err)
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	pipeline := opts.Pipeline
+	if pipeline == nil {
+		pipeline = &Pipeline{}
+	}
+
+	start := time.Now()
+	client, params := newRunClient(auth, opts.Params)
+	if err := client.ensureAuthenticated(); err != nil {
+		return nil, client.redactError(fmt.Errorf("authentication failed: %w", err))
+	}
+
+	var interval time.Duration
+	if opts.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / opts.Rate)
+	}
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		failed  bool
+		next    = time.Now()
+		results = make([]BatchItemResult, len(items))
+		slots   = make(chan struct{}, concurrency)
+	)
+	for i, item := range items {
+		results[i].Item = item
+
+		slots <- struct{}{}
+		mu.Lock()
+		abort := failed && opts.Mode == BatchFailFast
+		mu.Unlock()
+		if abort {
+			<-slots
+			results[i].Err = ErrBatchAborted
+			continue
+		}
+		if interval > 0 {
+			time.Sleep(time.Until(next))
+			next = time.Now().Add(interval)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			itemParams := params
+			itemParams.ShareParams, itemParams.FileSize, itemParams.Force = item.ShareParams, item.FileSize, item.Force
+			result, err := pipeline.runWith(context.Background(), client, auth, item.ExternalURL, item.remotePathFn, itemParams)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[i].Result, results[i].Err = result, err
+			failed = failed || err != nil
+		}()
+	}
+	wg.Wait()
+
+	report := &BatchReport{Results: results, Summary: BatchSummary{Total: len(items), Duration: time.Since(start)}}
+	var errs []error
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+			report.Summary.Succeeded++
+		case errors.Is(result.Err, ErrBatchAborted):
+			report.Summary.Aborted++
+		default:
+			report.Summary.Failed++
+			errs = append(errs, fmt.Errorf("%s: %w", result.Item.ExternalURL, result.Err))
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// batchManifestItem is an item of a batch manifest
+type batchManifestItem struct {
+	URL      string `json:"url"`
+	Path     string `json:"path"`
+	Expiry   string `json:"expiry"`
+	Password string `json:"password"`
+	Size     int64  `json:"size"`
+	Force    bool   `json:"force"`
+}
+
+// ReadBatchManifest reads the items of SaveAndShareBatch from a JSON array of objects with
+// the fields "url", "path" (see BatchItem.RemotePath), "expiry" (a number followed by s,
+// m, h or d, hours if no unit is given, empty for shares that never expire), "password",
+// "size" (the expected file size) and "force". Invalid items fail with their index.
+func ReadBatchManifest(r io.Reader) ([]BatchItem, error) {
+	var manifest []batchManifestItem
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to read batch manifest: %w", err)
+	}
+
+	items := make([]BatchItem, len(manifest))
+	for i, entry := range manifest {
+		if entry.URL == "" {
+			return nil, fmt.Errorf("item %d: url cannot be empty", i)
+		}
+		expiry, err := parseExpiryAfter(entry.Expiry)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		if entry.Password != "" && expiry.IsZero() {
+			// Filebrowser ignores the password of shares that never expire
+			return nil, fmt.Errorf("item %d: a password requires an expiry", i)
+		}
+		items[i] = BatchItem{
+			ExternalURL: entry.URL,
+			RemotePath:  entry.Path,
+			ShareParams: ShareParams{Expiry: expiry, Password: entry.Password},
+			FileSize:    entry.Size,
+			Force:       entry.Force,
+		}
+	}
+	return items, nil
+}
+
+// parseExpiryAfter parses an expiry such as "36h" or "7d" like parseShareExpiry into the
+// expiry of a share valid for that long
+func parseExpiryAfter(s string) (ShareExpiry, error) {
+	expires, unit, err := parseShareExpiry(s)
+	if err != nil || expires == 0 {
+		return ShareExpiry{}, err
+	}
+	for _, u := range shareUnits {
+		if u.name == unit {
+			return ExpireAfter(time.Duration(expires) * u.step), nil
+		}
+	}
+	return ShareExpiry{}, fmt.Errorf("invalid expiry %q", s)
+}
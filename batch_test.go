@@ -0,0 +1,87 @@
+package filebrowser
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSaveAndShareBatch(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("payload of " + r.URL.Path))
+	}))
+	defer origin.Close()
+
+	files := newMemServer(t, nil)
+	var logins atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/login" {
+			logins.Add(1)
+		}
+		files.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+
+	items, err := ReadBatchManifest(strings.NewReader(`[
+		{"url": "` + origin.URL + `/a.txt", "path": "inbox/", "expiry": "7d", "password": "secret-pass"},
+		{"url": "` + origin.URL + `/missing.txt"},
+		{"url": "` + origin.URL + `/c.txt", "path": "renamed/c-copy.txt"}
+	]`))
+	if err != nil {
+		t.Fatalf("ReadBatchManifest() error = %v", err)
+	}
+
+	begin := time.Now()
+	report, err := SaveAndShareBatch(auth, items, BatchOptions{Params: ActionParams{URLPolicy: localOrigin}, Rate: 50})
+	if err == nil || !strings.Contains(err.Error(), "missing.txt") {
+		t.Errorf("SaveAndShareBatch() error = %v, want the failed item", err)
+	}
+	if elapsed := time.Since(begin); elapsed < 40*time.Millisecond {
+		t.Errorf("batch took %v, want items started at most 50 per second", elapsed)
+	}
+	if want := (BatchSummary{Total: 3, Succeeded: 2, Failed: 1, Duration: report.Summary.Duration}); report.Summary != want {
+		t.Errorf("summary = %+v, want %+v", report.Summary, want)
+	}
+	if logins.Load() != 1 {
+		t.Errorf("logins = %d, want one shared by the batch", logins.Load())
+	}
+
+	first, third := report.Results[0].Result, report.Results[2].Result
+	if first.RemotePath != "inbox/a.txt" || !first.PasswordProtected || first.Expires.Before(time.Now().Add(6*24*time.Hour)) {
+		t.Errorf("first result = %+v, want a password protected share of inbox/a.txt for 7 days", first)
+	}
+	if content, _ := files.file("renamed/c-copy.txt"); third.RemotePath != "renamed/c-copy.txt" || string(content) != "payload of /c.txt" {
+		t.Errorf("third result = %+v with content %q", third, content)
+	}
+
+	// Fail fast starts no items after a failure
+	items = []BatchItem{{ExternalURL: origin.URL + "/missing.txt"}, {ExternalURL: origin.URL + "/d.txt"}}
+	report, _ = SaveAndShareBatch(auth, items, BatchOptions{Params: ActionParams{URLPolicy: localOrigin}, Concurrency: 1, Mode: BatchFailFast})
+	if report.Summary.Aborted != 1 || !errors.Is(report.Results[1].Err, ErrBatchAborted) {
+		t.Errorf("fail fast summary = %+v, want the second item aborted", report.Summary)
+	}
+}
+
+func TestReadBatchManifestInvalid(t *testing.T) {
+	for _, manifest := range []string{
+		`{"url": "https://example.com/a"}`,
+		`[{"path": "a.txt"}]`,
+		`[{"url": "https://example.com/a", "expiry": "soon"}]`,
+		`[{"url": "https://example.com/a", "password": "secret-pass"}]`,
+	} {
+		if _, err := ReadBatchManifest(strings.NewReader(manifest)); err == nil {
+			t.Errorf("ReadBatchManifest(%s) succeeded", manifest)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package bleveindex provides a filebrowser.TextIndex backed by a local bleve full-text
+// index, for Indexer users who need ranked, stemmed search over large document trees.
+//
+// It is a separate module so the SDK itself does not depend on bleve:
+//
+//	go get github.com/kiuber/filebrowser-sdk/v2/bleveindex
+package bleveindex
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+	filebrowser "github.com/kiuber/filebrowser-sdk/v2"
+)
+
+// pageSize is the number of hits fetched per search request
+const pageSize = 1000
+
+// Field names of indexed documents
+const (
+	fieldText    = "text"
+	fieldVersion = "version"
+)
+
+// document is the bleve document of an indexed file
+type document struct {
+	Text    string `json:"text"`
+	Version string `json:"version"`
+}
+
+// Index is a filebrowser.TextIndex stored in a bleve index on disk. Queries match files
+// containing every word of the query after bleve's standard analysis. It is safe for
+// concurrent use.
+type Index struct {
+	index bleve.Index
+}
+
+var _ filebrowser.TextIndex = (*Index)(nil)
+
+// Open opens the bleve index at path, creating it if it does not exist yet. Close it when
+// done.
+func Open(path string) (*Index, error) {
+	index, err := bleve.Open(path)
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		index, err = bleve.New(path, newMapping())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bleve index: %w", err)
+	}
+	return &Index{index: index}, nil
+}
+
+// newMapping indexes the text of files and stores their version without indexing it
+func newMapping() mapping.IndexMapping {
+	text := bleve.NewTextFieldMapping()
+	text.Store = false
+
+	version := bleve.NewKeywordFieldMapping()
+	version.Index = false
+	version.IncludeInAll = false
+
+	doc := bleve.NewDocumentMapping()
+	doc.AddFieldMappingsAt(fieldText, text)
+	doc.AddFieldMappingsAt(fieldVersion, version)
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = doc
+	return indexMapping
+}
+
+// Close closes the underlying bleve index
+func (i *Index) Close() error {
+	return i.index.Close()
+}
+
+// Index adds or replaces the text of a file
+func (i *Index) Index(path string, version string, text string) error {
+	return i.index.Index(path, document{Text: text, Version: version})
+}
+
+// Delete removes a file from the index
+func (i *Index) Delete(path string) error {
+	return i.index.Delete(path)
+}
+
+// Versions returns the version of every indexed file keyed by path
+func (i *Index) Versions() (map[string]string, error) {
+	versions := map[string]string{}
+	err := i.search(bleve.NewMatchAllQuery(), []string{fieldVersion}, func(path string, fields map[string]interface{}) {
+		version, _ := fields[fieldVersion].(string)
+		versions[path] = version
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// Query returns the sorted paths of the files containing every word of q
+func (i *Index) Query(q string) ([]string, error) {
+	match := bleve.NewMatchQuery(q)
+	match.SetField(fieldText)
+	match.SetOperator(query.MatchQueryOperatorAnd)
+
+	var paths []string
+	err := i.search(match, nil, func(path string, _ map[string]interface{}) {
+		paths = append(paths, path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// search calls fn for every document matching q in the order of their path, with the
+// given stored fields
+func (i *Index) search(q query.Query, fields []string, fn func(path string, fields map[string]interface{})) error {
+	for from := 0; ; from += pageSize {
+		request := bleve.NewSearchRequestOptions(q, pageSize, from, false)
+		request.Fields = fields
+		request.SortBy([]string{"_id"})
+
+		result, err := i.index.Search(request)
+		if err != nil {
+			return fmt.Errorf("failed to search bleve index: %w", err)
+		}
+		for _, hit := range result.Hits {
+			fn(hit.ID, hit.Fields)
+		}
+		if len(result.Hits) < pageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package bleveindex
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "docs.bleve")
+	index, err := Open(indexPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	for path, text := range map[string]string{
+		"/docs/guide.md":  "Run the Deploy script on staging.",
+		"/docs/notes.txt": "Staging credentials rotate weekly.",
+	} {
+		if err := index.Index(path, "v1", text); err != nil {
+			t.Fatalf("Index(%s) error = %v", path, err)
+		}
+	}
+
+	assertQuery := func(index *Index, q string, want ...string) {
+		t.Helper()
+		got, err := index.Query(q)
+		if err != nil {
+			t.Fatalf("Query(%q) error = %v", q, err)
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("Query(%q) = %v, want %v", q, got, want)
+		}
+	}
+	assertQuery(index, "staging", "/docs/guide.md", "/docs/notes.txt")
+	assertQuery(index, "deploy staging", "/docs/guide.md")
+
+	if err := index.Delete("/docs/guide.md"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := index.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// The index is kept on disk
+	reopened, err := Open(indexPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer reopened.Close()
+	versions, err := reopened.Versions()
+	if err != nil || len(versions) != 1 || versions["/docs/notes.txt"] != "v1" {
+		t.Errorf("Versions() = %v, %v, want notes.txt at v1", versions, err)
+	}
+	assertQuery(reopened, "staging", "/docs/notes.txt")
+}
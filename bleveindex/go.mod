@@ -0,0 +1,10 @@
+module github.com/kiuber/filebrowser-sdk/v2/bleveindex
+
+go 1.24.0
+
+require (
+	github.com/blevesearch/bleve/v2 v2.5.0
+	github.com/kiuber/filebrowser-sdk/v2 v2.0.0
+)
+
+replace github.com/kiuber/filebrowser-sdk/v2 => ../
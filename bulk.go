@@ -0,0 +1,289 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// statManyConcurrency bounds the number of concurrent resource requests made by StatMany
+const statManyConcurrency = 16
+
+// StatMany retrieves information about many remote paths concurrently and returns it keyed
+// by path. Missing paths are left out of the result. If some requests fail, the
+// information gathered for the other paths is returned together with the joined errors.
+func (c *Client) StatMany(paths []string) (map[string]*RespResource, error) {
+	if err := c.ensureAuthenticated(); err != nil {
+		return nil, c.redactError(fmt.Errorf("authentication failed: %w", err))
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]*RespResource, len(paths))
+		errs    []error
+		seen    = make(map[string]bool, len(paths))
+		slots   = make(chan struct{}, statManyConcurrency)
+	)
+	for _, remotePath := range paths {
+		if seen[remotePath] {
+			continue
+		}
+		seen[remotePath] = true
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			resource, err := c.lookupResource(remotePath)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", remotePath, err))
+				return
+			}
+			if resource != nil {
+				results[remotePath] = resource
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results, errors.Join(errs...)
+}
+
+// defaultUploadManyConcurrency is the number of concurrent uploads made by UploadMany when
+// no concurrency is given
+const defaultUploadManyConcurrency = 4
+
+// ErrBatchAborted is the error of batch items not started because an earlier item failed
+// in BatchFailFast mode
+var ErrBatchAborted = errors.New("batch aborted after a failure")
+
+// BatchMode decides how a batch reacts to a failed item
+type BatchMode int
+
+const (
+	// BatchBestEffort processes every item regardless of failures
+	BatchBestEffort BatchMode = iota
+	// BatchFailFast starts no new item once one failed; items in flight still complete
+	BatchFailFast
+)
+
+// UploadItem is a file uploaded by UploadMany
+type UploadItem struct {
+	LocalPath  string
+	RemotePath string
+	Options    UploadOptions
+}
+
+// UploadItemResult is the outcome of one UploadItem
+type UploadItemResult struct {
+	Item    UploadItem
+	Result  *UploadResult // Set if the upload succeeded
+	Skipped bool          // Set if the filter excluded the item
+	Err     error
+}
+
+// UploadManyOptions contains optional parameters for UploadManyWithOptions
+type UploadManyOptions struct {
+	// Concurrency is the number of concurrent uploads, 4 if zero
+	Concurrency int
+	Mode        BatchMode
+	// Filter, if set, limits the items uploaded; others are marked Skipped. Items are
+	// matched by their remote path relative to the server root and the size and
+	// modification time of their local file.
+	Filter Filter
+}
+
+// UploadMany uploads a batch of files with the given number of concurrent uploads, 4 if
+// zero. The results are returned in the order of items. If some uploads fail, the error
+// joins the errors of every failed item.
+func (c *Client) UploadMany(items []UploadItem, concurrency int, mode BatchMode) ([]UploadItemResult, error) {
+	return c.UploadManyWithOptions(items, UploadManyOptions{Concurrency: concurrency, Mode: mode})
+}
+
+// UploadManyWithOptions uploads a batch of files like UploadMany, applying the given options
+func (c *Client) UploadManyWithOptions(items []UploadItem, opts UploadManyOptions) ([]UploadItemResult, error) {
+	concurrency, mode := opts.Concurrency, opts.Mode
+	if concurrency <= 0 {
+		concurrency = defaultUploadManyConcurrency
+	}
+	if err := c.ensureAuthenticated(); err != nil {
+		return nil, c.redactError(fmt.Errorf("authentication failed: %w", err))
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		failed  bool
+		results = make([]UploadItemResult, len(items))
+		slots   = make(chan struct{}, concurrency)
+	)
+	for i, item := range items {
+		results[i].Item = item
+
+		if opts.Filter != nil {
+			info, err := os.Stat(item.LocalPath)
+			if err != nil {
+				mu.Lock()
+				results[i].Err, failed = err, true
+				mu.Unlock()
+				continue
+			}
+			if !opts.Filter.Match(FilterEntry{Path: relativePath("/", item.RemotePath), Size: info.Size(), Modified: info.ModTime()}) {
+				results[i].Skipped = true
+				continue
+			}
+		}
+
+		slots <- struct{}{}
+		mu.Lock()
+		abort := failed && mode == BatchFailFast
+		mu.Unlock()
+		if abort {
+			<-slots
+			results[i].Err = ErrBatchAborted
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			result, err := c.UploadWithOptions(item.LocalPath, item.RemotePath, item.Options)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[i].Result, results[i].Err = result, err
+			failed = failed || err != nil
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Item.RemotePath, result.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// UploadDirOptions contains optional parameters for UploadDir
+type UploadDirOptions struct {
+	// Concurrency is the number of concurrent uploads, 4 if zero
+	Concurrency int
+	Mode        BatchMode
+	// Options are applied to every upload
+	Options UploadOptions
+	// Filter, if set, limits the files uploaded
+	Filter Filter
+}
+
+// UploadDir uploads the files below localDir to remoteDir, keeping their relative paths,
+// with UploadManyWithOptions. Files excluded by the filter are left out of the results.
+func (c *Client) UploadDir(localDir string, remoteDir string, opts UploadDirOptions) ([]UploadItemResult, error) {
+	if localDir == "" {
+		return nil, fmt.Errorf("local directory cannot be empty")
+	}
+	if remoteDir == "" {
+		return nil, fmt.Errorf("remote directory cannot be empty")
+	}
+
+	var items []UploadItem
+	err := filepath.WalkDir(localDir, func(localPath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", localPath, err)
+		}
+		rel, err := filepath.Rel(localDir, localPath)
+		if err != nil {
+			return err
+		}
+		if !matchFilter(opts.Filter, FilterEntry{Path: filepath.ToSlash(rel), Size: info.Size(), Modified: info.ModTime()}) {
+			return nil
+		}
+
+		items = append(items, UploadItem{LocalPath: localPath, RemotePath: path.Join(remoteDir, filepath.ToSlash(rel)), Options: opts.Options})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", localDir, err)
+	}
+
+	return c.UploadManyWithOptions(items, UploadManyOptions{Concurrency: opts.Concurrency, Mode: opts.Mode})
+}
+
+// DownloadDirOptions contains optional parameters for DownloadDir
+type DownloadDirOptions struct {
+	// Filter, if set, limits the files downloaded
+	Filter Filter
+}
+
+// DownloadDir downloads the files below remoteDir to localDir, keeping their relative
+// paths, and returns the local paths written. Each file is written to a temporary file
+// renamed into place once complete. It stops at the first failure.
+func (c *Client) DownloadDir(remoteDir string, localDir string, opts DownloadDirOptions) (_ []string, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if localDir == "" {
+		return nil, fmt.Errorf("local directory cannot be empty")
+	}
+
+	var downloaded []string
+	_, err = c.WalkWithOptions(remoteDir, func(resource *RespResource) error {
+		if resource.IsDir {
+			return nil
+		}
+		rel := filepath.FromSlash(relativePath(remoteDir, resource.Path))
+		if !filepath.IsLocal(rel) {
+			return fmt.Errorf("%s is not below %s: %w", resource.Path, remoteDir, ErrInvalidPath)
+		}
+
+		localPath := filepath.Join(localDir, rel)
+		if err := c.downloadFile(resourcePath(resource.Path), localPath); err != nil {
+			return err
+		}
+		downloaded = append(downloaded, localPath)
+		return nil
+	}, WalkOptions{Filter: opts.Filter})
+	return downloaded, err
+}
+
+// downloadFile downloads a remote file to localPath through a temporary file in the same
+// directory
+func (c *Client) downloadFile(remotePath string, localPath string) error {
+	if err := EnsureFolderForFile(localPath); err != nil {
+		return err
+	}
+	out, err := os.CreateTemp(filepath.Dir(localPath), filepath.Base(localPath)+".*"+partSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer os.Remove(out.Name())
+
+	err = c.Download(remotePath, out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", remotePath, err)
+	}
+	return os.Rename(out.Name(), localPath)
+}
@@ -0,0 +1,139 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestUploadMany(t *testing.T) {
+	server := newMemServer(t, nil)
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	dir := t.TempDir()
+	var items []UploadItem
+	for i := 0; i < 6; i++ {
+		localPath := filepath.Join(dir, fmt.Sprintf("%d.txt", i))
+		if i != 2 {
+			if err := os.WriteFile(localPath, []byte(fmt.Sprintf("file %d", i)), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		items = append(items, UploadItem{LocalPath: localPath, RemotePath: fmt.Sprintf("batch/%d.txt", i)})
+	}
+
+	t.Run("BestEffort", func(t *testing.T) {
+		results, err := client.UploadMany(items, 3, BatchBestEffort)
+		if err == nil {
+			t.Fatal("UploadMany() should report the missing local file")
+		}
+		for i, result := range results {
+			if result.Item.RemotePath != items[i].RemotePath {
+				t.Errorf("result %d is for %s", i, result.Item.RemotePath)
+			}
+			if (result.Err != nil) != (i == 2) {
+				t.Errorf("result %d error = %v", i, result.Err)
+			}
+			if i != 2 {
+				if content, _ := server.file(items[i].RemotePath); string(content) != fmt.Sprintf("file %d", i) {
+					t.Errorf("uploaded %s = %q", items[i].RemotePath, content)
+				}
+			}
+		}
+	})
+
+	t.Run("FailFast", func(t *testing.T) {
+		results, err := client.UploadMany(items, 1, BatchFailFast)
+		if err == nil {
+			t.Fatal("UploadMany() should fail")
+		}
+		for i, result := range results {
+			switch {
+			case i < 2 && result.Err != nil:
+				t.Errorf("result %d error = %v", i, result.Err)
+			case i > 2 && !errors.Is(result.Err, ErrBatchAborted):
+				t.Errorf("result %d error = %v, want ErrBatchAborted", i, result.Err)
+			}
+		}
+	})
+}
+
+func TestUploadManyFilter(t *testing.T) {
+	server := newMemServer(t, nil)
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	dir := t.TempDir()
+	var items []UploadItem
+	for _, name := range []string{"a.jpg", "b.tmp", "c.jpg"} {
+		localPath := filepath.Join(dir, name)
+		if err := os.WriteFile(localPath, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, UploadItem{LocalPath: localPath, RemotePath: "batch/" + name})
+	}
+
+	results, err := client.UploadManyWithOptions(items, UploadManyOptions{Filter: Extensions(".jpg")})
+	if err != nil {
+		t.Fatalf("UploadManyWithOptions() error = %v", err)
+	}
+	for i, result := range results {
+		excluded := i == 1
+		if result.Skipped != excluded || result.Err != nil {
+			t.Errorf("result %d = %+v, want skipped %v", i, result, excluded)
+		}
+		if _, ok := server.file(items[i].RemotePath); ok == excluded {
+			t.Errorf("%s uploaded = %v, want %v", items[i].RemotePath, ok, !excluded)
+		}
+	}
+}
+
+func TestUploadAndDownloadDir(t *testing.T) {
+	server := newMemServer(t, nil)
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	localDir := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "a", "docs/b.txt": "b", "docs/drafts/c.txt": "c", "d.bin": "d"} {
+		localPath := filepath.Join(localDir, filepath.FromSlash(name))
+		if err := EnsureFolderForFile(localPath); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(localPath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The same filter selects the same relative paths on both sides
+	filter := And(Extensions(".txt"), Not(PathRegexp(regexp.MustCompile(`(^|/)drafts/`))))
+	results, err := client.UploadDir(localDir, "site", UploadDirOptions{Filter: filter})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("UploadDir() = %+v, %v, want 2 uploads", results, err)
+	}
+	for name, want := range map[string]bool{"site/a.txt": true, "site/docs/b.txt": true, "site/docs/drafts/c.txt": false, "site/d.bin": false} {
+		if server.exists(name) != want {
+			t.Errorf("%s uploaded = %v, want %v", name, !want, want)
+		}
+	}
+
+	server.setFile("site/docs/drafts/e.txt", []byte("e"))
+	server.setFile("site/f.bin", []byte("f"))
+	downloadDir := t.TempDir()
+	downloaded, err := client.DownloadDir("site", downloadDir, DownloadDirOptions{Filter: filter})
+	if err != nil {
+		t.Fatalf("DownloadDir() error = %v", err)
+	}
+	slices.Sort(downloaded)
+	if want := []string{filepath.Join(downloadDir, "a.txt"), filepath.Join(downloadDir, "docs", "b.txt")}; !slices.Equal(downloaded, want) {
+		t.Errorf("DownloadDir() = %v, want %v", downloaded, want)
+	}
+	if content, _ := os.ReadFile(filepath.Join(downloadDir, "docs", "b.txt")); string(content) != "b" {
+		t.Errorf("downloaded docs/b.txt = %q, want %q", content, "b")
+	}
+	entries, _ := os.ReadDir(downloadDir)
+	if len(entries) != 2 {
+		t.Errorf("download directory holds %v, want a.txt and docs", entries)
+	}
+}
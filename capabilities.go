@@ -0,0 +1,57 @@
+package filebrowser
+
+// Capability names reported by SDKCapabilities.Features
+const (
+	FeatureFallbackUpload = "fallback_upload" // Streams are spooled to disk for servers without deferred lengths
+	FeatureEncryption     = "encryption"      // Files are encrypted client-side before uploading
+	FeatureResume         = "resume"          // Interrupted uploads and downloads are resumed
+	FeatureParallelParts  = "parallel_parts"  // Uploads are split into concurrently sent parts
+	FeatureSparse         = "sparse"          // Sparse files are uploaded without their holes
+	FeatureChangePlans    = "change_plans"    // Bulk changes are planned and applied later
+)
+
+// SDKCapabilities describes what this build of the SDK supports, so code bundling several
+// versions of the SDK can branch at runtime instead of parsing version strings
+type SDKCapabilities struct {
+	// TUSExtensions are the TUS extensions the SDK uses when the server offers them
+	TUSExtensions []string
+	// ChecksumAlgorithms are the algorithms of TUS chunk checksums, in order of preference
+	ChecksumAlgorithms []string
+	// Sources are the URL schemes SaveAndShare downloads from by default
+	Sources []string
+	// ArchiveFormats are the formats of DownloadArchive
+	ArchiveFormats []ArchiveFormat
+	// Features are the optional features supported, by name, e.g. FeatureFallbackUpload.
+	// Features this build does not know are missing rather than false.
+	Features map[string]bool
+}
+
+// Supports reports whether the SDK supports the named feature
+func (c SDKCapabilities) Supports(feature string) bool {
+	return c.Features[feature]
+}
+
+// Capabilities reports what this build of the SDK supports. The result is a new value the
+// caller may modify.
+func Capabilities() SDKCapabilities {
+	return SDKCapabilities{
+		TUSExtensions: []string{
+			tusExtensionCreation,
+			tusExtensionDeferLength,
+			tusExtensionConcatenation,
+			tusExtensionChecksum,
+			tusExtensionTermination,
+		},
+		ChecksumAlgorithms: append([]string(nil), checksumPreference...),
+		Sources:            []string{"http", "https"},
+		ArchiveFormats:     append([]ArchiveFormat(nil), archiveFormats...),
+		Features: map[string]bool{
+			FeatureFallbackUpload: true,
+			FeatureEncryption:     false,
+			FeatureResume:         true,
+			FeatureParallelParts:  true,
+			FeatureSparse:         true,
+			FeatureChangePlans:    true,
+		},
+	}
+}
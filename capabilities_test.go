@@ -0,0 +1,29 @@
+package filebrowser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	caps := Capabilities()
+	if !slices.Contains(caps.TUSExtensions, tusExtensionDeferLength) || !slices.Contains(caps.Sources, "https") {
+		t.Errorf("capabilities = %+v", caps)
+	}
+	if !caps.Supports(FeatureFallbackUpload) || caps.Supports(FeatureEncryption) || caps.Supports("teleportation") {
+		t.Errorf("features = %v", caps.Features)
+	}
+	for _, format := range caps.ArchiveFormats {
+		if !format.valid() {
+			t.Errorf("archive format %q is reported but refused by DownloadArchive", format)
+		}
+	}
+
+	// Callers get their own copy
+	caps.Features[FeatureEncryption] = true
+	caps.ChecksumAlgorithms[0] = "crc32"
+	caps.ArchiveFormats[0] = "rar"
+	if again := Capabilities(); again.Supports(FeatureEncryption) || again.ChecksumAlgorithms[0] != "sha1" || !again.ArchiveFormats[0].valid() {
+		t.Errorf("Capabilities() returned shared state: %+v", again)
+	}
+}
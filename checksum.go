@@ -0,0 +1,97 @@
+package filebrowser
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+)
+
+// readSeekerAt is an upload source supporting both sequential and random access
+type readSeekerAt interface {
+	io.ReadSeeker
+	io.ReaderAt
+}
+
+// hashingReader hashes the data of an upload source while it is being read.
+// The upload may seek back and re-read data (e.g. after a failed chunk) or read parts
+// concurrently, so only the contiguous prefix of the source is hashed in-line and the
+// rest is hashed by Sum.
+type hashingReader struct {
+	source readSeekerAt
+
+	mu     sync.Mutex
+	hash   hash.Hash
+	pos    int64 // position of the sequential reader
+	hashed int64 // number of leading bytes already hashed
+}
+
+// newHashingReader wraps a seekable source whose bytes should be hashed with algo
+func newHashingReader(source readSeekerAt, algo string) (*hashingReader, error) {
+	h, err := newChecksumHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	return &hashingReader{source: source, hash: h}, nil
+}
+
+// Read implements io.Reader
+func (r *hashingReader) Read(b []byte) (int, error) {
+	n, err := r.source.Read(b)
+
+	r.mu.Lock()
+	r.observe(b[:n], r.pos)
+	r.pos += int64(n)
+	r.mu.Unlock()
+
+	return n, err
+}
+
+// Seek implements io.Seeker
+func (r *hashingReader) Seek(offset int64, whence int) (int64, error) {
+	pos, err := r.source.Seek(offset, whence)
+	if err == nil {
+		r.mu.Lock()
+		r.pos = pos
+		r.mu.Unlock()
+	}
+	return pos, err
+}
+
+// ReadAt implements io.ReaderAt
+func (r *hashingReader) ReadAt(b []byte, off int64) (int, error) {
+	n, err := r.source.ReadAt(b, off)
+
+	r.mu.Lock()
+	r.observe(b[:n], off)
+	r.mu.Unlock()
+
+	return n, err
+}
+
+// observe hashes the part of data read at off that extends the hashed prefix.
+// The caller must hold r.mu.
+func (r *hashingReader) observe(data []byte, off int64) {
+	if off > r.hashed || off+int64(len(data)) <= r.hashed {
+		return
+	}
+	r.hash.Write(data[r.hashed-off:])
+	r.hashed = off + int64(len(data))
+}
+
+// Sum hashes whatever part of the first size bytes has not been read yet and returns the
+// hex encoded digest
+func (r *hashingReader) Sum(size int64) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.hashed < size {
+		n, err := io.Copy(r.hash, io.NewSectionReader(r.source, r.hashed, size-r.hashed))
+		r.hashed += n
+		if err != nil {
+			return "", fmt.Errorf("failed to hash remaining data: %w", err)
+		}
+	}
+	return hex.EncodeToString(r.hash.Sum(nil)), nil
+}
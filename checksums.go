@@ -0,0 +1,76 @@
+package filebrowser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ChecksumsFileName is the name of the checksum file written by PublishChecksums
+const ChecksumsFileName = "SHA256SUMS"
+
+// PublishChecksums computes the SHA-256 checksums of every file below a remote directory
+// and uploads them as a SHA256SUMS file in that directory, in the format read by
+// sha256sum -c. Checksums are computed by the server where possible, otherwise the file
+// is downloaded and hashed. An existing SHA256SUMS file is replaced.
+func (c *Client) PublishChecksums(dir string) (err error) {
+	defer func() { err = c.redactError(err) }()
+
+	sumsPath := path.Join(strings.Trim(dir, "/"), ChecksumsFileName)
+	prefix := manifestPrefix(dir)
+
+	checksums := map[string]string{}
+	err = c.Walk(dir, func(resource *RespResource) error {
+		if resource.IsDir || resource.Path == "/"+sumsPath {
+			return nil
+		}
+
+		checksum, err := sha256Of(c, resourcePath(resource.Path))
+		if err != nil {
+			return err
+		}
+		checksums[strings.TrimPrefix(resource.Path, prefix)] = checksum
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sums strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sums, "%s  %s\n", checksums[name], name)
+	}
+
+	if err := c.DeleteResource(sumsPath); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", sumsPath, err)
+	}
+	if err := c.UploadStream(strings.NewReader(sums.String()), sumsPath); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", sumsPath, err)
+	}
+	return nil
+}
+
+// sha256Of returns the hex encoded SHA-256 checksum of a remote file, downloading the
+// file if the server cannot compute it
+func sha256Of(api FileBrowserAPI, remotePath string) (string, error) {
+	checksum, err := api.GetChecksum(remotePath, "sha256")
+	if err == nil || errors.Is(err, ErrDeniedByRule) {
+		return checksum, err
+	}
+	apiLog(api).Debug("Server checksum unavailable, downloading", "path", remotePath, "error", err)
+
+	hash := sha256.New()
+	if err := api.Download(remotePath, hash); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
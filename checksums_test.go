@@ -0,0 +1,24 @@
+package filebrowser
+
+import (
+	"testing"
+)
+
+func TestPublishChecksums(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"release/app.tar.gz":    []byte("app"),
+		"release/docs/guide.md": []byte("guide"),
+		"release/SHA256SUMS":    []byte("stale"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	if err := client.PublishChecksums("release"); err != nil {
+		t.Fatalf("PublishChecksums() error = %v", err)
+	}
+
+	expected := sha256Hex([]byte("app")) + "  app.tar.gz\n" +
+		sha256Hex([]byte("guide")) + "  docs/guide.md\n"
+	if got, _ := server.file("release/SHA256SUMS"); string(got) != expected {
+		t.Errorf("SHA256SUMS =\n%s\nwant\n%s", got, expected)
+	}
+}
@@ -0,0 +1,69 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+)
+
+// Chmod sets the permission bits of the remote resource at remotePath, e.g. to make an
+// uploaded script executable. Only the permission bits of mode are sent. The server must
+// support the chmod action of the resources API; others fail with an APIError.
+func (c *Client) Chmod(remotePath string, mode fs.FileMode) (err error) {
+	_, start := c.startOp(context.Background(), OpChmod, remotePath)
+	defer func() {
+		err = c.finishOp(OpChmod, remotePath, 0, start, err)
+		c.postOp(OpResult{Operation: OpChmod, Path: remotePath, Err: err}, start.at)
+	}()
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return c.chmod(remotePath, mode)
+}
+
+// applyMode sets the mode of a resource the client just created, doing nothing if mode
+// is zero
+func (c *Client) applyMode(remotePath string, mode fs.FileMode) error {
+	if mode == 0 {
+		return nil
+	}
+	if err := c.chmod(remotePath, mode); err != nil {
+		return fmt.Errorf("failed to set mode of %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+// chmod sends the chmod action for the resource at remotePath
+func (c *Client) chmod(remotePath string, mode fs.FileMode) error {
+	if remotePath == "" {
+		return fmt.Errorf("remote path cannot be empty")
+	}
+
+	url, err := c.apiURL("resources", remotePath)
+	if err != nil {
+		return err
+	}
+	resp, err := c.newRequest(context.Background()).
+		SetQueryParam("action", "chmod").
+		SetQueryParam("mode", fmt.Sprintf("%04o", uint32(mode.Perm()))).
+		Patch(url)
+	if err != nil {
+		return fmt.Errorf("chmod request failed: %w", err)
+	}
+
+	if err := checkDenied(resp, remotePath); err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		c.log().Info("Changed mode", "path", remotePath, "mode", mode.Perm())
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("cannot chmod %s: %w", remotePath, newAPIError(resp))
+	default:
+		return fmt.Errorf("chmod request failed with %w", newAPIError(resp))
+	}
+}
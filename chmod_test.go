@@ -0,0 +1,44 @@
+package filebrowser
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChmod(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"bin/run.sh": []byte("#!/bin/sh\n")})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	if err := client.Chmod("bin/run.sh", 0o750); err != nil {
+		t.Fatalf("Chmod() error = %v", err)
+	}
+	if mode := server.mode("bin/run.sh"); mode != 0o750 {
+		t.Errorf("mode = %o, want 750", mode)
+	}
+	if err := client.Chmod("missing.sh", 0o755); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Chmod() of missing file error = %v, want ErrNotFound", err)
+	}
+
+	// Uploads apply the default mode of the client unless the upload sets its own
+	client.FileMode = 0o755
+	if err := client.UploadReader(bytes.NewReader([]byte("echo")), 4, "bin/deploy.sh"); err != nil {
+		t.Fatalf("UploadReader() error = %v", err)
+	}
+	if mode := server.mode("bin/deploy.sh"); mode != 0o755 {
+		t.Errorf("mode after UploadReader = %o, want 755", mode)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(localPath, []byte("notes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UploadWithOptions(localPath, "notes.txt", UploadOptions{Mode: 0o600}); err != nil {
+		t.Fatalf("UploadWithOptions() error = %v", err)
+	}
+	if mode := server.mode("notes.txt"); mode != 0o600 {
+		t.Errorf("mode after UploadWithOptions = %o, want 600", mode)
+	}
+}
@@ -0,0 +1,218 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/duke-git/lancet/v2/fileutil"
+	"github.com/imroc/req/v3"
+)
+
+// defaultChunkSize is the size of the ranges fetched by parallel downloads if none is given
+const defaultChunkSize = 8 << 20
+
+// errSourceChanged is returned when the file changes during a parallel download
+var errSourceChanged = errors.New("file changed during the download")
+
+// fetchChunked downloads fileURL to localPath in ranges fetched over opts.Connections
+// connections. handled is false if the file must be downloaded with a single request
+// instead, because the server ignores ranges, cannot tell versions of the file apart, or
+// the file fits a single chunk.
+func fetchChunked(ctx context.Context, client *req.Client, localPath string, fileURL string, opts DownloadOptions) (handled bool, err error) {
+	partPath := localPath + partSuffix
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	// The partial file of an interrupted download is resumed with a single request
+	if v, ok := loadValidators(partPath+validatorsSuffix, fileURL); ok && v.ifRange() != "" && fileutil.IsExist(partPath) {
+		return false, nil
+	}
+
+	// Probe the size of the file and whether the server supports ranges with its first byte
+	r := traceTimeouts(client.R().SetContext(ctx)).DisableAutoReadResponse().SetHeader("Range", "bytes=0-0")
+	opts.Auth.apply(r)
+	revalidate(r, localPath, fileURL, opts)
+	resp, err := r.Get(fileURL)
+	if err != nil {
+		return true, fmt.Errorf("download request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		packageLog(opts.Logger).Info("File not modified, skipping download", "path", localPath)
+		return true, nil
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		return false, nil
+	default:
+		return true, fmt.Errorf("download failed with status code: %d", resp.StatusCode)
+	}
+	total, ok := contentRangeTotal(resp.Header.Get("Content-Range"))
+	validators := responseValidators(fileURL, resp)
+	if !ok || total <= chunkSize || validators.ifRange() == "" {
+		return false, nil
+	}
+	if opts.MaxBytes > 0 && total > opts.MaxBytes {
+		return true, &DownloadTooLargeError{URL: fileURL, Limit: opts.MaxBytes, Size: total}
+	}
+
+	discardPart(partPath)
+	part, err := os.OpenFile(partPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return true, fmt.Errorf("failed to open partial file: %w", err)
+	}
+	if err := part.Truncate(total); err != nil {
+		part.Close()
+		discardPart(partPath)
+		return true, fmt.Errorf("failed to allocate partial file: %w", err)
+	}
+
+	chunkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		slots    = make(chan struct{}, opts.Connections)
+		progress = &transferProgress{fn: opts.Progress, total: total}
+	)
+	for start := int64(0); start < total; start += chunkSize {
+		end := min(start+chunkSize, total) - 1
+
+		slots <- struct{}{}
+		if chunkCtx.Err() != nil {
+			<-slots
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			if err := fetchChunk(chunkCtx, client, part, fileURL, start, end, validators.ifRange(), opts, progress); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	err = firstErr
+	if err == nil {
+		err = ctx.Err()
+	}
+	if closeErr := part.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write file: %w", closeErr)
+	}
+	if err != nil {
+		// The partial file was allocated at full size, so report the bytes received instead
+		discardPart(partPath)
+		if done := progress.received(); done > 0 {
+			return true, &PartialDownloadError{URL: fileURL, Written: done, Err: err}
+		}
+		return true, err
+	}
+
+	if err := os.Rename(partPath, localPath); err != nil {
+		return true, err
+	}
+	if err := storeValidators(localPath+validatorsSuffix, validators); err != nil {
+		return true, fmt.Errorf("failed to store download validators: %w", err)
+	}
+	return true, nil
+}
+
+// fetchChunk downloads the bytes start to end, inclusive, of fileURL into file, failing
+// with errSourceChanged if the file no longer matches ifRange
+func fetchChunk(ctx context.Context, client *req.Client, file *os.File, fileURL string, start int64, end int64, ifRange string, opts DownloadOptions, progress *transferProgress) error {
+	watch := watchIdle(ctx, timeoutOrDefault(opts.IdleTimeout, defaultIdleTimeout))
+	r := traceTimeouts(client.R().SetContext(watch.ctx)).DisableAutoReadResponse().
+		SetHeader("Range", fmt.Sprintf("bytes=%d-%d", start, end)).
+		SetHeader("If-Range", ifRange)
+	opts.Auth.apply(r)
+	resp, err := r.Get(fileURL)
+	if err != nil {
+		watch.stop()
+		return fmt.Errorf("download request for bytes %d-%d failed: %w", start, end, watch.err(err))
+	}
+	body := watch.reader(resp.Body)
+	defer body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		return errSourceChanged
+	default:
+		return fmt.Errorf("download of bytes %d-%d failed with status code: %d", start, end, resp.StatusCode)
+	}
+	if got, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || got != start {
+		return fmt.Errorf("server returned an unexpected range: %s", resp.Header.Get("Content-Range"))
+	}
+
+	size := end - start + 1
+	w := chunkWriter{w: io.NewOffsetWriter(file, start), progress: progress}
+	written, err := io.Copy(w, io.LimitReader(body, size))
+	if err == nil && written < size {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write bytes %d-%d: %w", start, end, err)
+	}
+	return nil
+}
+
+// revalidate makes r conditional on the validators of an existing complete download of
+// fileURL, unless opts overwrite it
+func revalidate(r *req.Request, localPath string, fileURL string, opts DownloadOptions) {
+	validators, ok := loadValidators(localPath+validatorsSuffix, fileURL)
+	if !ok || opts.Overwrite || !fileutil.IsExist(localPath) {
+		return
+	}
+	if validators.ETag != "" {
+		r.SetHeader("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		r.SetHeader("If-Modified-Since", validators.LastModified)
+	}
+}
+
+// contentRangeTotal returns the complete length of a Content-Range header such as
+// "bytes 0-0/200", ok is false if it is unknown
+func contentRangeTotal(contentRange string) (int64, bool) {
+	_, length, ok := strings.Cut(contentRange, "/")
+	if !ok {
+		return 0, false
+	}
+	total, err := strconv.ParseInt(length, 10, 64)
+	return total, err == nil
+}
+
+// chunkWriter writes a chunk at its offset, reporting the bytes written
+type chunkWriter struct {
+	w        io.Writer
+	progress *transferProgress
+}
+
+// Write implements io.Writer
+func (w chunkWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.progress.add(int64(n))
+	return n, err
+}
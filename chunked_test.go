@@ -0,0 +1,102 @@
+package filebrowser
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDownloadChunked(t *testing.T) {
+	content := bytes.Repeat([]byte("abcdefghij"), 10*1024)
+	var (
+		mu               sync.Mutex
+		requests         int
+		inFlight, peak   int
+		etag             = `"v1"`
+		changeAfterProbe bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		if r.Header.Get("Range") != "bytes=0-0" {
+			inFlight++
+			peak = max(peak, inFlight)
+		} else if changeAfterProbe {
+			defer func() { etag = `"v2"` }()
+		}
+		w.Header().Set("ETag", etag)
+		mu.Unlock()
+
+		// Keep the chunk requests open long enough to overlap
+		time.Sleep(10 * time.Millisecond)
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+
+		mu.Lock()
+		if r.Header.Get("Range") != "bytes=0-0" {
+			inFlight--
+		}
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	var lastDone, lastTotal int64
+	opts := DownloadOptions{
+		Dir:         t.TempDir(),
+		Connections: 4,
+		ChunkSize:   16 * 1024,
+		Progress:    func(done int64, total int64) { lastDone, lastTotal = done, total },
+	}
+	localPath, err := DownloadToLocalWithOptions(server.URL+"/file.bin", 0, opts)
+	if err != nil {
+		t.Fatalf("DownloadToLocalWithOptions() error = %v", err)
+	}
+	if data, _ := os.ReadFile(localPath); !bytes.Equal(data, content) {
+		t.Fatal("assembled file does not match")
+	}
+	if requests != 1+7 || peak < 2 || peak > 4 {
+		t.Errorf("requests = %d, peak connections = %d, want a probe and 7 chunks over up to 4 connections", requests, peak)
+	}
+	if lastDone != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Errorf("last progress = %d/%d, want %d", lastDone, lastTotal, len(content))
+	}
+
+	// The complete file is revalidated by the probe
+	requests = 0
+	if _, err := DownloadToLocalWithOptions(server.URL+"/file.bin", 0, opts); err != nil || requests != 1 {
+		t.Errorf("revalidation error = %v with %d requests, want only the probe", err, requests)
+	}
+
+	// A file changing during the download fails it without leaving a partial file
+	changeAfterProbe = true
+	opts.Overwrite = true
+	if _, err := DownloadToLocalWithOptions(server.URL+"/file.bin", 0, opts); !errors.Is(err, errSourceChanged) {
+		t.Errorf("download of a changing file error = %v, want errSourceChanged", err)
+	}
+	if _, err := os.Stat(localPath + partSuffix); !os.IsNotExist(err) {
+		t.Errorf("partial file left behind: %v", err)
+	}
+}
+
+func TestDownloadChunkedFallback(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Ranges are not supported
+		requests++
+		w.Write([]byte("whole file"))
+	}))
+	defer server.Close()
+
+	opts := DownloadOptions{Dir: t.TempDir(), Connections: 4, ChunkSize: 2}
+	localPath, err := DownloadToLocalWithOptions(server.URL+"/file.txt", 0, opts)
+	if err != nil {
+		t.Fatalf("DownloadToLocalWithOptions() error = %v", err)
+	}
+	if data, _ := os.ReadFile(localPath); string(data) != "whole file" || requests != 2 {
+		t.Errorf("file = %q after %d requests, want the whole file after the probe and one download", data, requests)
+	}
+}
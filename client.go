@@ -1,20 +1,100 @@
 package filebrowser
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/eventials/go-tus"
 	"github.com/imroc/req/v3"
+	"golang.org/x/sync/singleflight"
 )
 
-// Client represents a Filebrowser client
+// Client represents a Filebrowser client. It is safe for concurrent use once configured;
+// Token and Password are then only changed by the client itself.
 type Client struct {
 	URL string
 	ReqLogin
 	Token string
+
+	// Stats, if set, receives statistics for every completed operation
+	Stats StatsCollector
+	// Sessions, if set, records unfinished uploads so they can be aborted later
+	Sessions UploadSessionStore
+	// SharePasswordPolicy, if set, is enforced for every share password
+	SharePasswordPolicy *SharePasswordPolicy
+	// Redactor, if set, scrubs additional sensitive data from errors and log lines.
+	// The password and token are always scrubbed.
+	Redactor Redactor
+	// Retry, if set, retries transient failures of Login, Upload, Share, GetResource
+	// and DeleteResource
+	Retry *RetryPolicy
+	// Logger, if set, receives the log messages of the client, scrubbed of its secrets.
+	// Nothing is logged if nil.
+	Logger Logger
+	// PreUploadHook, if set, is consulted before every upload and rejects it by
+	// returning an error
+	PreUploadHook PreUploadHook
+	// PostOpHook, if set, is called after every mutating operation, successful or not
+	PostOpHook PostOpHook
+	// Verbose records every HTTP attempt of Login, Upload, Share, GetResource and
+	// DeleteResource in the Attempts of OperationStats, OpResult and UploadResult
+	Verbose bool
+	// Tokens, if set, persists the login token between runs so the password is only needed
+	// once the token expires, e.g. a FileTokenStore
+	Tokens TokenStore
+	// PasswordPrompt, if set, is called for the password when a login is needed and
+	// Password is empty, e.g. to ask the user of a CLI
+	PasswordPrompt func(url string, username string) (string, error)
+	// UploadStore, if set, remembers the upload URLs of unfinished uploads made with
+	// UploadOptions.Resume, e.g. a FileUploadStore
+	UploadStore tus.Store
+	// CreateParents creates the missing parent directories of the destination of uploads,
+	// moves and copies first, for servers failing on missing parents
+	CreateParents bool
+	// Timeouts limit the duration of logins and API requests and how long transfers may
+	// stall. Zero fields use defaults.
+	Timeouts Timeouts
+	// MinDeleteDepth is the number of path segments a path must have for DeleteResource
+	// and EnforceQuota to delete at or below it. The root is always refused; values below
+	// 1 only protect the root.
+	MinDeleteDepth int
+	// AllowDangerous lifts the protection of MinDeleteDepth and the root
+	AllowDangerous bool
+	// FileMode, if set, is applied with Chmod to files right after they are uploaded, e.g.
+	// 0o755 for scripts. UploadOptions.Mode overrides it per upload.
+	FileMode fs.FileMode
+	// DirMode, if set, is applied with Chmod to directories the client creates
+	DirMode fs.FileMode
+	// Hedge, if set, sends a second request for GetResource if the first one is slow and
+	// uses the first response, optionally to replicas of the server
+	Hedge *HedgePolicy
+	// Middleware wraps every request to the server in order, the first being the
+	// outermost, e.g. for logging, metrics, tracing or adding headers
+	Middleware []Middleware
+	// Auth selects how the client obtains its token, logging in with the username and
+	// password by default
+	Auth AuthMethod
+	// ProxyHeader names the header carrying the username with AuthProxy,
+	// X-Forwarded-User if empty
+	ProxyHeader string
+
+	httpClient       *req.Client // Set by NewClient, the shared default client if nil
+	middlewareOnce   sync.Once   // Wraps httpClient with Middleware on first use
+	middlewareClient *req.Client
+
+	mu     sync.Mutex         // Guards Token and Password once the client is in use
+	logins singleflight.Group // Deduplicates concurrent logins
+	hedges atomic.Uint64      // Number of hedged requests, picks their replica
 }
 
 // ReqLogin contains login request parameters
@@ -37,16 +117,57 @@ type RespLogin struct {
 
 // RespResource contains resource information
 type RespResource struct {
-	NotExist  bool   `json:"not_exist"`
-	Path      string `json:"path"`
-	Name      string `json:"name"`
-	Size      int64  `json:"size"`
-	Extension string `json:"extension"`
-	Modified  string `json:"modified"`
-	Mode      int64  `json:"mode"`
-	IsDir     string `json:"IsDir"`
-	IsSymlink string `json:"isSymlink"`
-	Type      string `json:"type"`
+	Path      string      `json:"path"`
+	Name      string      `json:"name"`
+	Size      int64       `json:"size"`
+	Extension string      `json:"extension"`
+	Modified  time.Time   `json:"modified"`
+	Mode      fs.FileMode `json:"mode"` // Permission and type bits, e.g. fs.ModeDir
+	IsDir     bool        `json:"isDir"`
+	IsSymlink bool        `json:"isSymlink"`
+	Type      string      `json:"type"`
+
+	Checksums map[string]string `json:"checksums,omitempty"`
+	Items     []RespResource    `json:"items,omitempty"` // Entries of a directory
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The flags are accepted as booleans or as
+// strings, and the modification time in RFC 3339 format, zero if missing.
+func (r *RespResource) UnmarshalJSON(data []byte) error {
+	type plain RespResource
+	raw := struct {
+		*plain
+		Modified  string   `json:"modified"`
+		IsDir     jsonBool `json:"isDir"`
+		IsSymlink jsonBool `json:"isSymlink"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	r.IsDir, r.IsSymlink = bool(raw.IsDir), bool(raw.IsSymlink)
+	r.Modified = time.Time{}
+	if raw.Modified != "" {
+		modified, err := time.Parse(time.RFC3339Nano, raw.Modified)
+		if err != nil {
+			return fmt.Errorf("invalid modification time for %s: %w", r.Path, err)
+		}
+		r.Modified = modified
+	}
+	return nil
+}
+
+// jsonBool is a boolean sent as a JSON boolean or string
+type jsonBool bool
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *jsonBool) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseBool(strings.Trim(string(data), `"`))
+	if err != nil && string(data) != "null" && string(data) != `""` {
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	*b = jsonBool(value)
+	return nil
 }
 
 // RespShare contains share response data
@@ -55,118 +176,495 @@ type RespShare struct {
 	Path string `json:"path"`
 }
 
-// Validate checks if the client configuration is valid
+// Validate checks if the client configuration is valid. The password may be empty if the
+// client holds a token, it can be prompted for or a stored token may be used. Auth methods
+// other than AuthPassword need no password, see AuthMethod.
 func (c *Client) Validate() error {
 	if c.URL == "" {
 		return fmt.Errorf("URL cannot be empty")
 	}
-	if c.Username == "" {
-		return fmt.Errorf("username cannot be empty")
-	}
-	if c.Password == "" {
-		return fmt.Errorf("password cannot be empty")
-	}
-	return nil
+	return c.validateAuth()
 }
 
-// Login authenticates with the Filebrowser server and retrieves a token
+// Login authenticates with the Filebrowser server and retrieves a token. Concurrent calls
+// share a single login request.
 func (c *Client) Login() error {
+	_, err, _ := c.logins.Do("login", func() (any, error) { return nil, c.login() })
+	return err
+}
+
+// login authenticates like Login
+func (c *Client) login() (err error) {
+	ctx, start := c.startOp(context.Background(), OpLogin, "")
+	retry := c.newRetrier(ctx)
+	defer func() { err = c.finishRetriedOp(OpLogin, "", 0, start, retry, err) }()
+
 	if err := c.Validate(); err != nil {
 		return fmt.Errorf("invalid client configuration: %w", err)
 	}
+	if c.Auth == AuthToken {
+		return fmt.Errorf("cannot log in with a pre-issued token: %w", ErrUnauthorized)
+	}
+	password, _ := c.credentials()
+	if c.Auth == AuthPassword && password == "" && c.PasswordPrompt != nil {
+		if password, err = c.PasswordPrompt(c.URL, c.Username); err != nil {
+			return fmt.Errorf("failed to read password: %w", err)
+		}
+		c.mu.Lock()
+		c.Password = password
+		c.mu.Unlock()
+	}
+	if c.Auth == AuthPassword && password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
 
-	client := req.C().DevMode()
-	resp, err := client.R().
-		SetBody(ReqLogin{Username: c.Username, Password: c.Password}).
-		Post(fmt.Sprintf("%s/api/login", c.URL))
+	client := c.http()
+	resp, err := retry.send(func() (*req.Response, error) {
+		r := withTimeout(traceTimeouts(client.R()), timeoutOrDefault(c.Timeouts.Auth, defaultAuthTimeout))
+		return c.loginRequest(r, password).Post(fmt.Sprintf("%s/api/login", c.URL))
+	})
 	if err != nil {
 		return fmt.Errorf("login request failed: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("login failed with status code: %d", resp.StatusCode)
+		return fmt.Errorf("login failed with %w", newAPIError(resp))
 	}
 
-	c.Token = resp.String()
-	if c.Token == "" {
+	token := resp.String()
+	if token == "" {
 		return fmt.Errorf("received empty token from server")
 	}
+	c.setToken(token)
 
-	log.Printf("Successfully authenticated with Filebrowser")
+	c.log().Info("Authenticated with Filebrowser", "url", c.URL)
 	return nil
 }
 
-// ensureAuthenticated ensures the client is authenticated, logging in if necessary
-func (c *Client) ensureAuthenticated() error {
-	if c.Token == "" {
-		return c.Login()
-	}
-	return nil
+// UploadOptions contains optional parameters for uploads
+type UploadOptions struct {
+	// ParallelParts uploads the file as this many partial uploads sent concurrently
+	// and concatenated by the server afterwards. It only takes effect when the server
+	// supports the TUS concatenation extension; values below 2 upload sequentially.
+	ParallelParts int
+	// Checksum is the algorithm ("md5", "sha1", "sha256" or "sha512") of a digest computed
+	// while the file is uploaded and returned in UploadResult.Checksum. Empty disables it.
+	Checksum string
+	// Probes extract media metadata from the local file before it is uploaded, returned
+	// in UploadResult.Media (e.g. ImageProbe, FFProbe{})
+	Probes []MediaProbe
+	// Sidecar uploads the size, checksum and media metadata as JSON next to the file,
+	// at the remote path with SidecarSuffix appended
+	Sidecar bool
+	// Progress, if set, is called as chunks are acknowledged by the server
+	Progress ProgressFunc
+	// Lock honors lock sentinels (see LockSuffix): the upload fails with ErrLocked if the
+	// remote file is locked, otherwise the file is locked while it is uploaded
+	Lock bool
+	// Resume continues an interrupted upload of the same unmodified file from the offset
+	// the server received, using the client's UploadStore. Parallel uploads are not resumed.
+	Resume bool
+	// Mode, if set, is applied with Chmod to the file after it is uploaded instead of
+	// Client.FileMode
+	Mode fs.FileMode
+}
+
+// UploadResult contains information about a completed upload
+type UploadResult struct {
+	RemotePath string
+	Bytes      int64
+	Duration   time.Duration // Time taken by the upload, including the sidecar
+	TusURL     string        // Upload URL assigned by the server, empty if it reported none
+	Checksum   string        // Hex encoded digest, set if UploadOptions.Checksum was given
+	Media      *MediaInfo    // Probed metadata, set if a probe understood the file
+	Attempts   []AttemptInfo // HTTP attempts of the upload, if Client.Verbose is set
+}
+
+// Upload uploads a local file to the specified remote path using TUS protocol.
+// If the server supports the TUS checksum extension, every chunk is verified and
+// a failed verification is returned as a *ChecksumMismatchError. The returned result
+// confirms the uploaded size and the upload URL assigned by the server.
+func (c *Client) Upload(localPath string, remotePath string) (*UploadResult, error) {
+	return c.UploadWithOptions(localPath, remotePath, UploadOptions{})
+}
+
+// UploadWithOptions uploads a local file to the specified remote path like Upload,
+// applying the given options, and returns details about the completed upload
+func (c *Client) UploadWithOptions(localPath string, remotePath string, opts UploadOptions) (*UploadResult, error) {
+	return c.UploadWithOptionsContext(context.Background(), localPath, remotePath, opts)
 }
 
-// Upload uploads a local file to the specified remote path using TUS protocol
-func (c *Client) Upload(localPath string, remotePath string) error {
+// UploadWithOptionsContext is like UploadWithOptions, sending the headers attached to ctx
+// with WithHeader
+func (c *Client) UploadWithOptionsContext(ctx context.Context, localPath string, remotePath string, opts UploadOptions) (*UploadResult, error) {
+	return c.uploadFile(ctx, localPath, remotePath, opts, true)
+}
+
+// uploadFile uploads a local file, consulting the PreUploadHook if hook is set
+func (c *Client) uploadFile(ctx context.Context, localPath string, remotePath string, opts UploadOptions, hook bool) (_ *UploadResult, err error) {
+	var size int64
+	ctx, start := c.startOp(ctx, OpUpload, remotePath)
+	retry := c.newRetrier(ctx)
+	defer func() {
+		err = c.finishRetriedOp(OpUpload, remotePath, size, start, retry, err)
+		c.postOp(OpResult{Operation: OpUpload, Path: remotePath, Bytes: size, Err: err, Attempts: retry.attempts.list(c.redactError)}, start.at)
+	}()
+
 	if localPath == "" {
-		return fmt.Errorf("local path cannot be empty")
+		return nil, fmt.Errorf("local path cannot be empty")
 	}
 	if remotePath == "" {
-		return fmt.Errorf("remote path cannot be empty")
+		return nil, fmt.Errorf("remote path cannot be empty")
 	}
 
 	// Check if local file exists
-	if _, err := os.Stat(localPath); os.IsNotExist(err) {
-		return fmt.Errorf("local file does not exist: %s", localPath)
+	info, err := os.Stat(localPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("local file does not exist: %s", localPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat local file: %w", err)
+	}
+	size = info.Size()
+
+	if hook {
+		if err := c.checkUpload(ctx, info, remotePath); err != nil {
+			return nil, err
+		}
+	}
+
+	media, err := probeMedia(localPath, opts.Probes)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := c.ensureAuthenticated(); err != nil {
-		return fmt.Errorf("authentication failed: %w", err)
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	if opts.Resume && c.UploadStore == nil {
+		return nil, fmt.Errorf("client has no upload store")
+	}
+
+	if err := c.ensureParents(remotePath); err != nil {
+		return nil, err
+	}
+
+	if opts.Lock {
+		unlock, err := c.acquireLock(remotePath)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
 	}
 
 	// Configure TUS client
-	config := tus.DefaultConfig()
-	config.Header.Set("X-Auth", c.Token)
-	
-	tusClient, err := tus.NewClient(
-		fmt.Sprintf("%s/api/tus/%s", c.URL, remotePath),
-		config,
-	)
+	newConfig := func() *tus.Config { return c.newTusConfig(ctx) }
+	config := newConfig()
+	endpoint, err := c.tusEndpoint(remotePath)
 	if err != nil {
-		return fmt.Errorf("failed to create TUS client: %w", err)
+		return nil, err
+	}
+	tusClient, err := tus.NewClient(endpoint, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TUS client: %w", err)
 	}
 
 	// Open local file
 	file, err := os.Open(localPath)
 	if err != nil {
-		return fmt.Errorf("failed to open local file: %w", err)
+		return nil, fmt.Errorf("failed to open local file: %w", err)
 	}
 	defer file.Close()
 
-	// Create upload from file
-	upload, err := tus.NewUploadFromFile(file)
+	// Hash the file while it is being uploaded if a checksum was requested
+	var source readSeekerAt = file
+	var hasher *hashingReader
+	if opts.Checksum != "" {
+		if hasher, err = newHashingReader(file, opts.Checksum); err != nil {
+			return nil, err
+		}
+		source = hasher
+	}
+
+	// Verify chunks if the server supports checksums
+	server := discoverTus(tusClient, c.log())
+	algo := server.checksumAlgorithm()
+	progress := newTransferProgress(opts.Progress, size)
+
+	var tusURL string
+	if opts.ParallelParts > 1 && size >= int64(opts.ParallelParts) && server.supports(tusExtensionConcatenation) {
+		if tusURL, err = uploadConcatenated(tusClient.Url, newConfig, source, size, opts.ParallelParts, algo, c.trackSession(remotePath), progress, retry); err != nil {
+			return nil, fmt.Errorf("parallel upload failed: %w", err)
+		}
+	} else {
+		// Create upload from file
+		fileUpload, err := tus.NewUploadFromFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create upload from file: %w", err)
+		}
+		upload := tus.NewUpload(source, size, fileUpload.Metadata, fileUpload.Fingerprint)
+
+		// Continue an interrupted upload of the same file if resumption is enabled
+		var uploader *tus.Uploader
+		var fingerprint string
+		if opts.Resume {
+			fingerprint = uploadFingerprint(localPath, info, remotePath)
+			if uploader, err = c.resumeStoredUpload(tusClient, upload, fingerprint); err != nil {
+				return nil, err
+			}
+		}
+
+		// Create uploader
+		if uploader == nil {
+			err = retry.tus(func() (err error) {
+				uploader, err = tusClient.CreateUpload(upload)
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create upload: %w", err)
+			}
+			if opts.Resume {
+				c.UploadStore.Set(fingerprint, uploader.Url())
+			}
+		}
+		progress.add(uploader.Offset())
+
+		// Perform upload
+		untrack := c.trackSession(remotePath)(uploader.Url())
+		if err := uploadChunks(uploader, config, source, size, algo, progress, retry); err != nil {
+			return nil, fmt.Errorf("upload failed: %w", err)
+		}
+		untrack()
+		tusURL = uploader.Url()
+		if opts.Resume {
+			c.UploadStore.Delete(fingerprint)
+		}
+	}
+
+	result := &UploadResult{RemotePath: remotePath, Bytes: size, TusURL: tusURL, Media: media}
+	if hasher != nil {
+		if result.Checksum, err = hasher.Sum(size); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Sidecar {
+		if err := c.uploadSidecar(result, opts.Checksum); err != nil {
+			return nil, err
+		}
+	}
+	mode := opts.Mode
+	if mode == 0 {
+		mode = c.FileMode
+	}
+	if err := c.applyMode(remotePath, mode); err != nil {
+		return nil, err
+	}
+
+	result.Duration = time.Since(start.at)
+	result.Attempts = retry.attempts.list(c.redactError)
+	c.log().Info("Uploaded file", "path", remotePath, "bytes", size, "duration", result.Duration)
+	return result, nil
+}
+
+// UploadReader uploads exactly size bytes read from r to the specified remote path, e.g.
+// an HTTP response body or an in-memory buffer, without writing them to a temporary file.
+// It fails if r ends early.
+func (c *Client) UploadReader(r io.Reader, size int64, remotePath string) (err error) {
+	ctx, start := c.startOp(context.Background(), OpUpload, remotePath)
+	defer func() {
+		err = c.finishOp(OpUpload, remotePath, size, start, err)
+		c.postOp(OpResult{Operation: OpUpload, Path: remotePath, Bytes: size, Err: err}, start.at)
+	}()
+
+	if r == nil {
+		return fmt.Errorf("reader cannot be nil")
+	}
+	if size < 0 {
+		return fmt.Errorf("size cannot be negative")
+	}
+	if remotePath == "" {
+		return fmt.Errorf("remote path cannot be empty")
+	}
+
+	if err := c.checkUpload(ctx, nil, remotePath); err != nil {
+		return err
+	}
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	if err := c.ensureParents(remotePath); err != nil {
+		return err
+	}
+
+	endpoint, err := c.tusEndpoint(remotePath)
+	if err != nil {
+		return err
+	}
+	tusClient, err := tus.NewClient(endpoint, c.newTusConfig(ctx))
 	if err != nil {
-		return fmt.Errorf("failed to create upload from file: %w", err)
+		return fmt.Errorf("failed to create TUS client: %w", err)
+	}
+
+	algo := discoverTus(tusClient, c.log()).checksumAlgorithm()
+	if err := uploadSized(tusClient, r, size, algo, c.trackSession(remotePath), nil); err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	if err := c.applyMode(remotePath, c.FileMode); err != nil {
+		return err
 	}
 
-	// Create uploader
-	uploader, err := tusClient.CreateUpload(upload)
+	c.log().Info("Uploaded stream", "path", remotePath, "bytes", size)
+	return nil
+}
+
+// UploadStream uploads data of unknown length from r to the specified remote path.
+// If the server supports the TUS creation-defer-length extension the stream is sent
+// chunk by chunk as it is read; otherwise it is spooled to a temporary file first.
+func (c *Client) UploadStream(r io.Reader, remotePath string) (err error) {
+	var size int64
+	var spooled bool
+	ctx, start := c.startOp(context.Background(), OpUpload, remotePath)
+	defer func() {
+		// Spooled uploads are reported by Upload itself
+		if spooled {
+			err = c.redactError(err)
+			return
+		}
+		err = c.finishOp(OpUpload, remotePath, size, start, err)
+		c.postOp(OpResult{Operation: OpUpload, Path: remotePath, Bytes: size, Err: err}, start.at)
+	}()
+
+	if r == nil {
+		return fmt.Errorf("reader cannot be nil")
+	}
+	if remotePath == "" {
+		return fmt.Errorf("remote path cannot be empty")
+	}
+
+	if err := c.checkUpload(ctx, nil, remotePath); err != nil {
+		return err
+	}
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	if err := c.ensureParents(remotePath); err != nil {
+		return err
+	}
+
+	endpoint, err := c.tusEndpoint(remotePath)
+	if err != nil {
+		return err
+	}
+	tusClient, err := tus.NewClient(endpoint, c.newTusConfig(ctx))
 	if err != nil {
-		return fmt.Errorf("failed to create upload: %w", err)
+		return fmt.Errorf("failed to create TUS client: %w", err)
+	}
+
+	server := discoverTus(tusClient, c.log())
+	if !server.supports(tusExtensionDeferLength) {
+		c.log().Debug("Server does not support deferred upload length, spooling stream to disk", "path", remotePath)
+		spooled = true
+		return c.uploadSpooled(r, remotePath)
 	}
 
-	// Perform upload
-	if err := uploader.Upload(); err != nil {
+	size, err = uploadDeferred(tusClient, r, server.checksumAlgorithm(), c.trackSession(remotePath), nil)
+	if err != nil {
 		return fmt.Errorf("upload failed: %w", err)
 	}
+	if err := c.applyMode(remotePath, c.FileMode); err != nil {
+		return err
+	}
 
-	log.Printf("Successfully uploaded file to remote path: %s", remotePath)
+	c.log().Info("Uploaded stream", "path", remotePath, "bytes", size)
 	return nil
 }
 
-// Share creates a share link for the specified remote path
+// uploadSpooled writes the stream to a temporary file and uploads it from there
+func (c *Client) uploadSpooled(r io.Reader, remotePath string) error {
+	spool, err := createStagingFile("filebrowser-upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create spool file: %w", err)
+	}
+	defer os.Remove(spool.Name())
+
+	_, err = io.Copy(spool, r)
+	if closeErr := spool.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to spool stream: %w", err)
+	}
+
+	// The stream was already checked by the PreUploadHook
+	_, err = c.uploadFile(context.Background(), spool.Name(), remotePath, UploadOptions{}, false)
+	return err
+}
+
+// tusEndpoint returns the TUS upload URL for a remote path
+func (c *Client) tusEndpoint(remotePath string) (string, error) {
+	return c.apiURL("tus", remotePath)
+}
+
+// newTusConfig creates a TUS configuration authenticated with the client's token and
+// carrying the headers attached to ctx
+func (c *Client) newTusConfig(ctx context.Context) *tus.Config {
+	config := tus.DefaultConfig()
+	config.HttpClient = transferHTTPClient(c.http().GetClient(), timeoutOrDefault(c.Timeouts.Idle, defaultIdleTimeout))
+	for key, values := range c.http().Headers {
+		config.Header[key] = append([]string(nil), values...)
+	}
+	for key, values := range headersFromContext(ctx) {
+		config.Header[key] = append([]string(nil), values...)
+	}
+	config.Header.Set("X-Auth", c.token())
+	return config
+}
+
+// newRequest creates an API request authenticated with the client's token and carrying
+// the headers attached to ctx. It fails after the metadata timeout.
+func (c *Client) newRequest(ctx context.Context) *req.Request {
+	return withTimeout(c.newTransferRequest(ctx), timeoutOrDefault(c.Timeouts.Metadata, defaultMetadataTimeout))
+}
+
+// newTransferRequest creates an API request like newRequest without a total timeout, for
+// transfers watched for progress instead
+func (c *Client) newTransferRequest(ctx context.Context) *req.Request {
+	r := traceTimeouts(c.http().R().SetContext(ctx))
+	r.Headers = headersFromContext(ctx).Clone()
+	return r.SetHeader("X-Auth", c.token())
+}
+
+// Share creates a share link for the specified remote path.
+// If the client has a SharePasswordPolicy, a weak password fails with ErrWeakSharePassword.
 func (c *Client) Share(remotePath string, expires int64, password string, unit string) (string, error) {
+	return c.ShareContext(context.Background(), remotePath, expires, password, unit)
+}
+
+// ShareContext is like Share, sending the headers attached to ctx with WithHeader
+func (c *Client) ShareContext(ctx context.Context, remotePath string, expires int64, password string, unit string) (hash string, err error) {
+	ctx, start := c.startOp(ctx, OpShare, remotePath)
+	retry := c.newRetrier(ctx)
+	defer func() {
+		err = c.finishRetriedOp(OpShare, remotePath, 0, start, retry, err)
+		c.postOp(OpResult{Operation: OpShare, Path: remotePath, ShareHash: hash, Err: err, Attempts: retry.attempts.list(c.redactError)}, start.at)
+	}()
+
 	if remotePath == "" {
 		return "", fmt.Errorf("remote path cannot be empty")
 	}
+	if expires > 0 && !validShareUnit(unit) {
+		return "", fmt.Errorf("unknown share expiry unit %q: %w", unit, ErrInvalidExpiry)
+	}
+	if c.SharePasswordPolicy != nil {
+		if err := c.SharePasswordPolicy.Validate(password); err != nil {
+			return "", err
+		}
+	}
 
 	if err := c.ensureAuthenticated(); err != nil {
 		return "", fmt.Errorf("authentication failed: %w", err)
@@ -183,31 +681,73 @@ func (c *Client) Share(remotePath string, expires int64, password string, unit s
 	}
 
 	// Make share request
+	url, err := c.apiURL("share", remotePath)
+	if err != nil {
+		return "", err
+	}
 	var result RespShare
-	client := req.C()
-	resp, err := client.R().
-		SetHeader("X-Auth", c.Token).
-		SetBody(body).
-		SetSuccessResult(&result).
-		Post(fmt.Sprintf("%s/api/share/%s", c.URL, remotePath))
+	resp, err := retry.send(func() (*req.Response, error) {
+		return c.newRequest(ctx).
+			SetBody(body).
+			SetSuccessResult(&result).
+			Post(url)
+	})
 	if err != nil {
 		return "", fmt.Errorf("share request failed: %w", err)
 	}
 
+	if err := checkDenied(resp, remotePath); err != nil {
+		return "", err
+	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("share request failed with status code: %d", resp.StatusCode)
+		return "", fmt.Errorf("share request failed with %w", newAPIError(resp))
 	}
 
 	if result.Hash == "" {
 		return "", fmt.Errorf("received empty hash from server")
 	}
 
-	log.Printf("Successfully created share for path: %s", remotePath)
+	c.log().Info("Created share", "path", remotePath)
 	return result.Hash, nil
 }
 
-// GetResource retrieves information about a resource at the specified path
+// ShareIfExists creates a share link like Share, but only if the remote path exists.
+// A missing path returns an error matching ErrNotFound without creating anything, as some
+// server versions otherwise create a dead share.
+func (c *Client) ShareIfExists(remotePath string, params ShareParams) (string, error) {
+	params, err := params.resolve(time.Now())
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.GetResource(remotePath); err != nil {
+		return "", fmt.Errorf("cannot share %s: %w", remotePath, err)
+	}
+
+	return c.Share(remotePath, params.Expires, params.Password, params.Unit)
+}
+
+// ShareWithExpiry creates a share link like Share, expiring as given. An expiry not in the
+// future fails with an error matching ErrInvalidExpiry.
+func (c *Client) ShareWithExpiry(remotePath string, expiry ShareExpiry, password string) (string, error) {
+	params, err := ShareParams{Expiry: expiry, Password: password}.resolve(time.Now())
+	if err != nil {
+		return "", err
+	}
+	return c.Share(remotePath, params.Expires, params.Password, params.Unit)
+}
+
+// GetResource retrieves information about a resource at the specified path.
+// A missing resource returns an error matching ErrNotFound.
 func (c *Client) GetResource(remotePath string) (*RespResource, error) {
+	return c.GetResourceContext(context.Background(), remotePath)
+}
+
+// GetResourceContext is like GetResource, sending the headers attached to ctx with WithHeader
+func (c *Client) GetResourceContext(ctx context.Context, remotePath string) (_ *RespResource, err error) {
+	ctx, start := c.startOp(ctx, OpGetResource, remotePath)
+	retry := c.newRetrier(ctx)
+	defer func() { err = c.finishRetriedOp(OpGetResource, remotePath, 0, start, retry, err) }()
+
 	if remotePath == "" {
 		return nil, fmt.Errorf("remote path cannot be empty")
 	}
@@ -217,52 +757,229 @@ func (c *Client) GetResource(remotePath string) (*RespResource, error) {
 	}
 
 	// Make resource request
-	var result RespResource
-	client := req.C()
-	url := fmt.Sprintf("%s/api/resources/%s", c.URL, remotePath)
-	resp, err := client.R().
-		SetHeader("X-Auth", c.Token).
-		SetSuccessResult(&result).
-		Get(url)
+	url, err := c.apiURL("resources", remotePath)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := retry.send(func() (*req.Response, error) {
+		return c.hedged(ctx, url, func(ctx context.Context, url string) (*req.Response, error) {
+			return c.newRequest(ctx).
+				SetSuccessResult(&RespResource{}).
+				Get(url)
+		})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("resource request failed: %w", err)
 	}
 
-	if resp.StatusCode == http.StatusNotFound {
-		return &RespResource{NotExist: true}, nil
+	if err := checkDenied(resp, remotePath); err != nil {
+		return nil, err
 	}
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("resource request failed with status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("resource request failed with %w", newAPIError(resp))
 	}
 
-	return &result, nil
+	return resp.SuccessResult().(*RespResource), nil
+}
+
+// lookupResource retrieves a resource like GetResource, returning nil without an error
+// if it does not exist
+func (c *Client) lookupResource(remotePath string) (*RespResource, error) {
+	return c.lookupResourceContext(context.Background(), remotePath)
+}
+
+// lookupResourceContext is like lookupResource, sending the headers attached to ctx
+func (c *Client) lookupResourceContext(ctx context.Context, remotePath string) (*RespResource, error) {
+	return findResource(ctx, c, remotePath)
 }
 
-// DeleteResource deletes a resource at the specified path
+// DeleteResource deletes a resource at the specified path. The root and paths shallower
+// than MinDeleteDepth are refused with an error matching ErrDangerousPath.
 func (c *Client) DeleteResource(remotePath string) error {
+	return c.DeleteResourceContext(context.Background(), remotePath)
+}
+
+// DeleteResourceContext is like DeleteResource, sending the headers attached to ctx with WithHeader
+func (c *Client) DeleteResourceContext(ctx context.Context, remotePath string) (err error) {
+	ctx, start := c.startOp(ctx, OpDeleteResource, remotePath)
+	retry := c.newRetrier(ctx)
+	defer func() {
+		err = c.finishRetriedOp(OpDeleteResource, remotePath, 0, start, retry, err)
+		c.postOp(OpResult{Operation: OpDeleteResource, Path: remotePath, Err: err, Attempts: retry.attempts.list(c.redactError)}, start.at)
+	}()
+
 	if remotePath == "" {
 		return fmt.Errorf("remote path cannot be empty")
 	}
+	if err := c.checkDestructive(remotePath); err != nil {
+		return err
+	}
 
 	if err := c.ensureAuthenticated(); err != nil {
 		return fmt.Errorf("authentication failed: %w", err)
 	}
 
 	// Make delete request
-	client := req.C()
-	url := fmt.Sprintf("%s/api/resources/%s", c.URL, remotePath)
-	resp, err := client.R().
-		SetHeader("X-Auth", c.Token).
-		Delete(url)
+	url, err := c.apiURL("resources", remotePath)
+	if err != nil {
+		return err
+	}
+	resp, err := retry.send(func() (*req.Response, error) {
+		return c.newRequest(ctx).Delete(url)
+	})
 	if err != nil {
 		return fmt.Errorf("delete request failed: %w", err)
 	}
 
+	if err := checkDenied(resp, remotePath); err != nil {
+		return err
+	}
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
-		return fmt.Errorf("delete request failed with status code: %d", resp.StatusCode)
+		return fmt.Errorf("delete request failed with %w", newAPIError(resp))
+	}
+
+	c.log().Info("Deleted resource", "path", remotePath)
+	return nil
+}
+
+// Move renames the resource at src to dst. An existing resource at dst is replaced
+// only if overwrite is set, otherwise the move fails with an error matching ErrAlreadyExists.
+func (c *Client) Move(src string, dst string, overwrite bool) error {
+	return c.MoveContext(context.Background(), src, dst, overwrite)
+}
+
+// MoveContext is like Move, sending the headers attached to ctx with WithHeader
+func (c *Client) MoveContext(ctx context.Context, src string, dst string, overwrite bool) (err error) {
+	ctx, start := c.startOp(ctx, OpMoveResource, src)
+	defer func() {
+		err = c.finishOp(OpMoveResource, src, 0, start, err)
+		c.postOp(OpResult{Operation: OpMoveResource, Path: src, Destination: dst, Err: err}, start.at)
+	}()
+
+	if err := c.patchResource(ctx, "rename", src, dst, overwrite); err != nil {
+		return err
+	}
+
+	c.log().Info("Moved resource", "path", src, "destination", dst)
+	return nil
+}
+
+// Copy copies the resource at src to dst. An existing resource at dst is replaced
+// only if overwrite is set, otherwise the copy fails with an error matching ErrAlreadyExists.
+func (c *Client) Copy(src string, dst string, overwrite bool) error {
+	return c.CopyContext(context.Background(), src, dst, overwrite)
+}
+
+// CopyContext is like Copy, sending the headers attached to ctx with WithHeader
+func (c *Client) CopyContext(ctx context.Context, src string, dst string, overwrite bool) (err error) {
+	ctx, start := c.startOp(ctx, OpCopyResource, src)
+	defer func() {
+		err = c.finishOp(OpCopyResource, src, 0, start, err)
+		c.postOp(OpResult{Operation: OpCopyResource, Path: src, Destination: dst, Err: err}, start.at)
+	}()
+
+	if err := c.patchResource(ctx, "copy", src, dst, overwrite); err != nil {
+		return err
 	}
 
-	log.Printf("Successfully deleted resource: %s", remotePath)
+	c.log().Info("Copied resource", "path", src, "destination", dst)
 	return nil
 }
+
+// patchResource applies a rename or copy action to the resource at src
+func (c *Client) patchResource(ctx context.Context, action string, src string, dst string, overwrite bool) error {
+	if src == "" || dst == "" {
+		return fmt.Errorf("source and destination paths cannot be empty")
+	}
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	if err := c.ensureParents(dst); err != nil {
+		return err
+	}
+
+	// Make patch request
+	url, err := c.apiURL("resources", src)
+	if err != nil {
+		return err
+	}
+	destination, err := cleanRemotePath(dst)
+	if err != nil {
+		return err
+	}
+	resp, err := c.newRequest(ctx).
+		SetQueryParam("action", action).
+		SetQueryParam("destination", "/"+destination).
+		SetQueryParam("override", strconv.FormatBool(overwrite)).
+		Patch(url)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", action, err)
+	}
+
+	if err := checkDenied(resp, src); err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusConflict:
+		return fmt.Errorf("cannot %s %s to %s: %w: %w", action, src, dst, ErrAlreadyExists, newAPIError(resp))
+	case http.StatusNotFound:
+		return fmt.Errorf("cannot %s %s: %w", action, src, newAPIError(resp))
+	default:
+		return fmt.Errorf("%s request failed with %w", action, newAPIError(resp))
+	}
+}
+
+// GetChecksum retrieves the checksum of a remote file computed by the server.
+// Supported algorithms are "md5", "sha1", "sha256" and "sha512".
+func (c *Client) GetChecksum(remotePath string, algo string) (string, error) {
+	return c.GetChecksumContext(context.Background(), remotePath, algo)
+}
+
+// GetChecksumContext is like GetChecksum, sending the headers attached to ctx with WithHeader
+func (c *Client) GetChecksumContext(ctx context.Context, remotePath string, algo string) (_ string, err error) {
+	ctx, start := c.startOp(ctx, OpGetChecksum, remotePath)
+	defer func() { err = c.finishOp(OpGetChecksum, remotePath, 0, start, err) }()
+
+	if remotePath == "" {
+		return "", fmt.Errorf("remote path cannot be empty")
+	}
+	if algo == "" {
+		return "", fmt.Errorf("checksum algorithm cannot be empty")
+	}
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return "", fmt.Errorf("authentication failed: %w", err)
+	}
+
+	// Make checksum request
+	var result RespResource
+	url, err := c.apiURL("resources", remotePath)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.newRequest(ctx).
+		SetQueryParam("checksum", algo).
+		SetSuccessResult(&result).
+		Get(url)
+	if err != nil {
+		return "", fmt.Errorf("checksum request failed: %w", err)
+	}
+
+	if err := checkDenied(resp, remotePath); err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("checksum request failed with %w", newAPIError(resp))
+	}
+
+	checksum := result.Checksums[algo]
+	if checksum == "" {
+		return "", fmt.Errorf("server returned no %s checksum for %s", algo, remotePath)
+	}
+
+	return checksum, nil
+}
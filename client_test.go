@@ -0,0 +1,338 @@
+package filebrowser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eventials/go-tus"
+)
+
+// testFile is a remote file served by the fake Filebrowser instance
+type testFile struct {
+	content  []byte
+	modified time.Time
+}
+
+// newResourceServer starts a fake Filebrowser instance serving the given remote files
+func newResourceServer(t *testing.T, files map[string][]byte) *httptest.Server {
+	t.Helper()
+
+	tree := make(map[string]testFile, len(files))
+	for name, content := range files {
+		tree[name] = testFile{content: content}
+	}
+	return newTreeServer(t, tree)
+}
+
+// newTreeServer starts a fake Filebrowser instance serving the given remote files.
+// Directories are derived from the file paths.
+func newTreeServer(t *testing.T, files map[string]testFile) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/login":
+			w.Write([]byte("test-token"))
+		case strings.HasPrefix(r.URL.Path, "/api/resources"):
+			name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/resources"), "/")
+			resource, ok := testResource(files, name)
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if algo := r.URL.Query().Get("checksum"); algo == "sha256" {
+				sum := sha256.Sum256(files[name].content)
+				resource.Checksums = map[string]string{algo: hex.EncodeToString(sum[:])}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(resource)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// testResource builds the resource response for a file or directory of the fake tree
+func testResource(files map[string]testFile, name string) (RespResource, bool) {
+	if file, ok := files[name]; ok {
+		return RespResource{
+			Path:     "/" + name,
+			Name:     path.Base(name),
+			Size:     int64(len(file.content)),
+			Modified: file.modified,
+			IsDir:    false,
+			Type:     testFileType(name),
+		}, true
+	}
+
+	prefix := name + "/"
+	if name == "" {
+		prefix = ""
+	}
+	dir := RespResource{Path: "/" + name, Name: path.Base(name), IsDir: true, Items: []RespResource{}}
+	children := map[string]bool{}
+	for filePath := range files {
+		rest, ok := strings.CutPrefix(filePath, prefix)
+		if !ok {
+			continue
+		}
+		child, _, _ := strings.Cut(rest, "/")
+		children[prefix+child] = true
+	}
+	if len(children) == 0 {
+		return RespResource{}, false
+	}
+	for child := range children {
+		item, _ := testResource(files, child)
+		item.Items = nil
+		dir.Items = append(dir.Items, item)
+	}
+	sort.Slice(dir.Items, func(i, j int) bool { return dir.Items[i].Path < dir.Items[j].Path })
+	return dir, true
+}
+
+// testFileType returns the type the server reports for a file name
+func testFileType(name string) string {
+	switch path.Ext(name) {
+	case ".jpg", ".png", ".gif":
+		return "image"
+	case ".txt", ".md":
+		return "text"
+	default:
+		return "blob"
+	}
+}
+
+func TestShareIfExistsMissing(t *testing.T) {
+	server := newResourceServer(t, map[string][]byte{})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	_, err := client.ShareIfExists("missing.txt", ShareParams{})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("ShareIfExists() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStatMany(t *testing.T) {
+	server := newResourceServer(t, map[string][]byte{
+		"a.txt":     []byte("a"),
+		"dir/b.txt": []byte("bb"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	results, err := client.StatMany([]string{"a.txt", "dir/b.txt", "missing.txt", "a.txt"})
+	if err != nil {
+		t.Fatalf("StatMany() error = %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("StatMany() returned %d results, want 2", len(results))
+	}
+	if results["a.txt"].Size != 1 || results["dir/b.txt"].Size != 2 {
+		t.Errorf("unexpected sizes: a.txt=%d, dir/b.txt=%d", results["a.txt"].Size, results["dir/b.txt"].Size)
+	}
+	if _, ok := results["missing.txt"]; ok {
+		t.Error("missing.txt should be left out")
+	}
+}
+
+func TestDeniedByRule(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/login":
+			w.Write([]byte("test-token"))
+		case strings.Contains(r.URL.Path, "/secret/"):
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("403 Forbidden"))
+		case strings.Contains(r.URL.Path, "/blocked/"):
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("<html><body>Request blocked by firewall</body></html>"))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	localPath, _ := writeTestFile(t, 16)
+
+	checks := map[string]error{}
+	_, checks["GetResource"] = client.GetResource("secret/a.txt")
+	_, checks["Share"] = client.Share("secret/a.txt", 0, "", "")
+	checks["DeleteResource"] = client.DeleteResource("secret/a.txt")
+	_, checks["Upload"] = client.Upload(localPath, "secret/a.txt")
+	for name, err := range checks {
+		var denied *DeniedByRuleError
+		if !errors.As(err, &denied) || denied.Path != "secret/a.txt" {
+			t.Errorf("%s() error = %v, want DeniedByRuleError for secret/a.txt", name, err)
+		}
+	}
+
+	if _, err := client.GetResource("public/a.txt"); err == nil || errors.Is(err, ErrDeniedByRule) {
+		t.Errorf("GetResource() with unauthorized status error = %v, want non rule error", err)
+	}
+	var apiErr *APIError
+	if _, err := client.GetResource("blocked/a.txt"); errors.Is(err, ErrDeniedByRule) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("GetResource() with a proxy 403 error = %v, want APIError", err)
+	}
+	if _, err := client.Upload(localPath, "blocked/a.txt"); errors.Is(err, ErrDeniedByRule) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Upload() with a proxy 403 error = %v, want APIError", err)
+	}
+}
+
+func TestMoveAndCopy(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"tmp/a.txt":    []byte("a"),
+		"tmp/b.txt":    []byte("b"),
+		"public/b.txt": []byte("old"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	if err := client.Copy("tmp/a.txt", "backup/a.txt", false); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if err := client.Move("tmp/a.txt", "public/a.txt", false); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if err := client.Move("tmp/b.txt", "public/b.txt", false); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("Move() onto existing file error = %v, want ErrAlreadyExists", err)
+	}
+	if err := client.Move("tmp/b.txt", "public/b.txt", true); err != nil {
+		t.Fatalf("Move() with overwrite error = %v", err)
+	}
+	if err := client.Copy("tmp/missing.txt", "public/missing.txt", false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Copy() of missing file error = %v, want ErrNotFound", err)
+	}
+
+	expected := map[string]string{"backup/a.txt": "a", "public/a.txt": "a", "public/b.txt": "b"}
+	for name, want := range expected {
+		if got, _ := server.file(name); string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	for _, name := range []string{"tmp/a.txt", "tmp/b.txt"} {
+		if _, ok := server.file(name); ok {
+			t.Errorf("%s still exists after Move()", name)
+		}
+	}
+}
+
+func TestMoveAndCopyContext(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"tmp/a.txt": []byte("a")})
+	var requestIDs []string
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			requestIDs = append(requestIDs, r.Header.Get("X-Request-Id"))
+		}
+		next.ServeHTTP(w, r)
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	ctx := WithHeader(context.Background(), "X-Request-Id", "req-1")
+	if err := client.CopyContext(ctx, "tmp/a.txt", "tmp/b.txt", false); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+	if err := client.MoveContext(ctx, "tmp/b.txt", "tmp/c.txt", false); err != nil {
+		t.Fatalf("MoveContext() error = %v", err)
+	}
+	if len(requestIDs) != 2 || requestIDs[0] != "req-1" || requestIDs[1] != "req-1" {
+		t.Errorf("request IDs = %v, want the header of ctx on both requests", requestIDs)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.MoveContext(cancelled, "tmp/c.txt", "tmp/d.txt", false); !errors.Is(err, context.Canceled) {
+		t.Errorf("MoveContext() with a cancelled context error = %v, want context.Canceled", err)
+	}
+	if _, ok := server.file("tmp/c.txt"); !ok {
+		t.Error("cancelled move should leave the file in place")
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/login":
+			w.Write([]byte("test-token"))
+		case strings.HasPrefix(r.URL.Path, "/api/resources/missing"):
+			http.Error(w, "not found", http.StatusNotFound)
+		case strings.HasPrefix(r.URL.Path, "/api/resources/locked"):
+			http.Error(w, "locked by another request", http.StatusConflict)
+		case strings.HasPrefix(r.URL.Path, "/api/tus/") && r.Method == http.MethodOptions:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	_, err := client.GetResource("missing.txt")
+	var apiErr *APIError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Body != "not found" {
+		t.Errorf("GetResource() error = %v, want APIError matching ErrNotFound", err)
+	}
+	if err := client.DeleteResource("locked.txt"); !errors.Is(err, ErrConflict) || errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteResource() error = %v, want ErrConflict", err)
+	}
+
+	localPath, _ := writeTestFile(t, 16)
+	_, err = client.Upload(localPath, "a.txt")
+	var clientErr tus.ClientError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError || !errors.As(err, &clientErr) {
+		t.Errorf("Upload() error = %v, want APIError wrapping the TUS error", err)
+	}
+
+	unauthorized := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	if err := unauthorized.Login(); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Login() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestRespResourceJSON(t *testing.T) {
+	data := `{"path":"/docs","name":"docs","size":4096,"modified":"2024-05-01T10:00:00.5+02:00","mode":2147484141,
+		"isDir":true,"isSymlink":false,"type":"","items":[
+		{"path":"/docs/a.txt","name":"a.txt","size":1,"modified":"2024-05-01T08:00:00Z","isDir":false,"isSymlink":"true"},
+		{"path":"/docs/b.txt","name":"b.txt","size":2,"IsDir":"false"}]}`
+
+	var resource RespResource
+	if err := json.Unmarshal([]byte(data), &resource); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	modified := time.Date(2024, 5, 1, 8, 0, 0, 500000000, time.UTC)
+	if !resource.IsDir || resource.IsSymlink || !resource.Modified.Equal(modified) || resource.Mode != fs.ModeDir|0o755 {
+		t.Errorf("resource = %+v", resource)
+	}
+	if len(resource.Items) != 2 {
+		t.Fatalf("Items = %+v, want 2", resource.Items)
+	}
+	if item := resource.Items[0]; item.IsDir || !item.IsSymlink || !item.Modified.Equal(modified.Add(-500*time.Millisecond)) {
+		t.Errorf("Items[0] = %+v", item)
+	}
+	if item := resource.Items[1]; item.IsDir || !item.Modified.IsZero() {
+		t.Errorf("Items[1] = %+v, want a file without modification time", item)
+	}
+
+	if err := json.Unmarshal([]byte(`{"modified":"yesterday"}`), &resource); err == nil {
+		t.Error("Unmarshal() accepted an invalid modification time")
+	}
+}
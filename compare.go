@@ -0,0 +1,154 @@
+package filebrowser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// compareChunkSize is the size of the ranges of the remote file fetched by
+// CompareRemoteLocal
+const compareChunkSize = 4 << 20
+
+// CompareRemoteLocal compares a remote file with a local file byte by byte, for
+// verification where checksums are unsupported by the server or not enough. The remote
+// file is fetched in ranges compared as they arrive, stopping at the first difference, so
+// a mismatch early in a large file downloads little of it. firstDiffOffset is the offset
+// of the first differing byte, the size of the shorter file if it is a prefix of the
+// longer one, and -1 if the files are equal.
+func (c *Client) CompareRemoteLocal(remotePath string, localPath string) (equal bool, firstDiffOffset int64, err error) {
+	_, start := c.startOp(context.Background(), OpDownload, remotePath)
+	var compared int64
+	defer func() { err = c.finishOp(OpDownload, remotePath, compared, start, err) }()
+
+	if localPath == "" {
+		return false, 0, fmt.Errorf("local path cannot be empty")
+	}
+	file, err := os.Open(localPath)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to stat local file: %w", err)
+	}
+	if info.IsDir() {
+		return false, 0, fmt.Errorf("%s is a directory", localPath)
+	}
+
+	resource, err := c.GetResource(remotePath)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to get resource info: %w", err)
+	}
+	if resource.IsDir {
+		return false, 0, fmt.Errorf("%s is a directory", remotePath)
+	}
+
+	url, err := c.apiURL("raw", remotePath)
+	if err != nil {
+		return false, 0, err
+	}
+	limit := min(info.Size(), resource.Size)
+	for compared < limit {
+		n, diff, err := c.compareRange(url, remotePath, file, compared, min(compareChunkSize, limit-compared), limit)
+		compared += n
+		if err != nil {
+			return false, 0, err
+		}
+		if diff >= 0 {
+			c.log().Info("Found difference", "path", remotePath, "local_path", localPath, "offset", diff)
+			return false, diff, nil
+		}
+	}
+
+	if info.Size() != resource.Size {
+		return false, limit, nil
+	}
+	return true, -1, nil
+}
+
+// compareRange compares length bytes of the remote file at url from offset with file,
+// returning the number of bytes compared and the offset of the first difference, -1 if
+// there is none. A server ignoring the range sends the whole file, which is then compared
+// up to limit at once.
+func (c *Client) compareRange(url string, remotePath string, file *os.File, offset int64, length int64, limit int64) (int64, int64, error) {
+	ctx := context.Background()
+	watch := watchIdle(ctx, timeoutOrDefault(c.Timeouts.Idle, defaultIdleTimeout))
+	resp, err := c.newTransferRequest(watch.ctx).
+		DisableAutoReadResponse().
+		SetHeader("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)).
+		Get(url)
+	if err != nil {
+		watch.stop()
+		return 0, -1, fmt.Errorf("download request for bytes %d-%d failed: %w", offset, offset+length-1, watch.err(err))
+	}
+	body := watch.reader(resp.Body)
+	defer body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if got, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || got != offset {
+			return 0, -1, fmt.Errorf("server returned an unexpected range: %s", resp.Header.Get("Content-Range"))
+		}
+	case http.StatusOK:
+		// The server ignored the range
+		if _, err := io.CopyN(io.Discard, body, offset); err != nil {
+			return 0, -1, fmt.Errorf("failed to skip %d bytes: %w", offset, err)
+		}
+		length = limit - offset
+	default:
+		if err := checkDenied(resp, remotePath); err != nil {
+			return 0, -1, err
+		}
+		return 0, -1, fmt.Errorf("download request failed with %w", newAPIError(resp))
+	}
+
+	return compareReader(body, file, offset, length)
+}
+
+// compareReader compares length bytes of r with file from offset, returning the number of
+// bytes compared and the offset of the first difference, -1 if there is none
+func compareReader(r io.Reader, file *os.File, offset int64, length int64) (int64, int64, error) {
+	remote := make([]byte, 32<<10)
+	local := make([]byte, len(remote))
+	var compared int64
+	for compared < length {
+		n, err := io.ReadFull(r, remote[:min(int64(len(remote)), length-compared)])
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			// The remote file shrank since its size was checked
+			err = fmt.Errorf("remote file ended after %d bytes: %w", offset+compared+int64(n), io.ErrUnexpectedEOF)
+		}
+		if n > 0 {
+			if _, err := file.ReadAt(local[:n], offset+compared); err != nil {
+				return compared, -1, fmt.Errorf("failed to read local file: %w", err)
+			}
+			if i := firstDifference(remote[:n], local[:n]); i >= 0 {
+				return compared + int64(i), offset + compared + int64(i), nil
+			}
+			compared += int64(n)
+		}
+		if err != nil {
+			return compared, -1, err
+		}
+	}
+	return compared, -1, nil
+}
+
+// firstDifference returns the index of the first byte differing between a and b, which
+// have the same length, -1 if they are equal
+func firstDifference(a []byte, b []byte) int {
+	if bytes.Equal(a, b) {
+		return -1
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return -1
+}
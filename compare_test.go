@@ -0,0 +1,98 @@
+package filebrowser
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCompareRemoteLocal(t *testing.T) {
+	// Larger than a range, differing in the second one
+	large := bytes.Repeat([]byte("0123456789abcdef"), (compareChunkSize+1024)/16)
+	changed := bytes.Clone(large)
+	changed[compareChunkSize+100] = 'x'
+
+	server := newMemServer(t, map[string][]byte{
+		"same.txt":  []byte("hello world"),
+		"large.bin": large,
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	var ranges atomic.Int32
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/raw/") && r.Header.Get("Range") != "" {
+			ranges.Add(1)
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	dir := t.TempDir()
+	write := func(name string, content []byte) string {
+		t.Helper()
+		localPath := filepath.Join(dir, name)
+		if err := os.WriteFile(localPath, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return localPath
+	}
+
+	tests := []struct {
+		name       string
+		remotePath string
+		local      []byte
+		equal      bool
+		offset     int64
+	}{
+		{"equal", "same.txt", []byte("hello world"), true, -1},
+		{"different byte", "same.txt", []byte("hello wOrld"), false, 7},
+		{"local shorter", "same.txt", []byte("hello"), false, 5},
+		{"local longer", "same.txt", []byte("hello world!"), false, 11},
+		{"empty", "same.txt", nil, false, 0},
+		{"large equal", "large.bin", large, true, -1},
+		{"large different", "large.bin", changed, false, compareChunkSize + 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, offset, err := client.CompareRemoteLocal(tt.remotePath, write(tt.name, tt.local))
+			if err != nil {
+				t.Fatalf("CompareRemoteLocal: %v", err)
+			}
+			if equal != tt.equal || offset != tt.offset {
+				t.Errorf("CompareRemoteLocal = %v, %d, want %v, %d", equal, offset, tt.equal, tt.offset)
+			}
+		})
+	}
+	if ranges.Load() == 0 {
+		t.Error("no ranged requests were made")
+	}
+
+	if _, _, err := client.CompareRemoteLocal("missing.txt", write("missing", nil)); err == nil {
+		t.Error("comparing a missing remote file succeeded")
+	}
+}
+
+func TestCompareRemoteLocalIgnoredRange(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("abcdefgh")})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	// A server ignoring ranges sends the whole file
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del("Range")
+		next.ServeHTTP(w, r)
+	})
+
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("abcdefgX"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	equal, offset, err := client.CompareRemoteLocal("a.txt", localPath)
+	if err != nil || equal || offset != 7 {
+		t.Fatalf("CompareRemoteLocal = %v, %d, %v, want false, 7", equal, offset, err)
+	}
+}
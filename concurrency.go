@@ -0,0 +1,70 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrConcurrentModification is returned when a remote file changes while an operation
+// based on its earlier state is in progress
+var ErrConcurrentModification = errors.New("resource modified concurrently")
+
+// keyedMutex serializes callers sharing a key, callers with different keys do not wait.
+// The zero value is ready to use.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock is the lock of one key and the number of callers holding or waiting for it
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock blocks until key is free and returns the function releasing it
+func (m *keyedMutex) lock(key string) (unlock func()) {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = map[string]*keyedLock{}
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if l.refs--; l.refs == 0 {
+			delete(m.locks, key)
+		}
+	}
+}
+
+// saveLocks serializes SaveAndShare calls writing the same remote file within the process
+var saveLocks keyedMutex
+
+// checkUnchanged fails with an error matching ErrConcurrentModification if remotePath no
+// longer matches the state observed earlier, nil if it did not exist, i.e. it was created,
+// deleted or rewritten since
+func checkUnchanged(api ResourceAPI, remotePath string, observed *RespResource) error {
+	current, err := findResource(context.Background(), api, remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to get resource info: %w", err)
+	}
+	changed := (current == nil) != (observed == nil) ||
+		(current != nil && (current.Size != observed.Size || !current.Modified.Equal(observed.Modified)))
+	if changed {
+		return fmt.Errorf("%s changed during the operation: %w", remotePath, ErrConcurrentModification)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package filebrowser
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	var m keyedMutex
+	var mu sync.Mutex
+	active := map[string]int{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		key := []string{"a", "b"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := m.lock(key)
+			defer unlock()
+
+			mu.Lock()
+			active[key]++
+			if active[key] > 1 {
+				t.Errorf("%d callers hold key %s", active[key], key)
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			active[key]--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(m.locks) != 0 {
+		t.Errorf("locks = %v, want empty after release", m.locks)
+	}
+}
+
+func TestCheckUnchanged(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"dir/file.txt": []byte("hello")})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	observed, err := client.GetResource("dir/file.txt")
+	if err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if err := checkUnchanged(client, "dir/file.txt", observed); err != nil {
+		t.Fatalf("checkUnchanged() error = %v, want nil", err)
+	}
+
+	server.mu.Lock()
+	server.files["dir/file.txt"] = testFile{content: []byte("hello, world"), modified: time.Now()}
+	server.mu.Unlock()
+
+	if err := checkUnchanged(client, "dir/file.txt", observed); !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("checkUnchanged() error = %v, want ErrConcurrentModification", err)
+	}
+
+	if _, err := client.GetResource("dir/other.txt"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetResource() error = %v, want ErrNotFound", err)
+	}
+	if err := client.UploadStream(strings.NewReader("x"), "dir/other.txt"); err != nil {
+		t.Fatalf("UploadStream() error = %v", err)
+	}
+	if err := checkUnchanged(client, "dir/other.txt", nil); !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("checkUnchanged() after creation error = %v, want ErrConcurrentModification", err)
+	}
+}
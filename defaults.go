@@ -0,0 +1,53 @@
+package filebrowser
+
+import "sync"
+
+// Defaults configures the functions working without a Client created by the caller, like
+// SaveAndShare, Pipeline.Run and DownloadToLocal, for the settings their parameters leave
+// unset
+type Defaults struct {
+	// Logger receives the log messages of downloads and of the clients created for runs
+	Logger Logger
+	// Retry retries transient failures of the clients created for runs
+	Retry *RetryPolicy
+	// Timeouts limit the requests of downloads and of the clients created for runs
+	Timeouts Timeouts
+	// StagingDir is the directory the staging directories of processes are created in
+	// instead of the system temp dir, see StagingDir
+	StagingDir string
+}
+
+var (
+	defaultsMu sync.RWMutex
+	defaults   Defaults
+)
+
+// SetDefaults replaces the package-level defaults. It is safe to call concurrently with
+// running operations, which use the defaults current when they start. Set StagingDir
+// before the first download, as partial downloads are not found in another directory.
+func SetDefaults(d Defaults) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+
+	defaults = d
+}
+
+// CurrentDefaults returns the package-level defaults set by SetDefaults
+func CurrentDefaults() Defaults {
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+
+	return defaults
+}
+
+// withDefaults returns opts with unset fields filled in from the package-level defaults
+func (opts DownloadOptions) withDefaults() DownloadOptions {
+	d := CurrentDefaults()
+	if opts.Logger == nil {
+		opts.Logger = d.Logger
+	}
+	if opts.IdleTimeout == 0 {
+		opts.IdleTimeout = d.Timeouts.Idle
+	}
+	return opts
+}
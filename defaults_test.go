@@ -0,0 +1,64 @@
+package filebrowser
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetDefaults(t *testing.T) {
+	t.Cleanup(func() { SetDefaults(Defaults{}) })
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("payload"))
+	}))
+	defer origin.Close()
+	server := newMemServer(t, nil)
+
+	var logs bytes.Buffer
+	retry := &RetryPolicy{MaxAttempts: 2}
+	stagingRoot := t.TempDir()
+	SetDefaults(Defaults{
+		Logger:     slog.New(slog.NewTextHandler(&logs, nil)),
+		Retry:      retry,
+		Timeouts:   Timeouts{Idle: time.Minute},
+		StagingDir: stagingRoot,
+	})
+
+	var client *Client
+	var localPath string
+	pipeline := &Pipeline{After: func(stage string, state *PipelineState) error {
+		client, localPath = state.Client, state.LocalPath
+		return nil
+	}}
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	if _, err := pipeline.Run(auth, origin.URL+"/file.txt", func(name string) string { return name }, ActionParams{URLPolicy: localOrigin}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if client.Retry != retry || client.Timeouts.Idle != time.Minute {
+		t.Errorf("client retry = %v, timeouts = %v, want the defaults", client.Retry, client.Timeouts)
+	}
+	if !strings.HasPrefix(localPath, stagingRoot+string(filepath.Separator)) {
+		t.Errorf("local path = %v, want below %v", localPath, stagingRoot)
+	}
+	if out := logs.String(); !strings.Contains(out, "Downloaded file") || !strings.Contains(out, "Created share") {
+		t.Errorf("logs = %s, want download and client messages", out)
+	}
+
+	// Parameters take precedence over the defaults
+	var own bytes.Buffer
+	logs.Reset()
+	params := ActionParams{URLPolicy: localOrigin, Logger: slog.New(slog.NewTextHandler(&own, nil))}
+	if _, err := pipeline.Run(auth, origin.URL+"/other.txt", func(name string) string { return name }, params); err != nil {
+		t.Fatalf("Run() with logger error = %v", err)
+	}
+	if logs.Len() > 0 || own.Len() == 0 {
+		t.Errorf("default logs = %q, own logs = %q, want only the own logger used", logs.String(), own.String())
+	}
+}
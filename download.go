@@ -0,0 +1,183 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// DownloadOptions contains optional parameters for downloads
+type DownloadOptions struct {
+	// Progress, if set, is called as data is received
+	Progress ProgressFunc
+	// Logger, if set, receives log messages of downloads made without a client
+	Logger Logger
+	// Auth, if set, authenticates downloads made without a client with the server of the URL
+	Auth *SourceAuth
+	// URLPolicy, if set, restricts the URLs downloads made without a client may fetch
+	URLPolicy *URLPolicy
+	// MaxBytes, if positive, limits the size of downloads made without a client. Larger
+	// files fail with a DownloadTooLargeError as soon as the limit is exceeded.
+	MaxBytes int64
+	// IdleTimeout limits the time downloads made without a client may go without
+	// receiving data, see Timeouts.Idle. Zero uses the default, negative disables it.
+	IdleTimeout time.Duration
+
+	// Dir, if set, is the directory downloads made without a client are written to instead
+	// of the staging directory, see StagingDir
+	Dir string
+	// FileNameFn, if set, returns the path relative to the directory a URL is downloaded to.
+	// By default the path of the URL is mirrored, so files of the same name from different
+	// hosts share a path.
+	FileNameFn func(fileURL string) string
+	// Overwrite downloads the file even if a file of the expected size already exists,
+	// without a conditional request
+	Overwrite bool
+	// MaxRedirects limits the redirects downloads made without a client follow, overriding
+	// the limit of URLPolicy. Zero keeps the limit of the policy or the HTTP client,
+	// negative follows none.
+	MaxRedirects int
+	// Connections, if greater than one, makes downloads without a client fetch ranges of
+	// ChunkSize over this many connections in parallel, for high-latency sources. Files
+	// of a single chunk, and files of servers ignoring ranges or sending no validators to
+	// detect changes, are downloaded with one request. Parallel downloads are not resumed,
+	// a failed one starts over.
+	Connections int
+	// ChunkSize is the size of the ranges of parallel downloads, 8 MiB if zero
+	ChunkSize int64
+	// ResumeAttempts limits how often downloads made without a client are resumed within
+	// the call after an interruption, 3 if zero; negative disables it. The partial file is
+	// kept for the next call either way, if the server supports resuming it.
+	ResumeAttempts int
+	// DiscardPartial removes the partial file of a failed or cancelled download made
+	// without a client even if the server supports resuming it, so the next call starts over
+	DiscardPartial bool
+}
+
+// Download writes the content of a remote file to w
+func (c *Client) Download(remotePath string, w io.Writer) error {
+	return c.DownloadWithOptions(remotePath, w, DownloadOptions{})
+}
+
+// DownloadWithOptions writes the content of a remote file to w like Download, applying
+// the given options
+func (c *Client) DownloadWithOptions(remotePath string, w io.Writer, opts DownloadOptions) (err error) {
+	var written int64
+	ctx, start := c.startOp(context.Background(), OpDownload, remotePath)
+	defer func() { err = c.finishOp(OpDownload, remotePath, written, start, err) }()
+
+	if w == nil {
+		return fmt.Errorf("writer cannot be nil")
+	}
+
+	body, size, err := c.openRaw(ctx, remotePath)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if opts.Progress != nil {
+		w = NewProgressWriter(w, size, opts.Progress)
+	}
+	if written, err = io.Copy(w, body); err != nil {
+		return fmt.Errorf("failed to download %s: %w", remotePath, err)
+	}
+
+	c.log().Info("Downloaded file", "path", remotePath)
+	return nil
+}
+
+// ArchiveFormat is the format of a directory archive made by the server
+type ArchiveFormat string
+
+// Archive formats supported by Filebrowser
+const (
+	ArchiveZip    ArchiveFormat = "zip"
+	ArchiveTar    ArchiveFormat = "tar"
+	ArchiveTarGz  ArchiveFormat = "targz"
+	ArchiveTarBz2 ArchiveFormat = "tarbz2"
+	ArchiveTarXz  ArchiveFormat = "tarxz"
+)
+
+// archiveFormats are the archive formats supported by Filebrowser
+var archiveFormats = []ArchiveFormat{ArchiveZip, ArchiveTar, ArchiveTarGz, ArchiveTarBz2, ArchiveTarXz}
+
+// valid reports whether the server supports the format
+func (f ArchiveFormat) valid() bool {
+	return slices.Contains(archiveFormats, f)
+}
+
+// DownloadArchive writes an archive of the remote directory remoteDir and everything below
+// it to w, e.g. for backups. The server builds the archive while it is streamed, so a
+// failure after the start leaves a truncated archive in w.
+func (c *Client) DownloadArchive(remoteDir string, format ArchiveFormat, w io.Writer) (err error) {
+	var written int64
+	ctx, start := c.startOp(context.Background(), OpDownload, remoteDir)
+	defer func() { err = c.finishOp(OpDownload, remoteDir, written, start, err) }()
+
+	if w == nil {
+		return fmt.Errorf("writer cannot be nil")
+	}
+	if !format.valid() {
+		return fmt.Errorf("unsupported archive format %q", format)
+	}
+
+	body, _, err := c.openRawArchive(ctx, remoteDir, string(format))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if written, err = io.Copy(w, body); err != nil {
+		return fmt.Errorf("failed to download archive of %s: %w", remoteDir, err)
+	}
+
+	c.log().Info("Downloaded archive", "path", remoteDir, "format", format, "bytes", written)
+	return nil
+}
+
+// openRaw starts downloading a remote file and returns its content as a stream, which
+// the caller must close, together with its size or -1 if unknown. A missing file returns
+// an error matching ErrNotFound.
+func (c *Client) openRaw(ctx context.Context, remotePath string) (io.ReadCloser, int64, error) {
+	return c.openRawArchive(ctx, remotePath, "")
+}
+
+// openRawArchive is like openRaw, archiving directories with the given algorithm if set
+func (c *Client) openRawArchive(ctx context.Context, remotePath string, algo string) (io.ReadCloser, int64, error) {
+	if remotePath == "" {
+		return nil, 0, fmt.Errorf("remote path cannot be empty")
+	}
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return nil, 0, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	url, err := c.apiURL("raw", remotePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	watch := watchIdle(ctx, timeoutOrDefault(c.Timeouts.Idle, defaultIdleTimeout))
+	r := c.newTransferRequest(watch.ctx).DisableAutoReadResponse()
+	if algo != "" {
+		r.SetQueryParam("algo", algo)
+	}
+	resp, err := r.Get(url)
+	if err != nil {
+		watch.stop()
+		return nil, 0, fmt.Errorf("download request failed: %w", watch.err(err))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer watch.stop()
+		if err := checkDenied(resp, remotePath); err != nil {
+			return nil, 0, err
+		}
+		return nil, 0, fmt.Errorf("download request failed with %w", newAPIError(resp))
+	}
+
+	return watch.reader(resp.Body), resp.ContentLength, nil
+}
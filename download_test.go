@@ -0,0 +1,321 @@
+package filebrowser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownload(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"dir/a.txt": []byte("hello")})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	var buf bytes.Buffer
+	if err := client.Download("dir/a.txt", &buf); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if buf.String() != "hello" {
+		t.Errorf("Download() wrote %q, want hello", buf.String())
+	}
+
+	if err := client.Download("dir/missing.txt", &buf); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Download() of missing file error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDownloadArchive(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"dir/a.txt":     []byte("a"),
+		"dir/sub/b.txt": []byte("b"),
+		"other.txt":     []byte("other"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	var buf bytes.Buffer
+	if err := client.DownloadArchive("dir", ArchiveZip, &buf); err != nil {
+		t.Fatalf("DownloadArchive() error = %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("DownloadArchive() wrote no zip archive: %v", err)
+	}
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "a.txt,sub/b.txt" {
+		t.Errorf("DownloadArchive() archived %v, want a.txt and sub/b.txt", names)
+	}
+
+	if err := client.DownloadArchive("missing", ArchiveZip, io.Discard); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DownloadArchive() of missing directory error = %v, want ErrNotFound", err)
+	}
+	if err := client.DownloadArchive("dir", "rar", io.Discard); err == nil {
+		t.Error("DownloadArchive() with unsupported format succeeded, want error")
+	}
+}
+
+func TestDownloadProgress(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 100*1024)
+	server := newMemServer(t, map[string][]byte{"big.bin": content})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	var lastDone, lastTotal int64
+	err := client.DownloadWithOptions("big.bin", io.Discard, DownloadOptions{
+		Progress: func(done, total int64) { lastDone, lastTotal = done, total },
+	})
+	if err != nil {
+		t.Fatalf("DownloadWithOptions() error = %v", err)
+	}
+	if lastDone != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Errorf("final progress = %d/%d, want %d/%d", lastDone, lastTotal, len(content), len(content))
+	}
+}
+
+func TestDownloadToLocalWithOptions(t *testing.T) {
+	content := bytes.Repeat([]byte("y"), 64*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.bin" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	fileURL := server.URL + "/sdk-test-" + strconv.FormatInt(time.Now().UnixNano(), 10) + "/file.bin"
+	var lastDone int64
+	localPath, err := DownloadToLocalWithOptions(fileURL, 0, DownloadOptions{
+		Progress: func(done, total int64) { lastDone = done },
+	})
+	if err != nil {
+		t.Fatalf("DownloadToLocalWithOptions() error = %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(filepath.Dir(localPath)) })
+
+	data, err := os.ReadFile(localPath)
+	if err != nil || !bytes.Equal(data, content) {
+		t.Errorf("downloaded file does not match, error = %v", err)
+	}
+	if lastDone != int64(len(content)) {
+		t.Errorf("final progress = %d, want %d", lastDone, len(content))
+	}
+
+	if _, err := DownloadToLocal(server.URL+"/missing.bin", 0); err == nil {
+		t.Error("DownloadToLocal() of missing file should fail")
+	}
+}
+
+func TestDownloadDestination(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("content of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	opts := DownloadOptions{Dir: dir, FileNameFn: HostFileName}
+	localPath, err := DownloadToLocalWithOptions(server.URL+"/a/file.txt", 0, opts)
+	if err != nil {
+		t.Fatalf("DownloadToLocalWithOptions() error = %v", err)
+	}
+	host := strings.ReplaceAll(strings.TrimPrefix(server.URL, "http://"), ":", "_")
+	if want := filepath.Join(dir, host, "a", "file.txt"); localPath != want {
+		t.Errorf("local path = %s, want %s", localPath, want)
+	}
+
+	// An existing file of the expected size is only downloaded again with Overwrite
+	size := int64(len("content of /a/file.txt"))
+	if _, err := DownloadToLocalWithOptions(server.URL+"/a/file.txt", size, opts); err != nil || requests != 1 {
+		t.Errorf("download of existing file made %d requests, error = %v, want 1", requests, err)
+	}
+	opts.Overwrite = true
+	if _, err := DownloadToLocalWithOptions(server.URL+"/a/file.txt", size, opts); err != nil || requests != 2 {
+		t.Errorf("download with Overwrite made %d requests, error = %v, want 2", requests, err)
+	}
+
+	// Dot segments of the URL cannot leave the directory with the default naming either
+	localPath, err = DownloadToLocalWithOptions(server.URL+"/../../escape.txt", 0, DownloadOptions{Dir: dir})
+	if err != nil || localPath != filepath.Join(dir, "escape.txt") {
+		t.Errorf("DownloadToLocalWithOptions() of a .. URL = %s, %v, want %s", localPath, err, filepath.Join(dir, "escape.txt"))
+	}
+
+	opts.FileNameFn = func(string) string { return "../escape.txt" }
+	if _, err := DownloadToLocalWithOptions(server.URL+"/b.txt", 0, opts); err == nil {
+		t.Error("DownloadToLocalWithOptions() with a file name outside the directory should fail")
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10*1024)
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var headers []http.Header
+	interrupt := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		w.Header().Set("ETag", `"v1"`)
+		if interrupt > 0 && r.Header.Get("Range") == "" {
+			// Drop the connection halfway through the file
+			interrupt--
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Write(content[:len(content)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "file.bin", modified, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	download := func(opts DownloadOptions) (string, error) {
+		opts.Dir = dir
+		return DownloadToLocalWithOptions(server.URL+"/file.bin", 0, opts)
+	}
+
+	// An interrupted download is resumed within the call
+	interrupt = 1
+	localPath, err := download(DownloadOptions{})
+	if err != nil {
+		t.Fatalf("DownloadToLocalWithOptions() error = %v", err)
+	}
+	if data, _ := os.ReadFile(localPath); !bytes.Equal(data, content) {
+		t.Fatal("resumed download does not match")
+	}
+	if len(headers) != 2 || headers[1].Get("Range") != fmt.Sprintf("bytes=%d-", len(content)/2) || headers[1].Get("If-Range") != `"v1"` {
+		t.Errorf("requests = %v, want a resumed second request", headers)
+	}
+
+	// A complete file is revalidated instead of downloaded again
+	if _, err := download(DownloadOptions{}); err != nil {
+		t.Fatalf("DownloadToLocalWithOptions() again error = %v", err)
+	}
+	if len(headers) != 3 || headers[2].Get("If-None-Match") != `"v1"` {
+		t.Errorf("third request headers = %v, want a conditional request", headers[len(headers)-1])
+	}
+
+	// Without resume attempts the partial file is resumed by the next call
+	interrupt = 1
+	if _, err := download(DownloadOptions{Overwrite: true, ResumeAttempts: -1}); err == nil {
+		t.Fatal("interrupted download without resume attempts succeeded")
+	}
+	if info, err := os.Stat(localPath + partSuffix); err != nil || info.Size() != int64(len(content)/2) {
+		t.Fatalf("partial file = %v, %v, want half of the file", info, err)
+	}
+	if _, err := download(DownloadOptions{}); err != nil {
+		t.Fatalf("DownloadToLocalWithOptions() resuming error = %v", err)
+	}
+	if data, _ := os.ReadFile(localPath); !bytes.Equal(data, content) {
+		t.Error("download resumed by the next call does not match")
+	}
+	if _, err := os.Stat(localPath + partSuffix); !os.IsNotExist(err) {
+		t.Errorf("partial file left behind: %v", err)
+	}
+}
+
+func TestDownloadPartialError(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10*1024)
+	half := content[:len(content)/2]
+	var validators atomic.Bool
+	validators.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if validators.Load() {
+			w.Header().Set("ETag", `"v1"`)
+		}
+		// Drop the connection halfway through the file
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write(half)
+		w.(http.Flusher).Flush()
+		if !validators.Load() {
+			// Stall until the client gives up
+			<-r.Context().Done()
+		}
+		panic(http.ErrAbortHandler)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	partPath := filepath.Join(dir, "file.bin") + partSuffix
+	download := func(ctx context.Context, opts DownloadOptions) *PartialDownloadError {
+		t.Helper()
+		opts.Dir = dir
+		opts.ResumeAttempts = -1
+		_, err := DownloadToLocalWithOptionsContext(ctx, server.URL+"/file.bin", 0, opts)
+		var partial *PartialDownloadError
+		if !errors.As(err, &partial) || !errors.Is(err, ErrPartialDownload) {
+			t.Fatalf("error = %v, want a PartialDownloadError", err)
+		}
+		return partial
+	}
+
+	// A resumable download keeps its partial file
+	partial := download(context.Background(), DownloadOptions{})
+	if !partial.Resumable || partial.Path != partPath || partial.Written != int64(len(half)) {
+		t.Errorf("partial = %+v, want resumable from %s after %d bytes", partial, partPath, len(half))
+	}
+	if info, err := os.Stat(partPath); err != nil || info.Size() != int64(len(half)) {
+		t.Errorf("partial file = %v, %v, want half of the file", info, err)
+	}
+
+	// DiscardPartial removes it
+	partial = download(context.Background(), DownloadOptions{DiscardPartial: true})
+	if partial.Resumable || partial.Path != "" || partial.Written == 0 {
+		t.Errorf("partial = %+v, want a discarded partial download", partial)
+	}
+	if _, err := os.Stat(partPath); !os.IsNotExist(err) {
+		t.Errorf("partial file left behind: %v", err)
+	}
+
+	// A cancelled download of a file the server cannot resume is removed
+	validators.Store(false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	partial = download(ctx, DownloadOptions{Progress: func(done int64, total int64) {
+		if done > 0 {
+			cancel()
+		}
+	}})
+	if partial.Resumable || !errors.Is(partial, context.Canceled) {
+		t.Errorf("partial = %+v, want a cancelled download that cannot be resumed", partial)
+	}
+	if _, err := os.Stat(partPath); !os.IsNotExist(err) {
+		t.Errorf("partial file left behind: %v", err)
+	}
+}
+
+func TestDownloadMaxRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hop")); err == nil && n > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/hop%d", n-1), http.StatusFound)
+			return
+		}
+		w.Write([]byte("arrived"))
+	}))
+	defer server.Close()
+
+	opts := DownloadOptions{Dir: t.TempDir(), MaxRedirects: 2}
+	if _, err := DownloadToLocalWithOptions(server.URL+"/hop2", 0, opts); err != nil {
+		t.Errorf("download within the redirect limit error = %v", err)
+	}
+	if _, err := DownloadToLocalWithOptions(server.URL+"/hop3", 0, opts); err == nil {
+		t.Error("download beyond the redirect limit succeeded")
+	}
+	opts.URLPolicy = &URLPolicy{AllowPrivateNetworks: true}
+	if _, err := DownloadToLocalWithOptions(server.URL+"/hop3", 0, opts); err == nil {
+		t.Error("download beyond the redirect limit overriding the URL policy succeeded")
+	}
+}
@@ -0,0 +1,287 @@
+package filebrowser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/imroc/req/v3"
+)
+
+const (
+	// partSuffix names the partial file of an unfinished download
+	partSuffix = ".part"
+	// validatorsSuffix names the file holding the validators of a downloaded file
+	validatorsSuffix = ".validators"
+	// defaultResumeAttempts is the number of times an interrupted download is resumed
+	// within a call if no number is given
+	defaultResumeAttempts = 3
+)
+
+// downloadValidators identify the version of a downloaded file, for conditional requests
+// and resuming partial downloads
+type downloadValidators struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// responseValidators returns the validators of a response to a request for fileURL
+func responseValidators(fileURL string, resp *req.Response) downloadValidators {
+	return downloadValidators{
+		URL:          fileURL,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// ifRange returns the If-Range value for resuming a download, empty if it cannot be
+// resumed safely. Weak ETags cannot be used for ranges.
+func (v downloadValidators) ifRange() string {
+	if v.ETag != "" && !strings.HasPrefix(v.ETag, "W/") {
+		return v.ETag
+	}
+	return v.LastModified
+}
+
+// loadValidators reads the validators stored for a file of fileURL, ok is false if there
+// are none
+func loadValidators(path string, fileURL string) (v downloadValidators, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &v) != nil || v.URL != fileURL {
+		return downloadValidators{}, false
+	}
+	return v, v.ETag != "" || v.LastModified != ""
+}
+
+// storeValidators writes the validators of a file, removing stale ones if v has none
+func storeValidators(path string, v downloadValidators) error {
+	if v.ETag == "" && v.LastModified == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// downloadFile fetches fileURL to localPath with client. An interrupted download keeps
+// its partial file and resumes it with a range request, within this call up to the resume
+// attempts of opts and otherwise on the next call. An existing file is revalidated with a
+// conditional request. expectedSize is reported to progress when the server does not
+// announce the size. The download fails if it receives no data for the idle timeout or
+// exceeds the size limit of opts. With several connections the file is fetched in parallel
+// ranges if possible, see fetchChunked. A download failing after receiving data returns a
+// PartialDownloadError, see partialDownload.
+func downloadFile(ctx context.Context, client *req.Client, localPath string, fileURL string, expectedSize int64, opts DownloadOptions) (err error) {
+	attempts := opts.ResumeAttempts
+	if attempts == 0 {
+		attempts = defaultResumeAttempts
+	}
+	// Deferred so the partial file is cleaned up on every failure, including cancellation
+	defer func() {
+		if err != nil {
+			err = partialDownload(localPath+partSuffix, fileURL, err, opts)
+		}
+	}()
+	if opts.Connections > 1 {
+		if handled, err := fetchChunked(ctx, client, localPath, fileURL, opts); handled {
+			return err
+		}
+	}
+
+	log := packageLog(opts.Logger)
+	for attempt := 0; ; attempt++ {
+		resumable, err := fetchFile(ctx, client, localPath, fileURL, expectedSize, opts)
+		if err == nil || !resumable || attempt >= attempts || ctx.Err() != nil {
+			return err
+		}
+		log.Warn("Download interrupted, resuming", "url", fileURL, "attempt", attempt+1, "error", err)
+	}
+}
+
+// fetchFile makes one attempt of downloadFile and reports whether a failed attempt left
+// a partial file that can be resumed
+func fetchFile(ctx context.Context, client *req.Client, localPath string, fileURL string, expectedSize int64, opts DownloadOptions) (resumable bool, err error) {
+	partPath := localPath + partSuffix
+	watch := watchIdle(ctx, timeoutOrDefault(opts.IdleTimeout, defaultIdleTimeout))
+	r := traceTimeouts(client.R().SetContext(watch.ctx)).DisableAutoReadResponse()
+	opts.Auth.apply(r)
+
+	// Resume a partial file, or revalidate a complete one
+	var offset int64
+	partValidators, partKnown := loadValidators(partPath+validatorsSuffix, fileURL)
+	if info, err := os.Stat(partPath); err == nil && partKnown && partValidators.ifRange() != "" && info.Size() > 0 {
+		offset = info.Size()
+		r.SetHeader("Range", fmt.Sprintf("bytes=%d-", offset)).SetHeader("If-Range", partValidators.ifRange())
+	} else {
+		revalidate(r, localPath, fileURL, opts)
+	}
+
+	resp, err := r.Get(fileURL)
+	if err != nil {
+		watch.stop()
+		return offset > 0, fmt.Errorf("download request failed: %w", watch.err(err))
+	}
+	body := watch.reader(resp.Body)
+	defer body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		packageLog(opts.Logger).Info("File not modified, skipping download", "path", localPath)
+		return false, nil
+	case http.StatusPartialContent:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			discardPart(partPath)
+			return true, fmt.Errorf("server resumed at an unexpected offset: %s", resp.Header.Get("Content-Range"))
+		}
+		if offset == 0 {
+			partValidators = responseValidators(fileURL, resp)
+		}
+	case http.StatusOK:
+		offset = 0
+		partValidators = responseValidators(fileURL, resp)
+	default:
+		return false, fmt.Errorf("download failed with status code: %d", resp.StatusCode)
+	}
+
+	// Refuse announced oversized files before writing anything, and stop the others once
+	// they exceed the limit
+	var source io.Reader = body
+	if opts.MaxBytes > 0 {
+		if resp.ContentLength >= 0 && offset+resp.ContentLength > opts.MaxBytes {
+			discardPart(partPath)
+			return false, &DownloadTooLargeError{URL: fileURL, Limit: opts.MaxBytes, Size: offset + resp.ContentLength}
+		}
+		source = &limitedReader{r: body, remaining: opts.MaxBytes - offset, err: &DownloadTooLargeError{URL: fileURL, Limit: opts.MaxBytes}}
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	part, err := os.OpenFile(partPath, flags, 0o644)
+	if err != nil {
+		return false, fmt.Errorf("failed to open partial file: %w", err)
+	}
+	if offset == 0 {
+		if err := storeValidators(partPath+validatorsSuffix, partValidators); err != nil {
+			part.Close()
+			discardPart(partPath)
+			return false, fmt.Errorf("failed to store download validators: %w", err)
+		}
+	}
+
+	var w io.Writer = part
+	if opts.Progress != nil {
+		total := resp.ContentLength
+		if total >= 0 {
+			total += offset
+		} else if expectedSize > 0 {
+			total = expectedSize
+		}
+		w = NewProgressWriter(part, total, func(done int64, total int64) { opts.Progress(offset+done, total) })
+	}
+	written, err := io.Copy(w, source)
+	if closeErr := part.Close(); err == nil {
+		err = closeErr
+	}
+	var tooLarge *DownloadTooLargeError
+	if errors.As(err, &tooLarge) {
+		discardPart(partPath)
+		return false, err
+	}
+	if err != nil {
+		// What was received is kept by partialDownload if the server can resume it
+		return offset+written > 0 && partValidators.ifRange() != "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := os.Rename(partPath, localPath); err != nil {
+		return false, err
+	}
+	if err := storeValidators(localPath+validatorsSuffix, partValidators); err != nil {
+		return false, fmt.Errorf("failed to store download validators: %w", err)
+	}
+	os.Remove(partPath + validatorsSuffix)
+	return false, nil
+}
+
+// partialDownload cleans up after the download of fileURL failed with err. The partial
+// file is kept if the server can resume it and opts allow it, and removed otherwise. If
+// data was received, err is returned as a PartialDownloadError.
+func partialDownload(partPath string, fileURL string, err error, opts DownloadOptions) error {
+	var partial *PartialDownloadError
+	if errors.As(err, &partial) {
+		return err
+	}
+
+	info, statErr := os.Stat(partPath)
+	if statErr != nil {
+		discardPart(partPath)
+		return err
+	}
+	validators, known := loadValidators(partPath+validatorsSuffix, fileURL)
+	resumable := known && validators.ifRange() != "" && info.Size() > 0 && !opts.DiscardPartial
+	if !resumable {
+		discardPart(partPath)
+		if info.Size() == 0 {
+			return err
+		}
+		return &PartialDownloadError{URL: fileURL, Written: info.Size(), Err: err}
+	}
+	return &PartialDownloadError{URL: fileURL, Path: partPath, Written: info.Size(), Resumable: true, Err: err}
+}
+
+// discardPart removes a partial file and its validators
+func discardPart(partPath string) {
+	os.Remove(partPath)
+	os.Remove(partPath + validatorsSuffix)
+}
+
+// contentRangeStart returns the first byte position of a Content-Range header such as
+// "bytes 100-199/200"
+func contentRangeStart(contentRange string) (int64, bool) {
+	rest, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	first, _, ok := strings.Cut(rest, "-")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	return start, err == nil
+}
+
+// limitedReader reads at most remaining bytes from r and fails with err if r has more
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	err       error
+}
+
+// Read implements io.Reader
+func (l *limitedReader) Read(b []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.err
+	}
+	// Read one byte past the limit to tell a file of exactly the limit from a larger one
+	if int64(len(b)) > l.remaining+1 {
+		b = b[:l.remaining+1]
+	}
+	n, err := l.r.Read(b)
+	if l.remaining -= int64(n); l.remaining < 0 {
+		return n + int(l.remaining), l.err
+	}
+	return n, err
+}
@@ -0,0 +1,103 @@
+package filebrowser
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Plan describes what a SaveAndShare run would do, as reported by a dry run
+type Plan struct {
+	ExternalURL string
+	// DownloadBytes is the size of the external file, -1 if the source does not report it
+	DownloadBytes int64
+	RemotePath    string
+	// Existing is the remote file found at RemotePath, nil if there is none
+	Existing *RespResource
+	// Upload is set if the file would be uploaded, Overwrite if it would replace Existing
+	Upload    bool
+	Overwrite bool
+	// ReusedShare is the existing share that would be returned, nil if one would be created
+	ReusedShare *ShareLink
+	// ShareExpires is the expiry of the share that would be created, zero if it would
+	// never expire
+	ShareExpires           time.Time
+	SharePasswordProtected bool
+}
+
+// Actions describes the planned actions in order, e.g. to review a batch before it runs
+func (p *Plan) Actions() []string {
+	var actions []string
+	if p.DownloadBytes >= 0 {
+		actions = append(actions, fmt.Sprintf("download %d bytes from %s", p.DownloadBytes, p.ExternalURL))
+	} else {
+		actions = append(actions, fmt.Sprintf("download %s of unknown size", p.ExternalURL))
+	}
+
+	switch {
+	case p.Overwrite:
+		actions = append(actions, fmt.Sprintf("overwrite %s (%d bytes)", p.RemotePath, p.Existing.Size))
+	case p.Upload:
+		actions = append(actions, fmt.Sprintf("upload to %s", p.RemotePath))
+	default:
+		actions = append(actions, fmt.Sprintf("keep existing %s", p.RemotePath))
+	}
+
+	if p.ReusedShare != nil {
+		return append(actions, fmt.Sprintf("reuse share %s of %s", p.ReusedShare.Hash, p.RemotePath))
+	}
+	share := fmt.Sprintf("create share of %s", p.RemotePath)
+	if p.ShareExpires.IsZero() {
+		share += " never expiring"
+	} else {
+		share += " expiring " + p.ShareExpires.Format(time.RFC3339)
+	}
+	if p.SharePasswordProtected {
+		share += " with password"
+	}
+	return append(actions, share)
+}
+
+// planRun makes the decisions of the default stages for a dry run without changing
+// anything: the external URL is requested for its size only, and the remote file and its
+// shares are looked up
+func planRun(state *PipelineState) (*Plan, error) {
+	opts := state.downloadOptions()
+	opts.Progress = nil
+	body, size, err := openSource(state.Context, state.ExternalURL, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check external URL: %w", err)
+	}
+	body.Close()
+
+	plan := &Plan{
+		ExternalURL:   state.ExternalURL,
+		DownloadBytes: size,
+		RemotePath:    state.RemotePathFn(filepath.Base(urlFileName(state.ExternalURL))),
+	}
+	if plan.RemotePath == "" {
+		return nil, fmt.Errorf("remote path cannot be empty")
+	}
+
+	resource, err := findResource(state.Context, state.API, plan.RemotePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource info: %w", err)
+	}
+	plan.Existing = resource
+	plan.Upload = state.Params.uploadNeeded(resource)
+	plan.Overwrite = plan.Upload && resource != nil
+
+	now := time.Now()
+	share, err := state.Params.ShareParams.resolve(now)
+	if err != nil {
+		return nil, err
+	}
+	if state.Params.ReuseShare && resource != nil {
+		if plan.ReusedShare, err = reusableShare(state.API, plan.RemotePath, share, now); err != nil {
+			return nil, fmt.Errorf("failed to list shares: %w", err)
+		}
+	}
+	plan.ShareExpires = shareExpiry(now, share.Expires, share.Unit)
+	plan.SharePasswordProtected = share.Expires > 0 && share.Password != ""
+	return plan, nil
+}
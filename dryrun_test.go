@@ -0,0 +1,69 @@
+package filebrowser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDryRun(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("new content"))
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, map[string][]byte{"in/old.txt": []byte("old")})
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	remotePathFn := func(name string) string { return "in/" + name }
+	params := ActionParams{
+		URLPolicy:   localOrigin,
+		FileSize:    11,
+		DryRun:      true,
+		ShareParams: ShareParams{Expiry: ExpireAfter(time.Hour), Password: "secret-pass"},
+	}
+
+	result, err := SaveAndShare(auth, origin.URL+"/old.txt", remotePathFn, params)
+	if err != nil {
+		t.Fatalf("SaveAndShare() dry run error = %v", err)
+	}
+	plan := result.Plan
+	if plan == nil || result.ViewUrl != "" {
+		t.Fatalf("result = %+v, want only a plan", result)
+	}
+	if plan.DownloadBytes != 11 || !plan.Upload || !plan.Overwrite || plan.Existing.Size != 3 || !plan.SharePasswordProtected {
+		t.Errorf("plan = %+v, want overwriting the 3 byte file with 11 bytes", plan)
+	}
+	actions := plan.Actions()
+	if len(actions) != 3 || !strings.HasPrefix(actions[0], "download 11 bytes") ||
+		actions[1] != "overwrite in/old.txt (3 bytes)" || !strings.HasSuffix(actions[2], "with password") {
+		t.Errorf("actions = %q", actions)
+	}
+
+	// Nothing was downloaded, uploaded or shared
+	if content, _ := server.file("in/old.txt"); string(content) != "old" || len(server.shares) > 0 {
+		t.Errorf("remote file = %q with %d shares, want it untouched", content, len(server.shares))
+	}
+	if _, err := os.Stat(LocalPathForDownload(origin.URL + "/old.txt")); !os.IsNotExist(err) {
+		t.Errorf("dry run downloaded the file: %v", err)
+	}
+
+	// A new file is uploaded, an existing one of the expected size kept
+	params.ShareParams = ShareParams{}
+	result, err = SaveAndShare(auth, origin.URL+"/new.txt", remotePathFn, params)
+	if err != nil {
+		t.Fatalf("SaveAndShare() dry run of new file error = %v", err)
+	}
+	if want := []string{"download 11 bytes from " + origin.URL + "/new.txt", "upload to in/new.txt", "create share of in/new.txt never expiring"}; !reflect.DeepEqual(result.Plan.Actions(), want) {
+		t.Errorf("actions = %q, want %q", result.Plan.Actions(), want)
+	}
+	params.FileSize = 3
+	if result, err = SaveAndShare(auth, origin.URL+"/old.txt", remotePathFn, params); err != nil || result.Plan.Upload {
+		t.Errorf("dry run with matching size = %+v, %v, want the file kept", result.Plan, err)
+	}
+}
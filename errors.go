@@ -0,0 +1,225 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/eventials/go-tus"
+	"github.com/imroc/req/v3"
+)
+
+// maxAPIErrorBody is the number of response body bytes kept in an APIError
+const maxAPIErrorBody = 512
+
+// ErrNotFound is returned when a remote resource does not exist
+var ErrNotFound = errors.New("resource not found")
+
+// ErrAlreadyExists is returned when a destination resource exists and may not be replaced
+var ErrAlreadyExists = errors.New("resource already exists")
+
+// ErrUnauthorized is matched by errors.Is for requests rejected because the credentials or
+// the token are invalid
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrConflict is matched by errors.Is for requests conflicting with the state of a resource
+var ErrConflict = errors.New("conflict")
+
+// APIError reports a request the server answered with an unexpected status code. It
+// matches ErrNotFound (404), ErrUnauthorized (401) and ErrConflict (409) with errors.Is.
+type APIError struct {
+	StatusCode int
+	Body       string // Start of the response body, if any
+
+	err error // TUS client error reporting the status code, if the request was a TUS one
+}
+
+// newAPIError creates an APIError for an unexpected response
+func newAPIError(resp *req.Response) *APIError {
+	return &APIError{StatusCode: resp.StatusCode, Body: truncateBody(resp.String())}
+}
+
+// httpAPIError creates an APIError for an unexpected response of the TUS HTTP client
+func httpAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxAPIErrorBody+1))
+	return &APIError{StatusCode: resp.StatusCode, Body: truncateBody(string(body))}
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	if e.Body == "" {
+		return fmt.Sprintf("status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("status code: %d: %s", e.StatusCode, e.Body)
+}
+
+// Unwrap returns the TUS client error, if any
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether target is the sentinel error of the status code
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// truncateBody trims a response body to the length kept in an APIError
+func truncateBody(body string) string {
+	body = strings.TrimSpace(body)
+	if len(body) > maxAPIErrorBody {
+		return body[:maxAPIErrorBody] + "..."
+	}
+	return body
+}
+
+// ErrDownloadTooLarge is matched by errors.Is for every DownloadTooLargeError
+var ErrDownloadTooLarge = errors.New("download too large")
+
+// DownloadTooLargeError reports a download aborted because it exceeds the size limit
+type DownloadTooLargeError struct {
+	URL   string
+	Limit int64
+	Size  int64 // Announced size, zero if the download exceeded the limit while streaming
+}
+
+// Error implements the error interface
+func (e *DownloadTooLargeError) Error() string {
+	if e.Size > 0 {
+		return fmt.Sprintf("download of %s is %d bytes, more than the limit of %d bytes", e.URL, e.Size, e.Limit)
+	}
+	return fmt.Sprintf("download of %s exceeds the limit of %d bytes", e.URL, e.Limit)
+}
+
+// Is reports whether target is ErrDownloadTooLarge
+func (e *DownloadTooLargeError) Is(target error) bool {
+	return target == ErrDownloadTooLarge
+}
+
+// ErrPartialDownload is matched by errors.Is for every PartialDownloadError
+var ErrPartialDownload = errors.New("partial download")
+
+// PartialDownloadError reports a download that failed or was cancelled after receiving
+// data. A resumable download keeps its partial file at Path, and the next download of the
+// URL to the same file continues it; otherwise the partial file was removed and the
+// download starts over.
+type PartialDownloadError struct {
+	URL       string
+	Path      string // Partial file kept for resuming, empty if it was removed
+	Written   int64  // Bytes of the file received, including those of resumed attempts
+	Resumable bool
+	Err       error
+}
+
+// Error implements the error interface
+func (e *PartialDownloadError) Error() string {
+	if e.Resumable {
+		return fmt.Sprintf("download of %s interrupted after %d bytes, resumable from %s: %v", e.URL, e.Written, e.Path, e.Err)
+	}
+	return fmt.Sprintf("download of %s failed after %d bytes: %v", e.URL, e.Written, e.Err)
+}
+
+// Unwrap returns the error that interrupted the download
+func (e *PartialDownloadError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrPartialDownload
+func (e *PartialDownloadError) Is(target error) bool {
+	return target == ErrPartialDownload
+}
+
+// ErrContentMismatch is matched by errors.Is for every ContentMismatchError
+var ErrContentMismatch = errors.New("remote content differs")
+
+// ContentMismatchError reports an existing remote file that strict mode refused to keep
+// because its content differs from the local file
+type ContentMismatchError struct {
+	RemotePath   string
+	LocalPath    string
+	LocalSize    int64
+	RemoteSize   int64
+	LocalSHA256  string // Empty if the sizes already differ
+	RemoteSHA256 string // Empty if the sizes already differ
+}
+
+// Error implements the error interface
+func (e *ContentMismatchError) Error() string {
+	if e.LocalSize != e.RemoteSize {
+		return fmt.Sprintf("content of %s differs from %s: remote size %d, local size %d",
+			e.RemotePath, e.LocalPath, e.RemoteSize, e.LocalSize)
+	}
+	return fmt.Sprintf("content of %s differs from %s: remote sha256 %s, local sha256 %s (size %d)",
+		e.RemotePath, e.LocalPath, e.RemoteSHA256, e.LocalSHA256, e.LocalSize)
+}
+
+// Is reports whether target is ErrContentMismatch
+func (e *ContentMismatchError) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+// ErrDeniedByRule is matched by errors.Is for every DeniedByRuleError
+var ErrDeniedByRule = errors.New("access denied by rule")
+
+// DeniedByRuleError reports a request refused because a server rule (e.g. a hidden path
+// pattern) denies the user access to the path. Invalid credentials are reported separately.
+type DeniedByRuleError struct {
+	Path string
+}
+
+// Error implements the error interface
+func (e *DeniedByRuleError) Error() string {
+	return fmt.Sprintf("access to %s denied by server rules", e.Path)
+}
+
+// Is reports whether target is ErrDeniedByRule
+func (e *DeniedByRuleError) Is(target error) bool {
+	return target == ErrDeniedByRule
+}
+
+// deniedByRuleBody is the payload of Filebrowser's 403 responses for paths its rules deny
+const deniedByRuleBody = "403 Forbidden"
+
+// isDeniedByRule reports whether a response with the given status code and body is
+// Filebrowser denying access by rule. Other 403 responses, e.g. of a proxy in front of the
+// server, are not.
+func isDeniedByRule(statusCode int, body string) bool {
+	return statusCode == http.StatusForbidden && strings.TrimSpace(body) == deniedByRuleBody
+}
+
+// checkDenied returns a DeniedByRuleError if resp reports that server rules deny access to
+// remotePath. Other responses, including other 403 responses, are left to the caller to
+// report as an APIError.
+func checkDenied(resp *req.Response, remotePath string) error {
+	if resp.StatusCode != http.StatusForbidden || !isDeniedByRule(resp.StatusCode, resp.String()) {
+		return nil
+	}
+	return &DeniedByRuleError{Path: remotePath}
+}
+
+// tusError converts an error of the TUS client into a DeniedByRuleError if server rules
+// deny access to remotePath, and into an APIError for other status codes. Both wrap the
+// original error.
+func tusError(err error, remotePath string) error {
+	var clientErr tus.ClientError
+	var apiErr *APIError
+	if !errors.As(err, &clientErr) || errors.As(err, &apiErr) {
+		return err
+	}
+	if isDeniedByRule(clientErr.Code, string(clientErr.Body)) {
+		return fmt.Errorf("%w: %w", &DeniedByRuleError{Path: remotePath}, err)
+	}
+	return &APIError{StatusCode: clientErr.Code, Body: truncateBody(string(clientErr.Body)), err: err}
+}
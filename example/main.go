@@ -4,8 +4,9 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"time"
 
-	"github.com/kiuber/filebrowser-sdk"
+	"github.com/kiuber/filebrowser-sdk/v2"
 )
 
 func main() {
@@ -21,9 +22,8 @@ func main() {
 		FileSize: 0, // Set to actual file size if known, 0 to skip size checking
 		Force:    false,
 		ShareParams: filebrowser.ShareParams{
-			Expires:  24, // 24 hours
-			Password: "",  // No password protection
-			Unit:     "hours",
+			Expiry:   filebrowser.ExpireAfter(24 * time.Hour),
+			Password: "", // No password protection
 		},
 	}
 
@@ -0,0 +1,158 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidExpiry is returned for share expirations that are in the past or use an
+// unknown unit, instead of creating a share expiring unexpectedly
+var ErrInvalidExpiry = errors.New("invalid share expiry")
+
+// shareUnits are the units of share expirations accepted by Filebrowser, largest first
+var shareUnits = []struct {
+	name string
+	step time.Duration
+}{
+	{"days", 24 * time.Hour},
+	{"hours", time.Hour},
+	{"minutes", time.Minute},
+	{"seconds", time.Second},
+}
+
+// ShareExpiry is when a share expires: after a duration, at a point in time, or never for
+// the zero value
+type ShareExpiry struct {
+	after time.Duration
+	at    time.Time
+}
+
+// ExpireAfter returns the expiry of a share valid for d from its creation
+func ExpireAfter(d time.Duration) ShareExpiry {
+	return ShareExpiry{after: d}
+}
+
+// ExpireAt returns the expiry of a share valid until t
+func ExpireAt(t time.Time) ShareExpiry {
+	return ShareExpiry{at: t}
+}
+
+// IsZero reports whether the share never expires
+func (e ShareExpiry) IsZero() bool {
+	return e.after == 0 && e.at.IsZero()
+}
+
+// String implements fmt.Stringer
+func (e ShareExpiry) String() string {
+	switch {
+	case !e.at.IsZero():
+		return "at " + e.at.Format(time.RFC3339)
+	case e.after != 0:
+		return "after " + e.after.String()
+	default:
+		return "never"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, in the format of String
+func (e ShareExpiry) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the format of String
+func (e *ShareExpiry) UnmarshalText(text []byte) error {
+	value := string(text)
+	switch {
+	case value == "never" || value == "":
+		*e = ShareExpiry{}
+	case strings.HasPrefix(value, "after "):
+		d, err := time.ParseDuration(strings.TrimPrefix(value, "after "))
+		if err != nil {
+			return fmt.Errorf("invalid share expiry %q: %w", value, err)
+		}
+		*e = ExpireAfter(d)
+	case strings.HasPrefix(value, "at "):
+		t, err := time.Parse(time.RFC3339, strings.TrimPrefix(value, "at "))
+		if err != nil {
+			return fmt.Errorf("invalid share expiry %q: %w", value, err)
+		}
+		*e = ExpireAt(t)
+	default:
+		return fmt.Errorf("invalid share expiry %q: %w", value, ErrInvalidExpiry)
+	}
+	return nil
+}
+
+// request converts the expiry into the expiration and unit Filebrowser expects for a share
+// created at now, using the largest unit that represents it exactly. Durations are rounded
+// up to whole seconds.
+func (e ShareExpiry) request(now time.Time) (int64, string, error) {
+	if e.IsZero() {
+		return 0, "", nil
+	}
+	d := e.after
+	if !e.at.IsZero() {
+		d = e.at.Sub(now)
+	}
+	if d <= 0 {
+		return 0, "", fmt.Errorf("share expiry %s is not in the future: %w", e, ErrInvalidExpiry)
+	}
+
+	seconds := int64((d + time.Second - 1) / time.Second)
+	for _, unit := range shareUnits {
+		if step := int64(unit.step / time.Second); seconds%step == 0 {
+			return seconds / step, unit.name, nil
+		}
+	}
+	return seconds, "seconds", nil
+}
+
+// validShareUnit reports whether Filebrowser understands the unit of a share expiration.
+// An empty unit means hours.
+func validShareUnit(unit string) bool {
+	if unit == "" {
+		return true
+	}
+	for _, u := range shareUnits {
+		if u.name == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// expiry returns the expiry of the share parameters, converting the deprecated Expires
+// and Unit
+func (p ShareParams) expiry() ShareExpiry {
+	if p.Expires <= 0 {
+		return p.Expiry
+	}
+	unit := time.Hour
+	for _, u := range shareUnits {
+		if u.name == p.Unit {
+			unit = u.step
+		}
+	}
+	return ExpireAfter(time.Duration(p.Expires) * unit)
+}
+
+// resolve returns the share parameters with Expiry converted into Expires and Unit for a
+// share created at now, failing with ErrInvalidExpiry for invalid expirations
+func (p ShareParams) resolve(now time.Time) (ShareParams, error) {
+	if !p.Expiry.IsZero() {
+		if p.Expires != 0 || p.Unit != "" {
+			return p, fmt.Errorf("share expiry and expires cannot both be set: %w", ErrInvalidExpiry)
+		}
+		expires, unit, err := p.Expiry.request(now)
+		if err != nil {
+			return p, err
+		}
+		p.Expires, p.Unit = expires, unit
+	}
+	if p.Expires > 0 && !validShareUnit(p.Unit) {
+		return p, fmt.Errorf("unknown share expiry unit %q: %w", p.Unit, ErrInvalidExpiry)
+	}
+	return p, nil
+}
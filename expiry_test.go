@@ -0,0 +1,93 @@
+package filebrowser
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShareExpiryRequest(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expiry  ShareExpiry
+		expires int64
+		unit    string
+	}{
+		{ShareExpiry{}, 0, ""},
+		{ExpireAfter(48 * time.Hour), 2, "days"},
+		{ExpireAfter(36 * time.Hour), 36, "hours"},
+		{ExpireAfter(90 * time.Minute), 90, "minutes"},
+		{ExpireAfter(1500 * time.Millisecond), 2, "seconds"},
+		{ExpireAt(now.Add(3 * time.Hour)), 3, "hours"},
+	}
+	for _, tt := range tests {
+		expires, unit, err := tt.expiry.request(now)
+		if err != nil || expires != tt.expires || unit != tt.unit {
+			t.Errorf("%v.request() = %d, %q, %v, want %d, %q", tt.expiry, expires, unit, err, tt.expires, tt.unit)
+		}
+	}
+
+	for _, expiry := range []ShareExpiry{ExpireAfter(-time.Hour), ExpireAt(now.Add(-time.Minute)), ExpireAt(now)} {
+		if _, _, err := expiry.request(now); !errors.Is(err, ErrInvalidExpiry) {
+			t.Errorf("%v.request() error = %v, want ErrInvalidExpiry", expiry, err)
+		}
+	}
+}
+
+func TestShareWithExpiry(t *testing.T) {
+	var body ReqShare
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/login":
+			w.Write([]byte("test-token"))
+		case strings.HasPrefix(r.URL.Path, "/api/share/"):
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(RespShare{Hash: "abc"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	if _, err := client.ShareWithExpiry("a.txt", ExpireAfter(7*24*time.Hour), "secret"); err != nil {
+		t.Fatalf("ShareWithExpiry() error = %v", err)
+	}
+	if body.Expires != "7" || body.Unit != "days" || body.Password != "secret" {
+		t.Errorf("share request = %+v, want 7 days with password", body)
+	}
+
+	// Typos in the unit no longer reach the server
+	if _, err := client.Share("a.txt", 24, "", "hour"); !errors.Is(err, ErrInvalidExpiry) {
+		t.Errorf("Share() with unknown unit error = %v, want ErrInvalidExpiry", err)
+	}
+	params := ShareParams{Expiry: ExpireAfter(time.Hour), Expires: 1, Unit: "hours"}
+	if _, err := params.resolve(time.Now()); !errors.Is(err, ErrInvalidExpiry) {
+		t.Errorf("resolve() with Expiry and Expires error = %v, want ErrInvalidExpiry", err)
+	}
+}
+
+func TestSaveAndShareInvalidExpiry(t *testing.T) {
+	var downloads int
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		w.Write([]byte("report"))
+	}))
+	defer origin.Close()
+	server := newMemServer(t, nil)
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	// The expiry is refused before the file is downloaded or uploaded
+	params := ActionParams{URLPolicy: localOrigin, ShareParams: ShareParams{Expires: 24, Unit: "hour"}}
+	remotePathFn := func(string) string { return "reports/report.txt" }
+	if _, err := SaveAndShareWithAPI(client, origin.URL+"/report.txt", remotePathFn, params); !errors.Is(err, ErrInvalidExpiry) {
+		t.Fatalf("SaveAndShareWithAPI() error = %v, want ErrInvalidExpiry", err)
+	}
+	if downloads != 0 || server.exists("reports/report.txt") {
+		t.Errorf("downloaded %d times and uploaded %v, want neither", downloads, server.exists("reports/report.txt"))
+	}
+}
@@ -0,0 +1,351 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// ErrPlanStale is returned by Apply for an operation whose remote file changed since the
+// plan was made
+var ErrPlanStale = errors.New("remote file changed since the plan was made")
+
+// Actions of planned operations
+const (
+	ActionUpload       = "upload"         // Upload LocalPath to RemotePath
+	ActionDelete       = "delete"         // Delete RemotePath
+	ActionSaveAndShare = "save_and_share" // Save ExternalURL to RemotePath and share it
+	ActionShare        = "share"          // Share the existing RemotePath
+)
+
+// PlannedOp is an operation of a ChangePlan
+type PlannedOp struct {
+	Action      string `json:"action"`
+	RemotePath  string `json:"remote_path"`
+	LocalPath   string `json:"local_path,omitempty"`
+	ExternalURL string `json:"external_url,omitempty"`
+	// Size is the number of bytes uploaded or deleted, -1 if the source does not report it
+	Size   int64  `json:"size"`
+	Reason string `json:"reason,omitempty"`
+	// Existing is the size of the remote file when the plan was made, nil if it was missing.
+	// Apply fails with ErrPlanStale if the file appeared, vanished or changed size since.
+	Existing *int64 `json:"existing,omitempty"`
+
+	// Share parameters of save_and_share and share operations
+	ShareExpiry ShareExpiry `json:"share_expiry,omitzero"`
+	ReuseShare  bool        `json:"reuse_share,omitempty"`
+	// SharePassword protects the share. It is not serialized, so plans read back from JSON
+	// need it set again before they are applied, as recorded by SharePasswordProtected.
+	SharePassword          string `json:"-"`
+	SharePasswordProtected bool   `json:"share_password_protected,omitempty"`
+}
+
+// String describes the operation, e.g. for change tickets
+func (op PlannedOp) String() string {
+	var s string
+	switch op.Action {
+	case ActionUpload:
+		s = fmt.Sprintf("upload %s to %s", op.LocalPath, op.RemotePath)
+	case ActionDelete:
+		s = fmt.Sprintf("delete %s", op.RemotePath)
+	case ActionSaveAndShare:
+		s = fmt.Sprintf("save %s to %s and share it", op.ExternalURL, op.RemotePath)
+	case ActionShare:
+		s = fmt.Sprintf("share %s", op.RemotePath)
+	default:
+		s = fmt.Sprintf("%s %s", op.Action, op.RemotePath)
+	}
+	if op.Existing != nil && (op.Action == ActionUpload || op.Action == ActionSaveAndShare) {
+		s += fmt.Sprintf(" replacing %d bytes", *op.Existing)
+	}
+	if op.Reason != "" {
+		s += " (" + op.Reason + ")"
+	}
+	return s
+}
+
+// unchanged reports whether resource, the remote file or nil, is still the one the
+// operation was planned for
+func (op PlannedOp) unchanged(resource *RespResource) bool {
+	if resource == nil || op.Existing == nil {
+		return resource == nil && op.Existing == nil
+	}
+	return resource.Size == *op.Existing
+}
+
+// ChangePlan lists the operations of a bulk change in order, as planned by the Explain
+// functions without changing anything. It can be reviewed, stored as JSON, e.g. in a
+// change ticket, and executed later with Client.Apply.
+type ChangePlan struct {
+	CreatedAt  time.Time   `json:"created_at"`
+	Operations []PlannedOp `json:"operations"`
+}
+
+// existingSize returns the Existing size of an operation for a remote file of size, -1
+// for a missing one
+func existingSize(size int64) *int64 {
+	if size < 0 {
+		return nil
+	}
+	return &size
+}
+
+// ExplainSync plans the uploads making remoteDir match localDir, as analyzed by
+// AnalyzeSyncWithOptions
+func (c *Client) ExplainSync(localDir string, remoteDir string, opts SyncOptions) (*ChangePlan, error) {
+	report, err := c.AnalyzeSyncWithOptions(localDir, remoteDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ChangePlan{CreatedAt: time.Now(), Operations: []PlannedOp{}}
+	for _, entry := range report.Entries {
+		if !entry.Transfer {
+			continue
+		}
+		plan.Operations = append(plan.Operations, PlannedOp{
+			Action:     ActionUpload,
+			RemotePath: entry.RemotePath,
+			LocalPath:  entry.LocalPath,
+			Size:       entry.Size,
+			Reason:     entry.Reason,
+			Existing:   existingSize(entry.RemoteSize),
+		})
+	}
+	return plan, nil
+}
+
+// ExplainReconcile plans the changes making remoteDir mirror localDir: the uploads of
+// ExplainSync followed by the deletions of remote files without a local counterpart.
+// Remote files excluded by opts.Filter are kept.
+func (c *Client) ExplainReconcile(localDir string, remoteDir string, opts SyncOptions) (_ *ChangePlan, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if err := c.checkDestructive(remoteDir); err != nil {
+		return nil, err
+	}
+	plan, err := c.ExplainSync(localDir, remoteDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	local := map[string]bool{}
+	err = filepath.WalkDir(localDir, func(localPath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, localPath)
+		if err != nil {
+			return err
+		}
+		local[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", localDir, err)
+	}
+
+	_, err = c.WalkWithOptions(remoteDir, func(resource *RespResource) error {
+		if resource.IsDir || local[relativePath(remoteDir, resource.Path)] {
+			return nil
+		}
+		plan.Operations = append(plan.Operations, PlannedOp{
+			Action:     ActionDelete,
+			RemotePath: resourcePath(resource.Path),
+			Size:       resource.Size,
+			Reason:     "missing locally",
+			Existing:   existingSize(resource.Size),
+		})
+		return nil
+	}, WalkOptions{Filter: opts.Filter})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ExplainQuota plans the deletions of EnforceQuota with QuotaDeleteOldest, pruning the
+// least recently modified files below root until they fit maxBytes
+func (c *Client) ExplainQuota(root string, maxBytes int64) (*ChangePlan, error) {
+	return c.ExplainQuotaWithOptions(root, maxBytes, QuotaOptions{})
+}
+
+// ExplainQuotaWithOptions plans the deletions of EnforceQuotaWithOptions with
+// QuotaDeleteOldest and the given options
+func (c *Client) ExplainQuotaWithOptions(root string, maxBytes int64, opts QuotaOptions) (_ *ChangePlan, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("quota cannot be negative")
+	}
+	if err := c.checkDestructive(root); err != nil {
+		return nil, err
+	}
+
+	files, usage, err := c.quotaUsage(root, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ChangePlan{CreatedAt: time.Now(), Operations: []PlannedOp{}}
+	for _, file := range oldestOverQuota(files, usage, maxBytes) {
+		plan.Operations = append(plan.Operations, PlannedOp{
+			Action:     ActionDelete,
+			RemotePath: resourcePath(file.Path),
+			Size:       file.Size,
+			Reason:     fmt.Sprintf("modified %s, over quota of %d bytes", file.Modified.Format(time.RFC3339), maxBytes),
+			Existing:   existingSize(file.Size),
+		})
+	}
+	return plan, nil
+}
+
+// ExplainSaveAndShare plans a SaveAndShare run with a dry run, see ActionParams.DryRun.
+// The plan has a single operation: save_and_share, or share if the existing remote file
+// is kept.
+func ExplainSaveAndShare(auth FilebrowserAuth, externalURL string, remotePathFn func(string) string, actionParams ActionParams) (*ChangePlan, error) {
+	actionParams.DryRun = true
+	result, err := SaveAndShare(auth, externalURL, remotePathFn, actionParams)
+	if err != nil {
+		return nil, err
+	}
+
+	dryRun := result.Plan
+	op := PlannedOp{
+		Action:                 ActionSaveAndShare,
+		RemotePath:             dryRun.RemotePath,
+		ExternalURL:            dryRun.ExternalURL,
+		Size:                   dryRun.DownloadBytes,
+		ShareExpiry:            actionParams.ShareParams.expiry(),
+		ReuseShare:             actionParams.ReuseShare,
+		SharePasswordProtected: dryRun.SharePasswordProtected,
+	}
+	if op.SharePasswordProtected {
+		op.SharePassword = actionParams.ShareParams.Password
+	}
+	if dryRun.Existing != nil {
+		op.Existing = existingSize(dryRun.Existing.Size)
+	}
+	if !dryRun.Upload {
+		op.Action, op.Size, op.Reason = ActionShare, 0, "existing file kept"
+	}
+	if dryRun.ReusedShare != nil {
+		op.Reason = fmt.Sprintf("share %s reused", dryRun.ReusedShare.Hash)
+	}
+	return &ChangePlan{CreatedAt: time.Now(), Operations: []PlannedOp{op}}, nil
+}
+
+// AppliedOp is the outcome of an operation executed by Apply
+type AppliedOp struct {
+	PlannedOp
+	Share *ShareResult // Share of save_and_share and share operations
+	Err   error
+}
+
+// Apply executes the operations of a plan in order, e.g. once the change explained by it
+// was approved. Every operation first checks that its remote file did not change since
+// the plan was made, failing with ErrPlanStale otherwise. Apply stops at the first failed
+// operation, returning the outcomes of the operations it ran. actionParams configures
+// the downloads of save_and_share operations, e.g. URLPolicy; their share parameters are
+// taken from the plan.
+func (c *Client) Apply(plan *ChangePlan, actionParams ActionParams) ([]AppliedOp, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("plan cannot be nil")
+	}
+
+	var applied []AppliedOp
+	for _, op := range plan.Operations {
+		share, err := c.applyOp(op, actionParams)
+		applied = append(applied, AppliedOp{PlannedOp: op, Share: share, Err: c.redactError(err)})
+		if err != nil {
+			return applied, c.redactError(fmt.Errorf("failed to %s: %w", op, err))
+		}
+	}
+	return applied, nil
+}
+
+// applyOp executes a single planned operation
+func (c *Client) applyOp(op PlannedOp, actionParams ActionParams) (*ShareResult, error) {
+	if op.SharePasswordProtected && op.SharePassword == "" {
+		return nil, fmt.Errorf("share password of %s is not set", op.RemotePath)
+	}
+
+	resource, err := c.lookupResource(op.RemotePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource info: %w", err)
+	}
+	if !op.unchanged(resource) {
+		return nil, fmt.Errorf("%s: %w", op.RemotePath, ErrPlanStale)
+	}
+
+	switch op.Action {
+	case ActionUpload:
+		if resource == nil {
+			_, err := c.Upload(op.LocalPath, op.RemotePath)
+			return nil, err
+		}
+		return nil, c.applyReplace(op)
+	case ActionDelete:
+		return nil, c.DeleteResource(op.RemotePath)
+	case ActionSaveAndShare:
+		actionParams.ShareParams = ShareParams{Expiry: op.ShareExpiry, Password: op.SharePassword}
+		actionParams.ReuseShare, actionParams.Skip, actionParams.DryRun = op.ReuseShare, SkipNever, false
+		auth := FilebrowserAuth{URL: c.URL, Username: c.Username}
+		return (&Pipeline{}).runWith(context.Background(), c, auth, op.ExternalURL, func(string) string { return op.RemotePath }, actionParams)
+	case ActionShare:
+		now := time.Now()
+		share, err := ShareParams{Expiry: op.ShareExpiry, Password: op.SharePassword}.resolve(now)
+		if err != nil {
+			return nil, err
+		}
+		if op.ReuseShare {
+			link, err := reusableShare(c, op.RemotePath, share, now)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list shares: %w", err)
+			}
+			if link != nil {
+				return reusedShareResult(c.URL, op.RemotePath, *link), nil
+			}
+		}
+		hash, err := c.Share(op.RemotePath, share.Expires, share.Password, share.Unit)
+		if err != nil {
+			return nil, err
+		}
+		return newShareResult(c.URL, op.RemotePath, hash, share, now), nil
+	}
+	return nil, fmt.Errorf("unknown action %q", op.Action)
+}
+
+// applyReplace executes an upload replacing an existing remote file. The file is uploaded
+// next to it and moved over it, so a failed upload leaves the existing file in place.
+func (c *Client) applyReplace(op PlannedOp) error {
+	tmpPath, err := tempSibling(op.RemotePath, "apply")
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Upload(op.LocalPath, tmpPath)
+	if err == nil {
+		// Only replace the file if it is still the one the operation was planned for
+		var resource *RespResource
+		resource, err = c.lookupResource(op.RemotePath)
+		switch {
+		case err != nil:
+			err = fmt.Errorf("failed to get resource info: %w", err)
+		case !op.unchanged(resource):
+			err = fmt.Errorf("%s: %w", op.RemotePath, ErrPlanStale)
+		default:
+			err = c.Move(tmpPath, op.RemotePath, true)
+		}
+	}
+	if err != nil {
+		if deleteErr := c.DeleteResource(tmpPath); deleteErr != nil {
+			c.log().Warn("Failed to clean up temporary file", "path", tmpPath, "error", deleteErr)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package filebrowser
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplainSyncApply(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"site/same.txt":    []byte("same"),
+		"site/changed.txt": []byte("old"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	localDir := t.TempDir()
+	for name, content := range map[string]string{"same.txt": "same", "changed.txt": "new!", "new.txt": "new"} {
+		if err := os.WriteFile(filepath.Join(localDir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plan, err := client.ExplainSync(localDir, "site", SyncOptions{})
+	if err != nil {
+		t.Fatalf("ExplainSync() error = %v", err)
+	}
+	if len(plan.Operations) != 2 {
+		t.Fatalf("ExplainSync() = %+v, want 2 uploads", plan.Operations)
+	}
+	if content, _ := server.file("site/changed.txt"); string(content) != "old" {
+		t.Fatal("ExplainSync() changed a file")
+	}
+
+	// Plans survive a round trip through JSON, e.g. for an approval
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var approved ChangePlan
+	if err := json.Unmarshal(data, &approved); err != nil {
+		t.Fatal(err)
+	}
+	applied, err := client.Apply(&approved, ActionParams{})
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("Apply() = %+v, %v", applied, err)
+	}
+	for name, want := range map[string]string{"site/changed.txt": "new!", "site/new.txt": "new"} {
+		if content, _ := server.file(name); string(content) != want {
+			t.Errorf("%s = %q, want %q", name, content, want)
+		}
+	}
+
+	// Applying it again finds the files changed since it was planned
+	if _, err := client.Apply(&approved, ActionParams{}); !errors.Is(err, ErrPlanStale) {
+		t.Errorf("Apply() of stale plan error = %v, want ErrPlanStale", err)
+	}
+}
+
+func TestApplyFailedUpload(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"site/changed.txt": []byte("old")})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	localDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(localDir, "changed.txt"), []byte("new!"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := client.ExplainSync(localDir, "site", SyncOptions{})
+	if err != nil {
+		t.Fatalf("ExplainSync() error = %v", err)
+	}
+
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && strings.Contains(r.URL.Path, ".apply-") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+	if _, err := client.Apply(plan, ActionParams{}); err == nil {
+		t.Fatal("Apply() with failing upload succeeded")
+	}
+	if content, _ := server.file("site/changed.txt"); string(content) != "old" {
+		t.Errorf("content = %q, want the existing file kept", content)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	for name := range server.files {
+		if strings.Contains(name, ".apply-") {
+			t.Errorf("temporary file %s left behind", name)
+		}
+	}
+}
+
+func TestExplainReconcile(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"site/page.html":  []byte("old"),
+		"site/stale.html": []byte("stale"),
+		"site/cache.tmp":  []byte("tmp"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	localDir := t.TempDir()
+	localPath := filepath.Join(localDir, "page.html")
+	if err := os.WriteFile(localPath, []byte("new!"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := client.ExplainReconcile(localDir, "site", SyncOptions{Filter: Not(Extensions(".tmp"))})
+	if err != nil {
+		t.Fatalf("ExplainReconcile() error = %v", err)
+	}
+	if len(plan.Operations) != 2 || plan.Operations[0].Action != ActionUpload || plan.Operations[1].Action != ActionDelete || plan.Operations[1].RemotePath != "site/stale.html" {
+		t.Fatalf("ExplainReconcile() = %+v, want page.html uploaded and stale.html deleted", plan.Operations)
+	}
+
+	// A failed upload leaves the existing file in place
+	if err := os.Rename(localPath, localPath+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Apply(plan, ActionParams{}); err == nil {
+		t.Fatal("Apply() without the local file should fail")
+	}
+	if content, _ := server.file("site/page.html"); string(content) != "old" {
+		t.Errorf("page.html = %q after a failed upload, want it kept", content)
+	}
+	if err := os.Rename(localPath+".moved", localPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Apply(plan, ActionParams{}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if content, _ := server.file("site/page.html"); string(content) != "new!" {
+		t.Errorf("page.html = %q, want %q", content, "new!")
+	}
+	if _, ok := server.file("site/stale.html"); ok {
+		t.Error("stale.html was not deleted")
+	}
+	if _, ok := server.file("site/cache.tmp"); !ok {
+		t.Error("cache.tmp excluded by the filter was deleted")
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.files) != 2 {
+		t.Errorf("remote files = %v, want no temporary files left", server.files)
+	}
+}
+
+func TestExplainQuota(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"logs/a.log": []byte("aaaa"),
+		"logs/b.log": []byte("bbbb"),
+		"logs/c.log": []byte("cccc"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	server.mu.Lock()
+	for i, name := range []string{"logs/a.log", "logs/b.log", "logs/c.log"} {
+		file := server.files[name]
+		file.modified = time.Date(2024, 1, i+1, 0, 0, 0, 0, time.UTC)
+		server.files[name] = file
+	}
+	server.mu.Unlock()
+
+	plan, err := client.ExplainQuota("logs", 5)
+	if err != nil {
+		t.Fatalf("ExplainQuota() error = %v", err)
+	}
+	if len(plan.Operations) != 2 || plan.Operations[0].String() == "" || plan.Operations[1].RemotePath != "logs/b.log" {
+		t.Fatalf("ExplainQuota() = %+v, want a.log and b.log deleted", plan.Operations)
+	}
+	if _, err := client.Apply(plan, ActionParams{}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if _, ok := server.file("logs/b.log"); ok {
+		t.Error("b.log was not deleted")
+	}
+	if _, ok := server.file("logs/c.log"); !ok {
+		t.Error("c.log was deleted")
+	}
+}
+
+func TestExplainSaveAndShare(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("payload"))
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, map[string][]byte{})
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	remotePathFn := func(name string) string { return "shared/" + name }
+	params := ActionParams{
+		URLPolicy:   localOrigin,
+		ShareParams: ShareParams{Expiry: ExpireAfter(time.Hour), Password: "secret"},
+	}
+
+	plan, err := ExplainSaveAndShare(auth, origin.URL+"/file.txt", remotePathFn, params)
+	if err != nil {
+		t.Fatalf("ExplainSaveAndShare() error = %v", err)
+	}
+	op := plan.Operations[0]
+	if op.Action != ActionSaveAndShare || op.RemotePath != "shared/file.txt" || op.Size != 7 || !op.SharePasswordProtected {
+		t.Fatalf("ExplainSaveAndShare() = %+v", op)
+	}
+
+	// The password is not serialized and must be set again
+	data, _ := json.Marshal(plan)
+	var approved ChangePlan
+	if err := json.Unmarshal(data, &approved); err != nil {
+		t.Fatal(err)
+	}
+	if approved.Operations[0].ShareExpiry != ExpireAfter(time.Hour) {
+		t.Errorf("share expiry = %v after JSON, want after 1h", approved.Operations[0].ShareExpiry)
+	}
+	if _, err := client.Apply(&approved, ActionParams{URLPolicy: localOrigin}); err == nil {
+		t.Fatal("Apply() without the share password succeeded")
+	}
+	approved.Operations[0].SharePassword = "secret"
+	applied, err := client.Apply(&approved, ActionParams{URLPolicy: localOrigin})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if applied[0].Share == nil || !applied[0].Share.PasswordProtected {
+		t.Errorf("Apply() share = %+v", applied[0].Share)
+	}
+	if content, _ := server.file("shared/file.txt"); string(content) != "payload" {
+		t.Errorf("remote file = %q", content)
+	}
+
+	// An existing file of the expected size is only shared
+	params.FileSize = 7
+	plan, err = ExplainSaveAndShare(auth, origin.URL+"/file.txt", remotePathFn, params)
+	if err != nil {
+		t.Fatalf("ExplainSaveAndShare() error = %v", err)
+	}
+	if op := plan.Operations[0]; op.Action != ActionShare || op.Existing == nil || *op.Existing != 7 {
+		t.Errorf("ExplainSaveAndShare() of existing file = %+v", op)
+	}
+}
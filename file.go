@@ -1,46 +1,112 @@
 package filebrowser
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/duke-git/lancet/v2/convertor"
 	"github.com/duke-git/lancet/v2/fileutil"
-	"github.com/duke-git/lancet/v2/netutil"
+	"github.com/imroc/req/v3"
 )
 
+// downloadLocks serializes downloads to the same local file within the process
+var downloadLocks keyedMutex
+
 // DownloadToLocal downloads a file from the given URL to a local path.
 // It checks if the file already exists with the same size to avoid re-downloading.
 // Returns the local path where the file was downloaded.
 func DownloadToLocal(fileURL string, fileSize int64) (string, error) {
+	return DownloadToLocalWithOptions(fileURL, fileSize, DownloadOptions{})
+}
+
+// DownloadToLocalWithOptions downloads a file like DownloadToLocal, applying the given options
+func DownloadToLocalWithOptions(fileURL string, fileSize int64, opts DownloadOptions) (string, error) {
+	return DownloadToLocalWithOptionsContext(context.Background(), fileURL, fileSize, opts)
+}
+
+// DownloadToLocalWithOptionsContext is like DownloadToLocalWithOptions, aborting the
+// download once ctx is done
+func DownloadToLocalWithOptionsContext(ctx context.Context, fileURL string, fileSize int64, opts DownloadOptions) (string, error) {
 	if fileURL == "" {
 		return "", fmt.Errorf("file URL cannot be empty")
 	}
+	opts = opts.withDefaults()
+	if opts.MaxBytes > 0 && fileSize > opts.MaxBytes {
+		return "", &DownloadTooLargeError{URL: fileURL, Limit: opts.MaxBytes, Size: fileSize}
+	}
 
-	localPath := LocalPathForDownload(fileURL)
+	client, err := downloadClient(fileURL, opts)
+	if err != nil {
+		return "", err
+	}
+
+	localPath, err := downloadPath(fileURL, opts)
+	if err != nil {
+		return "", err
+	}
 	if err := EnsureFolderForFile(localPath); err != nil {
 		return "", fmt.Errorf("failed to create directory for file: %w", err)
 	}
 
+	// Downloads to the same file share its partial file
+	unlock := downloadLocks.lock(localPath)
+	defer unlock()
+
 	// Check if file already exists with same size
-	if fileSize > 0 && fileExistsWithSameSize(localPath, fileSize) {
-		log.Printf("File already exists with same size, skipping download: %s", localPath)
+	log := packageLog(opts.Logger)
+	if !opts.Overwrite && fileSize > 0 && fileExistsWithSameSize(localPath, fileSize) {
+		log.Info("File already exists with same size, skipping download", "path", localPath)
 		return localPath, nil
 	}
 
 	// Download the file
-	if err := netutil.DownloadFile(localPath, fileURL); err != nil {
+	if err := downloadFile(ctx, client, localPath, fileURL, fileSize, opts); err != nil {
 		return "", fmt.Errorf("failed to download file from %s: %w", fileURL, err)
 	}
 
-	log.Printf("Successfully downloaded file to: %s", localPath)
+	log.Info("Downloaded file", "url", fileURL, "path", localPath)
 	return localPath, nil
 }
 
+// downloadClient returns the HTTP client downloading fileURL as allowed by the URL policy
+// and redirect limit of opts
+func downloadClient(fileURL string, opts DownloadOptions) (*req.Client, error) {
+	policy := opts.URLPolicy
+	if policy == nil {
+		switch {
+		case opts.MaxRedirects > 0:
+			// via holds the requests made so far, one more than the redirects followed
+			return defaultHTTPClient.Clone().SetRedirectPolicy(req.MaxRedirectPolicy(opts.MaxRedirects + 1)), nil
+		case opts.MaxRedirects < 0:
+			return defaultHTTPClient.Clone().SetRedirectPolicy(req.NoRedirectPolicy()), nil
+		}
+		return defaultHTTPClient, nil
+	}
+
+	parsedURL, err := url.Parse(fileURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file URL: %w", err)
+	}
+	if err := policy.checkURL(parsedURL); err != nil {
+		return nil, err
+	}
+	if opts.MaxRedirects != 0 {
+		limited := *policy
+		limited.MaxRedirects = opts.MaxRedirects
+		policy = &limited
+	}
+	return policy.httpClient(), nil
+}
+
 // fileExistsWithSameSize checks if a file exists and has the same size as expected
 func fileExistsWithSameSize(localPath string, expectedSize int64) bool {
 	if !fileutil.IsExist(localPath) {
@@ -49,13 +115,11 @@ func fileExistsWithSameSize(localPath string, expectedSize int64) bool {
 
 	localSize, err := fileutil.FileSize(localPath)
 	if err != nil {
-		log.Printf("Warning: failed to get local file size: %v", err)
 		return false
 	}
 
 	expectedSizeInt, err := convertor.ToInt(expectedSize)
 	if err != nil {
-		log.Printf("Warning: failed to convert expected size: %v", err)
 		return false
 	}
 
@@ -63,22 +127,51 @@ func fileExistsWithSameSize(localPath string, expectedSize int64) bool {
 }
 
 // LocalPathForDownload generates a local path for downloading a file from a URL.
-// It uses the system's temp directory as the base path.
+// It uses the staging directory of the process as the base path, see StagingDir.
 func LocalPathForDownload(fileURL string) string {
-	parsedURL, err := url.Parse(fileURL)
-	if err != nil {
-		log.Printf("Warning: failed to parse URL %s: %v", fileURL, err)
-		// Fallback: use URL as filename
-		return filepath.Join(os.TempDir(), filepath.Base(fileURL))
+	return filepath.Join(StagingDir(), urlFileName(fileURL))
+}
+
+// urlFileName returns the path of a URL as a relative file name. Dot segments are
+// resolved against the root, so the name never leaves the directory it is joined to.
+func urlFileName(fileURL string) string {
+	name := filepath.Base(fileURL)
+	if parsedURL, err := url.Parse(fileURL); err == nil {
+		name = strings.TrimPrefix(path.Clean("/"+parsedURL.Path), "/")
 	}
 
-	path := strings.TrimPrefix(parsedURL.Path, "/")
-	if path == "" {
+	if name == "" || !filepath.IsLocal(filepath.FromSlash(name)) {
 		// If path is empty, use a default filename
-		path = "downloaded_file"
+		name = "downloaded_file"
 	}
+	return filepath.FromSlash(name)
+}
+
+// HostFileName is a DownloadOptions.FileNameFn naming downloads after the host and path
+// of their URL, so files of the same name from different hosts don't collide
+func HostFileName(fileURL string) string {
+	parsedURL, err := url.Parse(fileURL)
+	if err != nil || parsedURL.Host == "" {
+		return urlFileName(fileURL)
+	}
+	return filepath.Join(strings.ReplaceAll(parsedURL.Host, ":", "_"), urlFileName(fileURL))
+}
 
-	return filepath.Join(os.TempDir(), path)
+// downloadPath returns the local path fileURL is downloaded to with the directory and
+// naming strategy of opts
+func downloadPath(fileURL string, opts DownloadOptions) (string, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = StagingDir()
+	}
+	name := urlFileName(fileURL)
+	if opts.FileNameFn != nil {
+		name = opts.FileNameFn(fileURL)
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("file name %q must be a relative path within the download directory", name)
+	}
+	return filepath.Join(dir, name), nil
 }
 
 // EnsureFolderForFile creates the directory structure needed for the given file path.
@@ -94,3 +187,64 @@ func EnsureFolderForFile(localPath string) error {
 
 	return nil
 }
+
+// writeJSONFileAtomic writes v as indented JSON to localPath, see writeFileAtomic
+func writeJSONFileAtomic(localPath string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(localPath), err)
+	}
+	return writeFileAtomic(localPath, data)
+}
+
+// writeFileAtomic replaces the content of localPath, readable only by the owner. The data
+// is written to a uniquely named temporary file in the same directory and renamed over
+// localPath, so readers never see a partial file and concurrent writers never share a
+// temporary file.
+func writeFileAtomic(localPath string, data []byte) error {
+	if err := EnsureFolderForFile(localPath); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), "."+filepath.Base(localPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), localPath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace %s: %w", localPath, err)
+	}
+	return nil
+}
+
+// fileSHA256 computes the hex encoded SHA-256 checksum of a local file.
+func fileSHA256(localPath string) (string, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash local file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// localFileSize returns the size of a local file, or zero if it cannot be determined
+func localFileSize(localPath string) int64 {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
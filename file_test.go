@@ -15,17 +15,22 @@ func TestLocalPathForDownload(t *testing.T) {
 		{
 			name:     "Simple URL with path",
 			fileURL:  "https://example.com/files/document.pdf",
-			expected: filepath.Join(os.TempDir(), "files", "document.pdf"),
+			expected: filepath.Join(StagingDir(), "files", "document.pdf"),
 		},
 		{
 			name:     "URL with root path",
 			fileURL:  "https://example.com/document.pdf",
-			expected: filepath.Join(os.TempDir(), "document.pdf"),
+			expected: filepath.Join(StagingDir(), "document.pdf"),
+		},
+		{
+			name:     "URL escaping the staging directory",
+			fileURL:  "https://example.com/../../etc/passwd",
+			expected: filepath.Join(StagingDir(), "etc", "passwd"),
 		},
 		{
 			name:     "URL with empty path",
 			fileURL:  "https://example.com/",
-			expected: filepath.Join(os.TempDir(), "downloaded_file"),
+			expected: filepath.Join(StagingDir(), "downloaded_file"),
 		},
 	}
 
@@ -0,0 +1,756 @@
+// Package filebrowsertest provides an in-memory Filebrowser server for tests of code using
+// the filebrowser SDK, so they do not need a live Filebrowser instance.
+//
+// The server implements the endpoints used by the SDK: login, TUS uploads, raw downloads
+// including archives of directories, previews, resources (listing, creation, updates,
+// deletion, renaming, copying and chmod) and shares with their public links. Requests
+// other than login need the token it returned in the X-Auth header.
+package filebrowsertest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Token is the token returned by the login of the server
+const Token = "filebrowsertest-token"
+
+// sharePasswordHeader carries the password of protected shares
+const sharePasswordHeader = "X-SHARE-PASSWORD"
+
+// Share is a share link of the server
+type Share struct {
+	Hash     string
+	Path     string    // Shared path, e.g. "/docs/report.pdf"
+	Expires  time.Time // Zero if the share never expires
+	Password string    // Empty if the share is not protected
+}
+
+// entry is a file or directory of the server
+type entry struct {
+	dir      bool
+	content  []byte
+	modified time.Time
+	mode     fs.FileMode
+}
+
+// upload is a TUS upload in progress
+type upload struct {
+	length int64 // -1 if the length is deferred
+}
+
+// Server is a fake Filebrowser instance keeping its files in memory. Its URL is the
+// Filebrowser URL to configure clients with. Handlers can be wrapped, e.g. to inject
+// failures, by replacing Config.Handler before the first request.
+type Server struct {
+	*httptest.Server
+
+	Username string
+	Password string
+
+	mu      sync.Mutex
+	entries map[string]*entry // by path without leading slash, "" being the root
+	uploads map[string]upload
+	shares  []Share
+	hashes  int // Number of share hashes handed out
+}
+
+// NewServer starts a Server accepting the given credentials. It must be closed after use,
+// e.g. with t.Cleanup(server.Close).
+func NewServer(username string, password string) *Server {
+	s := &Server{
+		Username: username,
+		Password: password,
+		entries:  map[string]*entry{"": {dir: true, modified: time.Now()}},
+		uploads:  map[string]upload{},
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// cleanPath returns remotePath relative to the root, e.g. "docs/a.txt" for "/docs/a.txt/"
+func cleanPath(remotePath string) string {
+	return strings.Trim(path.Clean("/"+remotePath), "/")
+}
+
+// SetFile creates or replaces a file together with its missing parent directories
+func (s *Server) SetFile(remotePath string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := cleanPath(remotePath)
+	s.mkdirAll(path.Dir(name))
+	s.entries[name] = &entry{content: bytes.Clone(content), modified: time.Now()}
+}
+
+// SetModified sets the modification time of a file or directory, e.g. for tests of code
+// ordering files by age. It does nothing if there is none at remotePath.
+func (s *Server) SetModified(remotePath string, modified time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[cleanPath(remotePath)]; ok {
+		e.modified = modified
+	}
+}
+
+// Mkdir creates a directory together with its missing parents
+func (s *Server) Mkdir(remotePath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mkdirAll(cleanPath(remotePath))
+}
+
+// File returns the content of a file and whether it exists
+func (s *Server) File(remotePath string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[cleanPath(remotePath)]
+	if !ok || e.dir {
+		return nil, false
+	}
+	return bytes.Clone(e.content), true
+}
+
+// Exists reports whether a file or directory exists at remotePath
+func (s *Server) Exists(remotePath string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.entries[cleanPath(remotePath)]
+	return ok
+}
+
+// Mode returns the mode set for a file or directory with chmod, zero if none was
+func (s *Server) Mode(remotePath string) fs.FileMode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[cleanPath(remotePath)]; ok {
+		return e.mode
+	}
+	return 0
+}
+
+// Paths returns the paths of all files and directories below the root, sorted
+func (s *Server) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var paths []string
+	for name := range s.entries {
+		if name != "" {
+			paths = append(paths, "/"+name)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Shares returns the share links of the server in the order they were created
+func (s *Server) Shares() []Share {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Share(nil), s.shares...)
+}
+
+// mkdirAll creates the directory name and its missing parents
+func (s *Server) mkdirAll(name string) {
+	for name != "" && name != "." {
+		if _, ok := s.entries[name]; ok {
+			return
+		}
+		s.entries[name] = &entry{dir: true, modified: time.Now()}
+		name = path.Dir(name)
+	}
+}
+
+// below returns the paths at and below name
+func (s *Server) below(name string) []string {
+	var names []string
+	for other := range s.entries {
+		if other == name || name == "" || strings.HasPrefix(other, name+"/") {
+			names = append(names, other)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	api, name, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/"), "/")
+	switch {
+	case r.URL.Path == "/api/login" && r.Method == http.MethodPost:
+		s.login(w, r)
+		return
+	case api == "public":
+		s.servePublic(w, r, name)
+		return
+	case !strings.HasPrefix(r.URL.Path, "/api/"):
+		http.NotFound(w, r)
+		return
+	case r.Header.Get("X-Auth") != Token:
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch api {
+	case "tus":
+		s.serveTUS(w, r, cleanPath(name))
+	case "raw":
+		s.serveRaw(w, r, cleanPath(name))
+	case "preview":
+		_, name, _ = strings.Cut(name, "/") // Size of the preview
+		s.servePreview(w, r, cleanPath(name))
+	case "resources":
+		s.serveResources(w, r, cleanPath(name), strings.HasSuffix(name, "/"))
+	case "share", "shares":
+		s.serveShares(w, r, cleanPath(name))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// login returns Token for the credentials of the server
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	var credentials struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if credentials.Username != s.Username || credentials.Password != s.Password {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.Write([]byte(Token))
+}
+
+// serveTUS handles the TUS uploads to name
+func (s *Server) serveTUS(w http.ResponseWriter, r *http.Request, name string) {
+	w.Header().Set("Tus-Resumable", "1.0.0")
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Tus-Version", "1.0.0")
+		w.Header().Set("Tus-Extension", "creation,creation-defer-length,termination")
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		if e, ok := s.entries[name]; ok && (e.dir || r.URL.Query().Get("override") != "true") {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		length := int64(-1)
+		if r.Header.Get("Upload-Defer-Length") != "1" {
+			var err error
+			if length, err = strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64); err != nil || length < 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		s.mkdirAll(path.Dir(name))
+		s.entries[name] = &entry{modified: time.Now()}
+		s.uploads[name] = upload{length: length}
+		w.Header().Set("Location", r.URL.EscapedPath())
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodHead:
+		e, ok := s.entries[name]
+		if !ok || e.dir {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(e.content)))
+		if upload, ok := s.uploads[name]; ok && upload.length >= 0 {
+			w.Header().Set("Upload-Length", strconv.FormatInt(upload.length, 10))
+		} else if !ok {
+			w.Header().Set("Upload-Length", strconv.Itoa(len(e.content)))
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPatch:
+		e, ok := s.entries[name]
+		upload, uploading := s.uploads[name]
+		if !ok || e.dir || !uploading {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		if offset, err := strconv.Atoi(r.Header.Get("Upload-Offset")); err != nil || offset != len(e.content) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if upload.length < 0 && r.Header.Get("Upload-Length") != "" {
+			length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+			if err != nil || length < 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			upload.length = length
+			s.uploads[name] = upload
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if upload.length >= 0 && int64(len(e.content)+len(body)) > upload.length {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		e.content = append(e.content, body...)
+		e.modified = time.Now()
+		if int64(len(e.content)) == upload.length {
+			delete(s.uploads, name)
+		}
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(e.content)))
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if _, ok := s.uploads[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(s.uploads, name)
+		delete(s.entries, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// serveRaw serves the content of a file, or an archive of a directory
+func (s *Server) serveRaw(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	e, ok := s.entries[name]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if e.dir || r.URL.Query().Get("algo") != "" {
+		s.serveArchive(w, r.URL.Query().Get("algo"), name)
+		return
+	}
+	if r.URL.Query().Get("inline") != "true" {
+		w.Header().Set("Content-Disposition", "attachment; filename*=utf-8''"+path.Base(name))
+	}
+	// Like Filebrowser, serve ranges of the file if requested
+	http.ServeContent(w, r, name, e.modified, bytes.NewReader(e.content))
+}
+
+// serveArchive writes an archive of the files at and below name in the format algo,
+// zip by default
+func (s *Server) serveArchive(w http.ResponseWriter, algo string, name string) {
+	// Entries are named relative to the directory, or to the parent of a file
+	prefix := name + "/"
+	if !s.entries[name].dir {
+		prefix = path.Dir(name) + "/"
+	}
+	if prefix == "/" || prefix == "./" {
+		prefix = ""
+	}
+
+	var files []string
+	for _, other := range s.below(name) {
+		if !s.entries[other].dir {
+			files = append(files, other)
+		}
+	}
+
+	switch algo {
+	case "", "zip":
+		archive := zip.NewWriter(w)
+		for _, file := range files {
+			entryWriter, _ := archive.Create(strings.TrimPrefix(file, prefix))
+			entryWriter.Write(s.entries[file].content)
+		}
+		archive.Close()
+	case "tar", "targz":
+		var out io.Writer = w
+		if algo == "targz" {
+			zw := gzip.NewWriter(w)
+			defer zw.Close()
+			out = zw
+		}
+		archive := tar.NewWriter(out)
+		for _, file := range files {
+			e := s.entries[file]
+			archive.WriteHeader(&tar.Header{Name: strings.TrimPrefix(file, prefix), Mode: 0o644, Size: int64(len(e.content)), ModTime: e.modified})
+			archive.Write(e.content)
+		}
+		archive.Close()
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// servePreview serves the content of an image as its preview
+func (s *Server) servePreview(w http.ResponseWriter, r *http.Request, name string) {
+	e, ok := s.entries[name]
+	if !ok || e.dir || fileType(name) != "image" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	http.ServeContent(w, r, name, e.modified, bytes.NewReader(e.content))
+}
+
+// serveResources handles the resources API for name. dir is set if its path ended with a
+// slash, making POST create a directory.
+func (s *Server) serveResources(w http.ResponseWriter, r *http.Request, name string, dir bool) {
+	query := r.URL.Query()
+	switch r.Method {
+	case http.MethodGet:
+		if _, ok := s.entries[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		resource := s.resource(name)
+		if e := s.entries[name]; !e.dir && query.Get("checksum") != "" {
+			sum, ok := checksum(query.Get("checksum"), e.content)
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			resource["checksums"] = map[string]string{query.Get("checksum"): sum}
+		}
+		if e := s.entries[name]; e.dir {
+			items := []map[string]any{}
+			for _, other := range s.below(name) {
+				if other != name && path.Dir("/"+other) == path.Clean("/"+name) {
+					items = append(items, s.resource(other))
+				}
+			}
+			resource["items"] = items
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resource)
+	case http.MethodPost:
+		if name == "" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if e, ok := s.entries[name]; ok && (e.dir || dir || query.Get("override") != "true") {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.mkdirAll(path.Dir(name))
+		if dir {
+			s.entries[name] = &entry{dir: true, modified: time.Now()}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.entries[name] = &entry{content: content, modified: time.Now()}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPut:
+		e, ok := s.entries[name]
+		if !ok || e.dir {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		e.content, e.modified = content, time.Now()
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		if name == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if _, ok := s.entries[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for _, other := range s.below(name) {
+			delete(s.entries, other)
+			delete(s.uploads, other)
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPatch:
+		s.patchResource(w, name, query.Get("action"), query)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// patchResource renames, copies or changes the mode of name
+func (s *Server) patchResource(w http.ResponseWriter, name string, action string, query map[string][]string) {
+	get := func(key string) string {
+		if values := query[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	e, ok := s.entries[name]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch action {
+	case "chmod":
+		mode, err := strconv.ParseUint(get("mode"), 8, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		e.mode = fs.FileMode(mode)
+		w.WriteHeader(http.StatusOK)
+	case "rename", "copy":
+		dst := cleanPath(get("destination"))
+		if name == "" || dst == "" || dst == name || strings.HasPrefix(dst, name+"/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, ok := s.entries[dst]; ok {
+			if get("override") != "true" {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			for _, other := range s.below(dst) {
+				delete(s.entries, other)
+			}
+		}
+		if _, ok := s.entries[path.Dir(dst)]; !ok && path.Dir(dst) != "." {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for _, other := range s.below(name) {
+			moved := *s.entries[other]
+			moved.content = bytes.Clone(moved.content)
+			if action == "rename" {
+				delete(s.entries, other)
+			} else {
+				moved.modified = time.Now()
+			}
+			s.entries[dst+strings.TrimPrefix(other, name)] = &moved
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// resource describes name like the resources API of Filebrowser
+func (s *Server) resource(name string) map[string]any {
+	e := s.entries[name]
+	resource := map[string]any{
+		"path":      "/" + name,
+		"name":      path.Base("/" + name),
+		"size":      len(e.content),
+		"extension": path.Ext(name),
+		"modified":  e.modified.Format(time.RFC3339Nano),
+		"mode":      e.mode,
+		"isDir":     e.dir,
+		"isSymlink": false,
+		"type":      fileType(name),
+	}
+	if e.dir {
+		resource["size"], resource["extension"], resource["type"] = 0, "", ""
+	}
+	return resource
+}
+
+// fileType returns the Filebrowser type of a file from its extension
+func fileType(name string) string {
+	mimeType, _, _ := strings.Cut(mime.TypeByExtension(path.Ext(name)), ";")
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "image"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "video"
+	case strings.HasPrefix(mimeType, "audio/"):
+		return "audio"
+	case strings.HasPrefix(mimeType, "text/"), mimeType == "application/json":
+		return "text"
+	}
+	return "blob"
+}
+
+// checksum returns the hex checksum of content with the algorithm of the resources API
+func checksum(algo string, content []byte) (string, bool) {
+	var h hash.Hash
+	switch algo {
+	case "md5":
+		h = md5.New()
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", false
+	}
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// respShareLink is a share as listed by the share API
+type respShareLink struct {
+	Hash         string `json:"hash"`
+	Path         string `json:"path"`
+	Expire       int64  `json:"expire"`
+	PasswordHash string `json:"password_hash,omitempty"`
+}
+
+// link describes a share like the share API of Filebrowser
+func (share Share) link() respShareLink {
+	link := respShareLink{Hash: share.Hash, Path: share.Path}
+	if !share.Expires.IsZero() {
+		link.Expire = share.Expires.Unix()
+	}
+	if share.Password != "" {
+		link.PasswordHash = fmt.Sprintf("%x", sha256.Sum256([]byte(share.Password)))
+	}
+	return link
+}
+
+// serveShares handles the share API: listing, creating and deleting the shares of name,
+// name being the hash of the share for DELETE
+func (s *Server) serveShares(w http.ResponseWriter, r *http.Request, name string) {
+	switch r.Method {
+	case http.MethodGet:
+		links := []respShareLink{}
+		for _, share := range s.shares {
+			if r.URL.Path == "/api/shares" || share.Path == "/"+name {
+				links = append(links, share.link())
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(links)
+	case http.MethodPost:
+		if _, ok := s.entries[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body struct {
+			Expires  string `json:"expires"`
+			Password string `json:"password"`
+			Unit     string `json:"unit"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		share := Share{Path: "/" + name, Password: body.Password}
+		if body.Expires != "" && body.Expires != "0" {
+			expires, err := strconv.ParseInt(body.Expires, 10, 64)
+			if err != nil || expires < 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			unit := map[string]time.Duration{"seconds": time.Second, "minutes": time.Minute, "hours": time.Hour, "days": 24 * time.Hour}[body.Unit]
+			if unit == 0 {
+				unit = time.Hour
+			}
+			share.Expires = time.Now().Add(time.Duration(expires) * unit)
+		}
+		s.hashes++
+		share.Hash = fmt.Sprintf("share%04d", s.hashes)
+		s.shares = append(s.shares, share)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(share.link())
+	case http.MethodDelete:
+		for i, share := range s.shares {
+			if share.Hash == name {
+				s.shares = append(s.shares[:i], s.shares[i+1:]...)
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// servePublic handles the public API of share links: the resources of
+// /api/public/share/{hash}/{path} and the content of /api/public/dl/{hash}/{path}
+func (s *Server) servePublic(w http.ResponseWriter, r *http.Request, name string) {
+	api, name, _ := strings.Cut(name, "/")
+	hash, sharedPath, _ := strings.Cut(name, "/")
+
+	var share *Share
+	for i := range s.shares {
+		if s.shares[i].Hash == hash {
+			share = &s.shares[i]
+		}
+	}
+	if share == nil || (!share.Expires.IsZero() && time.Now().After(share.Expires)) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if share.Password != "" && r.Header.Get(sharePasswordHeader) != share.Password {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	root := cleanPath(share.Path)
+	target := root
+	if sharedPath = cleanPath(sharedPath); sharedPath != "" {
+		if e, ok := s.entries[root]; !ok || !e.dir {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		target = cleanPath(root + "/" + sharedPath)
+	}
+	if _, ok := s.entries[target]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch api {
+	case "share":
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		s.serveResources(w, r, target, false)
+	case "dl":
+		s.serveRaw(w, r, target)
+	default:
+		http.NotFound(w, r)
+	}
+}
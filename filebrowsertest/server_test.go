@@ -0,0 +1,121 @@
+package filebrowsertest_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kiuber/filebrowser-sdk/v2"
+	"github.com/kiuber/filebrowser-sdk/v2/filebrowsertest"
+)
+
+func newClient(t *testing.T) (*filebrowser.Client, *filebrowsertest.Server) {
+	t.Helper()
+
+	server := filebrowsertest.NewServer("admin", "secret")
+	t.Cleanup(server.Close)
+	client, err := filebrowser.NewClient(server.URL, "admin", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, server
+}
+
+func TestServerFiles(t *testing.T) {
+	client, server := newClient(t)
+
+	localPath := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(localPath, []byte("quarterly numbers"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Upload(localPath, "/docs/report.txt"); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if content, ok := server.File("/docs/report.txt"); !ok || string(content) != "quarterly numbers" {
+		t.Fatalf("uploaded file = %q, %v", content, ok)
+	}
+
+	resource, err := client.GetResource("/docs")
+	if err != nil {
+		t.Fatalf("GetResource: %v", err)
+	}
+	if !resource.IsDir || len(resource.Items) != 1 || resource.Items[0].Name != "report.txt" || resource.Items[0].Size != 17 {
+		t.Fatalf("directory = %+v", resource)
+	}
+
+	var downloaded bytes.Buffer
+	if err := client.Download("/docs/report.txt", &downloaded); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if downloaded.String() != "quarterly numbers" {
+		t.Fatalf("downloaded %q", downloaded.String())
+	}
+
+	if err := client.Move("/docs/report.txt", "/archive/report.txt", false); err == nil {
+		t.Fatal("Move into a missing directory succeeded")
+	}
+	if err := client.MkdirAll("/archive"); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := client.Move("/docs/report.txt", "/archive/report.txt", false); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if err := client.DeleteResource("/archive"); err != nil {
+		t.Fatalf("DeleteResource: %v", err)
+	}
+	if _, err := client.GetResource("/archive/report.txt"); !errors.Is(err, filebrowser.ErrNotFound) {
+		t.Fatalf("GetResource of a deleted file: %v", err)
+	}
+	if paths := server.Paths(); len(paths) != 1 || paths[0] != "/docs" {
+		t.Fatalf("paths = %v", paths)
+	}
+}
+
+func TestServerShares(t *testing.T) {
+	client, server := newClient(t)
+	server.SetFile("/photos/cat.jpg", []byte("meow"))
+
+	hash, err := client.Share("/photos/cat.jpg", 2, "hunter2", "days")
+	if err != nil {
+		t.Fatalf("Share: %v", err)
+	}
+	links, err := client.ListShares("/photos/cat.jpg")
+	if err != nil {
+		t.Fatalf("ListShares: %v", err)
+	}
+	if len(links) != 1 || links[0].Hash != hash || !links[0].PasswordProtected || links[0].Expires.IsZero() {
+		t.Fatalf("links = %+v", links)
+	}
+	if shares := server.Shares(); len(shares) != 1 || shares[0].Password != "hunter2" {
+		t.Fatalf("shares = %+v", shares)
+	}
+
+	public := &filebrowser.SharePublicClient{URL: server.URL, Hash: hash}
+	if err := public.Download("", &bytes.Buffer{}); !errors.Is(err, filebrowser.ErrUnauthorized) {
+		t.Fatalf("Download without the password: %v", err)
+	}
+	public.Password = "hunter2"
+	var downloaded bytes.Buffer
+	if err := public.Download("", &downloaded); err != nil || downloaded.String() != "meow" {
+		t.Fatalf("Download = %q, %v", downloaded.String(), err)
+	}
+
+	if _, err := client.Share("/photos/dog.jpg", 0, "", ""); !errors.Is(err, filebrowser.ErrNotFound) {
+		t.Fatalf("Share of a missing file: %v", err)
+	}
+}
+
+func TestServerLogin(t *testing.T) {
+	server := filebrowsertest.NewServer("admin", "secret")
+	t.Cleanup(server.Close)
+
+	client, err := filebrowser.NewClient(server.URL, "admin", "wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetResource("/"); err == nil {
+		t.Fatal("GetResource with wrong credentials succeeded")
+	}
+}
@@ -0,0 +1,111 @@
+package filebrowser
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FilterEntry describes a local or remote file to a Filter
+type FilterEntry struct {
+	// Path is slash-separated and relative to the root of the operation: the directory
+	// walked, synced, transferred or pruned, or the server root for UploadMany. The same
+	// filter thus selects the same files locally and remotely.
+	Path     string
+	Size     int64
+	Modified time.Time
+}
+
+// Filter selects the files handled by bulk operations, see WalkOptions.Filter,
+// SyncOptions.Filter, UploadFSOptions.Filter, UploadManyOptions.Filter,
+// QuotaOptions.Filter, UploadDirOptions.Filter and DownloadDirOptions.Filter. Directories
+// are always traversed.
+// Filters are combined with And, Or and Not.
+type Filter interface {
+	Match(entry FilterEntry) bool
+}
+
+// FilterFunc adapts an ordinary function to the Filter interface
+type FilterFunc func(entry FilterEntry) bool
+
+// Match calls f(entry)
+func (f FilterFunc) Match(entry FilterEntry) bool {
+	return f(entry)
+}
+
+// SizeRange matches files of at least minSize and at most maxSize bytes. A maxSize of
+// zero or less sets no upper bound.
+func SizeRange(minSize int64, maxSize int64) Filter {
+	return FilterFunc(func(entry FilterEntry) bool {
+		return entry.Size >= minSize && (maxSize <= 0 || entry.Size <= maxSize)
+	})
+}
+
+// Extensions matches files with one of the given extensions, e.g. ".jpg", ignoring case
+func Extensions(exts ...string) Filter {
+	return FilterFunc(func(entry FilterEntry) bool {
+		ext := path.Ext(entry.Path)
+		for _, want := range exts {
+			if strings.EqualFold(ext, want) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ModifiedWithin matches files modified less than age ago when the filter is applied
+func ModifiedWithin(age time.Duration) Filter {
+	return FilterFunc(func(entry FilterEntry) bool {
+		return time.Since(entry.Modified) < age
+	})
+}
+
+// OlderThan matches files modified at least age ago when the filter is applied
+func OlderThan(age time.Duration) Filter {
+	return Not(ModifiedWithin(age))
+}
+
+// PathRegexp matches files whose path matches re
+func PathRegexp(re *regexp.Regexp) Filter {
+	return FilterFunc(func(entry FilterEntry) bool {
+		return re.MatchString(entry.Path)
+	})
+}
+
+// And matches files matched by every filter, and every file without filters
+func And(filters ...Filter) Filter {
+	return FilterFunc(func(entry FilterEntry) bool {
+		for _, filter := range filters {
+			if !filter.Match(entry) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Or matches files matched by any of the filters, and no file without filters
+func Or(filters ...Filter) Filter {
+	return FilterFunc(func(entry FilterEntry) bool {
+		for _, filter := range filters {
+			if filter.Match(entry) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Not matches files not matched by filter
+func Not(filter Filter) Filter {
+	return FilterFunc(func(entry FilterEntry) bool {
+		return !filter.Match(entry)
+	})
+}
+
+// matchFilter reports whether filter matches entry, matching everything without a filter
+func matchFilter(filter Filter, entry FilterEntry) bool {
+	return filter == nil || filter.Match(entry)
+}
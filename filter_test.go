@@ -0,0 +1,107 @@
+package filebrowser
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFilters(t *testing.T) {
+	now := time.Now()
+	photo := FilterEntry{Path: "photos/a.JPG", Size: 2048, Modified: now.Add(-48 * time.Hour)}
+	notes := FilterEntry{Path: "docs/notes.txt", Size: 10, Modified: now.Add(-time.Minute)}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []bool // Matches of photo and notes
+	}{
+		{"size range", SizeRange(1024, 0), []bool{true, false}},
+		{"bounded size range", SizeRange(0, 100), []bool{false, true}},
+		{"extensions", Extensions(".jpg", ".png"), []bool{true, false}},
+		{"modified within", ModifiedWithin(time.Hour), []bool{false, true}},
+		{"older than", OlderThan(24 * time.Hour), []bool{true, false}},
+		{"regexp", PathRegexp(regexp.MustCompile(`^docs/`)), []bool{false, true}},
+		{"func", FilterFunc(func(e FilterEntry) bool { return e.Size == 10 }), []bool{false, true}},
+		{"and", And(Extensions(".jpg"), SizeRange(0, 100)), []bool{false, false}},
+		{"empty and", And(), []bool{true, true}},
+		{"or", Or(Extensions(".jpg"), SizeRange(0, 100)), []bool{true, true}},
+		{"empty or", Or(), []bool{false, false}},
+		{"not", Not(Extensions(".txt")), []bool{true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := []bool{tt.filter.Match(photo), tt.filter.Match(notes)}; !slices.Equal(got, tt.want) {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBulkFilters(t *testing.T) {
+	onlyText := Extensions(".txt")
+
+	// Walk passes the matching files and every directory
+	server := newTreeServer(t, map[string]testFile{
+		"a.txt":     {content: []byte("a")},
+		"b.bin":     {content: []byte("b")},
+		"dir/c.txt": {content: []byte("c")},
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	var walked []string
+	_, err := client.WalkWithOptions("/", func(resource *RespResource) error {
+		walked = append(walked, resource.Path)
+		return nil
+	}, WalkOptions{Filter: onlyText})
+	if err != nil {
+		t.Fatalf("WalkWithOptions() error = %v", err)
+	}
+	slices.Sort(walked)
+	if want := []string{"/a.txt", "/dir", "/dir/c.txt"}; !slices.Equal(walked, want) {
+		t.Errorf("walked %v, want %v", walked, want)
+	}
+
+	// Walk filters match paths relative to the root
+	walked = nil
+	_, err = client.WalkWithOptions("dir", func(resource *RespResource) error {
+		walked = append(walked, resource.Path)
+		return nil
+	}, WalkOptions{Filter: PathRegexp(regexp.MustCompile(`^c\.txt$`))})
+	if err != nil || !slices.Equal(walked, []string{"/dir/c.txt"}) {
+		t.Errorf("walked %v, %v, want /dir/c.txt", walked, err)
+	}
+
+	// Sync analysis leaves filtered files out of the report
+	localDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.bin"} {
+		if err := os.WriteFile(filepath.Join(localDir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	memServer := newMemServer(t, nil)
+	client = &Client{URL: memServer.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	report, err := client.AnalyzeSyncWithOptions(localDir, "remote", SyncOptions{Filter: onlyText})
+	if err != nil {
+		t.Fatalf("AnalyzeSyncWithOptions() error = %v", err)
+	}
+	if len(report.Entries) != 1 || report.Entries[0].RemotePath != "remote/a.txt" {
+		t.Errorf("entries = %+v, want only a.txt", report.Entries)
+	}
+
+	// UploadFS uploads only matching files
+	assets := fstest.MapFS{
+		"web/index.txt": {Data: []byte("index")},
+		"web/big.bin":   {Data: []byte("binary")},
+	}
+	uploaded, err := client.UploadFSWithOptions(assets, "web", "site", UploadFSOptions{Filter: onlyText})
+	if err != nil {
+		t.Fatalf("UploadFSWithOptions() error = %v", err)
+	}
+	if len(uploaded) != 1 || uploaded[0] != "site/index.txt" || memServer.exists("site/big.bin") {
+		t.Errorf("uploaded %v, want only site/index.txt", uploaded)
+	}
+}
@@ -0,0 +1,113 @@
+package filebrowser
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Limits applied by Grep when GrepOptions fields are zero
+const (
+	defaultGrepMaxSize    = 10 * 1024 * 1024
+	defaultGrepMaxLineLen = 1024 * 1024
+)
+
+// GrepOptions selects the remote files searched by Grep
+type GrepOptions struct {
+	// Extensions restricts the search to files with these extensions (e.g. ".yaml"),
+	// compared case-insensitively. Empty searches every file.
+	Extensions []string
+	// MaxSize skips files larger than this many bytes, 10MB if zero
+	MaxSize int64
+	// MaxLineLength skips files containing longer lines, typically binaries, 1MB if zero
+	MaxLineLength int
+}
+
+// GrepMatch is a line of a remote file matching the Grep pattern
+type GrepMatch struct {
+	Path string // Server path of the file
+	Line int    // Line number, starting at 1
+	Text string // Line content without the line terminator
+}
+
+// GrepFunc is called by Grep for every matching line. A non-nil error stops the search
+// and is returned by Grep.
+type GrepFunc func(match GrepMatch) error
+
+// Grep searches the content of the files below root selected by opts for lines matching
+// pattern, calling fn for every match as files are downloaded. The server search only
+// matches file names, so every candidate file is downloaded and searched client-side.
+func (c *Client) Grep(root string, pattern *regexp.Regexp, opts GrepOptions, fn GrepFunc) (err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if pattern == nil {
+		return fmt.Errorf("pattern cannot be nil")
+	}
+	if fn == nil {
+		return fmt.Errorf("grep function cannot be nil")
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = defaultGrepMaxSize
+	}
+	if opts.MaxLineLength <= 0 {
+		opts.MaxLineLength = defaultGrepMaxLineLen
+	}
+
+	return c.Walk(root, func(resource *RespResource) error {
+		if resource.IsDir || resource.Size > opts.MaxSize || !matchesExtension(resource.Path, opts.Extensions) {
+			return nil
+		}
+		return c.grepFile(resource.Path, pattern, opts.MaxLineLength, fn)
+	})
+}
+
+// grepFile searches a single remote file line by line
+func (c *Client) grepFile(remotePath string, pattern *regexp.Regexp, maxLineLength int, fn GrepFunc) error {
+	body, _, err := c.openRaw(context.Background(), resourcePath(remotePath))
+	if errors.Is(err, ErrNotFound) {
+		// Removed since it was listed
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, min(64*1024, maxLineLength)), maxLineLength)
+	for line := 1; scanner.Scan(); line++ {
+		if !pattern.Match(scanner.Bytes()) {
+			continue
+		}
+		if err := fn(GrepMatch{Path: remotePath, Line: line, Text: scanner.Text()}); err != nil {
+			return err
+		}
+	}
+
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		c.log().Warn("Skipping file with overlong line", "path", remotePath, "max_line_length", maxLineLength)
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+// matchesExtension reports whether the file has one of the extensions, or if there are none
+func matchesExtension(remotePath string, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+	ext := path.Ext(remotePath)
+	for _, e := range extensions {
+		if strings.EqualFold(ext, "."+strings.TrimPrefix(e, ".")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package filebrowser
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestGrep(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"conf/app.yaml":   []byte("name: app\ndb_host: db1\nport: 80\n"),
+		"conf/other.YML":  []byte("db_host: db2"),
+		"conf/notes.txt":  []byte("db_host: not searched"),
+		"conf/large.yaml": bytes.Repeat([]byte("db_host: too large\n"), 100),
+		"conf/long.yaml":  append(bytes.Repeat([]byte("x"), 200), []byte("\ndb_host: db3\n")...),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	var matches []GrepMatch
+	opts := GrepOptions{Extensions: []string{".yaml", "yml"}, MaxSize: 1000, MaxLineLength: 100}
+	err := client.Grep("conf", regexp.MustCompile(`^db_host:`), opts, func(match GrepMatch) error {
+		matches = append(matches, match)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Grep() error = %v", err)
+	}
+
+	expected := []GrepMatch{
+		{Path: "/conf/app.yaml", Line: 2, Text: "db_host: db1"},
+		{Path: "/conf/other.YML", Line: 1, Text: "db_host: db2"},
+	}
+	if len(matches) != len(expected) {
+		t.Fatalf("Grep() matches = %+v, want %+v", matches, expected)
+	}
+	for i := range expected {
+		if matches[i] != expected[i] {
+			t.Errorf("match %d = %+v, want %+v", i, matches[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDangerousPath is returned when a destructive operation targets the root or a path
+// shallower than Client.MinDeleteDepth, unless Client.AllowDangerous is set
+var ErrDangerousPath = errors.New("refusing destructive operation on shallow path")
+
+// checkDestructive returns an error matching ErrDangerousPath if remotePath is too shallow
+// to be deleted or pruned by the client
+func (c *Client) checkDestructive(remotePath string) error {
+	if c.AllowDangerous {
+		return nil
+	}
+	cleaned, err := cleanRemotePath(remotePath)
+	if err != nil {
+		return err
+	}
+
+	var depth int
+	if cleaned != "" {
+		depth = strings.Count(cleaned, "/") + 1
+	}
+	minDepth := max(c.MinDeleteDepth, 1)
+	if depth < minDepth {
+		if depth == 0 {
+			return fmt.Errorf("%q is the root: %w", remotePath, ErrDangerousPath)
+		}
+		return fmt.Errorf("%q is %d levels deep, less than %d: %w", remotePath, depth, minDepth, ErrDangerousPath)
+	}
+	return nil
+}
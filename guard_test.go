@@ -0,0 +1,42 @@
+package filebrowser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDeleteGuardRails(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"tenant/a/file.txt": []byte("a"),
+		"tenant/b.txt":      []byte("b"),
+		"top.txt":           []byte("top"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}, MinDeleteDepth: 2}
+
+	for _, remotePath := range []string{"/", "//", ".", "tenant", "/tenant/", "top.txt"} {
+		if err := client.DeleteResource(remotePath); !errors.Is(err, ErrDangerousPath) {
+			t.Errorf("DeleteResource(%q) error = %v, want ErrDangerousPath", remotePath, err)
+		}
+	}
+	if _, err := client.EnforceQuota("tenant", 0, QuotaDeleteOldest); !errors.Is(err, ErrDangerousPath) {
+		t.Errorf("EnforceQuota() error = %v, want ErrDangerousPath", err)
+	}
+	if _, ok := server.file("top.txt"); !ok {
+		t.Fatal("top.txt was deleted")
+	}
+
+	if err := client.DeleteResource("tenant/a"); err != nil {
+		t.Fatalf("DeleteResource() at the minimum depth error = %v", err)
+	}
+	if _, ok := server.file("tenant/a/file.txt"); ok {
+		t.Error("tenant/a was not deleted")
+	}
+
+	client.AllowDangerous = true
+	if err := client.DeleteResource("top.txt"); err != nil {
+		t.Fatalf("DeleteResource() with AllowDangerous error = %v", err)
+	}
+	if _, ok := server.file("top.txt"); ok {
+		t.Error("top.txt was not deleted")
+	}
+}
@@ -0,0 +1,66 @@
+package filebrowser
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/imroc/req/v3"
+)
+
+// headerContextKey is the context key under which WithHeader stores request headers
+type headerContextKey struct{}
+
+// WithHeader returns a copy of ctx carrying an additional header that is sent with every
+// request made by SDK calls using that context, such as trace or tenant IDs for auditing
+// proxies. Headers added to a context never override the authentication header.
+func WithHeader(ctx context.Context, key, value string) context.Context {
+	header := headersFromContext(ctx).Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Add(key, value)
+	return context.WithValue(ctx, headerContextKey{}, header)
+}
+
+// headersFromContext returns the headers attached to ctx by WithHeader
+func headersFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(headerContextKey{}).(http.Header)
+	return header
+}
+
+// SourceAuth authenticates the requests for an external URL, for files served by APIs
+// that refuse anonymous requests. Net/http drops the Authorization header and cookies on
+// redirects to another host, Header is sent to every host.
+type SourceAuth struct {
+	// Header is sent with every request, e.g. an API key
+	Header http.Header
+	// BearerToken, if set, is sent as bearer token in the Authorization header
+	BearerToken string
+	// Username and Password, if Username is set, are sent with basic authentication
+	Username string
+	Password string
+	// Cookies are sent with every request, e.g. a session cookie
+	Cookies []*http.Cookie
+}
+
+// apply adds the credentials to r
+func (a *SourceAuth) apply(r *req.Request) {
+	if a == nil {
+		return
+	}
+	if r.Headers == nil {
+		r.Headers = http.Header{}
+	}
+	for key, values := range a.Header {
+		for _, value := range values {
+			r.Headers.Add(key, value)
+		}
+	}
+	if a.BearerToken != "" {
+		r.SetBearerAuthToken(a.BearerToken)
+	}
+	if a.Username != "" {
+		r.SetBasicAuth(a.Username, a.Password)
+	}
+	r.SetCookies(a.Cookies...)
+}
@@ -0,0 +1,127 @@
+package filebrowser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithHeader(t *testing.T) {
+	ctx := WithHeader(context.Background(), "X-Trace-Id", "trace-1")
+	child := WithHeader(ctx, "X-Tenant-Id", "tenant-1")
+
+	if got := headersFromContext(ctx); len(got) != 1 {
+		t.Errorf("parent context headers = %v, want only X-Trace-Id", got)
+	}
+	got := headersFromContext(child)
+	if got.Get("X-Trace-Id") != "trace-1" || got.Get("X-Tenant-Id") != "tenant-1" {
+		t.Errorf("child context headers = %v", got)
+	}
+}
+
+func TestWithHeaderSentWithRequests(t *testing.T) {
+	received := map[string]http.Header{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login":
+			w.Write([]byte("test-token"))
+		default:
+			received[r.Method] = r.Header.Clone()
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("{}"))
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	ctx := WithHeader(context.Background(), "X-Tenant-Id", "tenant-1")
+	ctx = WithHeader(ctx, "X-Auth", "spoofed")
+
+	if _, err := client.GetResourceContext(ctx, "file.txt"); err != nil {
+		t.Fatalf("GetResourceContext() error = %v", err)
+	}
+	if err := client.DeleteResourceContext(context.Background(), "file.txt"); err != nil {
+		t.Fatalf("DeleteResourceContext() error = %v", err)
+	}
+
+	if got := received[http.MethodGet]; got.Get("X-Tenant-Id") != "tenant-1" || got.Get("X-Auth") != "test-token" {
+		t.Errorf("GET headers = %v, want tenant header and client token", got)
+	}
+	if got := received[http.MethodDelete]; got.Get("X-Tenant-Id") != "" {
+		t.Errorf("DELETE headers = %v, want no tenant header", got)
+	}
+}
+
+func TestWithHeaderSentWithUploads(t *testing.T) {
+	localPath, _ := writeTestFile(t, 1024)
+
+	var tenants []string
+	tusServer := &tusTestServer{corruptFrom: -1}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/login" {
+			tenants = append(tenants, r.Header.Get("X-Tenant-Id"))
+		}
+		tusServer.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	ctx := WithHeader(context.Background(), "X-Tenant-Id", "tenant-1")
+	if _, err := client.UploadWithOptionsContext(ctx, localPath, "dir/upload.bin", UploadOptions{}); err != nil {
+		t.Fatalf("UploadWithOptionsContext() error = %v", err)
+	}
+
+	if len(tenants) == 0 {
+		t.Fatal("no TUS requests received")
+	}
+	for i, tenant := range tenants {
+		if tenant != "tenant-1" {
+			t.Errorf("TUS request %d tenant header = %q, want tenant-1", i, tenant)
+		}
+	}
+}
+
+func TestSourceAuth(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, basic := r.BasicAuth()
+		session, _ := r.Cookie("session")
+		switch {
+		case r.URL.Path == "/bearer.txt" && r.Header.Get("Authorization") == "Bearer api-token":
+		case r.URL.Path == "/basic.txt" && basic && user == "reader" && password == "secret":
+		case r.URL.Path == "/cookie.txt" && session != nil && session.Value == "s1" && r.Header.Get("X-Api-Key") == "key":
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("protected"))
+	}))
+	defer origin.Close()
+
+	dir := t.TempDir()
+	for name, auth := range map[string]*SourceAuth{
+		"bearer.txt": {BearerToken: "api-token"},
+		"basic.txt":  {Username: "reader", Password: "secret"},
+		"cookie.txt": {Header: http.Header{"X-Api-Key": {"key"}}, Cookies: []*http.Cookie{{Name: "session", Value: "s1"}}},
+	} {
+		if _, err := DownloadToLocalWithOptions(origin.URL+"/"+name, 0, DownloadOptions{Dir: dir}); err == nil {
+			t.Errorf("download of %s without credentials succeeded", name)
+		}
+		if _, err := DownloadToLocalWithOptions(origin.URL+"/"+name, 0, DownloadOptions{Dir: dir, Auth: auth}); err != nil {
+			t.Errorf("download of %s error = %v", name, err)
+		}
+	}
+
+	// SaveAndShare passes the credentials to the download
+	server := newMemServer(t, nil)
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	params := ActionParams{URLPolicy: localOrigin, SourceAuth: &SourceAuth{BearerToken: "api-token"}}
+	if _, err := SaveAndShare(auth, origin.URL+"/bearer.txt", func(name string) string { return "in/" + name }, params); err != nil {
+		t.Fatalf("SaveAndShare() error = %v", err)
+	}
+	if content, _ := server.file("in/bearer.txt"); string(content) != "protected" {
+		t.Errorf("uploaded content = %q", content)
+	}
+}
@@ -0,0 +1,87 @@
+package filebrowser
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+// HedgePolicy hedges latency-sensitive reads: if a request gets no response within Delay,
+// the same request is sent once more and the first usable response is taken, cutting
+// latency spikes of interactive UIs at the cost of some duplicate requests
+type HedgePolicy struct {
+	// Delay is how long the first request may take before the second one is sent, e.g. the
+	// 95th percentile latency. Hedging is disabled if it is not positive.
+	Delay time.Duration
+	// Replicas are the base URLs of other instances serving the same files and accepting
+	// the same tokens. Second requests go to them in turn, or to the client's URL if empty.
+	Replicas []string
+}
+
+// hedgeOutcome is the outcome of one of the requests of hedged
+type hedgeOutcome struct {
+	resp *req.Response
+	err  error
+}
+
+// usable reports whether the outcome is an answer, not a failure of the server or network
+func (o hedgeOutcome) usable() bool {
+	return o.err == nil && o.resp.StatusCode < http.StatusInternalServerError
+}
+
+// hedged sends the request to url with send and, following the hedge policy of the client,
+// a second one if the first gets no response in time, returning the first usable
+// response. The other request is cancelled. A request failing before the second one is
+// sent fails the call, hedging is no failover.
+func (c *Client) hedged(ctx context.Context, url string, send func(ctx context.Context, url string) (*req.Response, error)) (*req.Response, error) {
+	policy := c.Hedge
+	if policy == nil || policy.Delay <= 0 {
+		return send(ctx, url)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	outcomes := make(chan hedgeOutcome, 2)
+	run := func(url string) {
+		resp, err := send(ctx, url)
+		outcomes <- hedgeOutcome{resp: resp, err: err}
+	}
+	go run(url)
+
+	timer := time.NewTimer(policy.Delay)
+	defer timer.Stop()
+	hedge := timer.C
+	var failed *hedgeOutcome
+	for pending := 1; pending > 0; {
+		select {
+		case <-hedge:
+			hedge = nil
+			pending++
+			hedgeURL := c.hedgeURL(url)
+			c.log().Debug("Request slow, sending hedged request", "url", hedgeURL, "delay", policy.Delay)
+			go run(hedgeURL)
+		case outcome := <-outcomes:
+			pending--
+			if outcome.usable() || hedge != nil {
+				return outcome.resp, outcome.err
+			}
+			if failed == nil {
+				failed = &outcome
+			}
+		}
+	}
+	return failed.resp, failed.err
+}
+
+// hedgeURL returns url for the replica the next hedged request goes to
+func (c *Client) hedgeURL(url string) string {
+	replicas := c.Hedge.Replicas
+	if len(replicas) == 0 {
+		return url
+	}
+	replica := replicas[(c.hedges.Add(1)-1)%uint64(len(replicas))]
+	return strings.TrimSuffix(replica, "/") + strings.TrimPrefix(url, c.URL)
+}
@@ -0,0 +1,55 @@
+package filebrowser
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedgedGetResource(t *testing.T) {
+	replica := newMemServer(t, map[string][]byte{"docs/a.txt": []byte("hello")})
+
+	// The primary serves the same files, but its first resource request is slow
+	var requests atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/resources/") && requests.Add(1) == 1 {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		replica.ServeHTTP(w, r)
+	}))
+	defer primary.Close()
+
+	for _, replicas := range [][]string{{replica.URL}, nil} {
+		requests.Store(0)
+		client := &Client{
+			URL:      primary.URL,
+			ReqLogin: ReqLogin{Username: "user", Password: "pass"},
+			Hedge:    &HedgePolicy{Delay: 20 * time.Millisecond, Replicas: replicas},
+		}
+		begin := time.Now()
+		resource, err := client.GetResource("docs/a.txt")
+		if err != nil {
+			t.Fatalf("GetResource() with replicas %v error = %v", replicas, err)
+		}
+		if resource.Size != 5 || time.Since(begin) > 2*time.Second {
+			t.Errorf("GetResource() with replicas %v = %+v after %v, want the hedged response", replicas, resource, time.Since(begin))
+		}
+	}
+
+	// Missing files are answers, not failures to hedge
+	requests.Store(1)
+	client := &Client{URL: primary.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}, Hedge: &HedgePolicy{Delay: time.Hour}}
+	if _, err := client.GetResource("missing.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetResource() of missing file error = %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// PreUploadHook is consulted before an upload to remotePath and rejects it by returning an
+// error, so quotas, naming conventions and business rules can be enforced in one place.
+// localInfo describes the local file, it is nil for streams of unknown length.
+type PreUploadHook func(ctx context.Context, localInfo fs.FileInfo, remotePath string) error
+
+// checkUpload consults the client's PreUploadHook for an upload
+func (c *Client) checkUpload(ctx context.Context, localInfo fs.FileInfo, remotePath string) error {
+	if c.PreUploadHook == nil {
+		return nil
+	}
+	if err := c.PreUploadHook(ctx, localInfo, remotePath); err != nil {
+		return fmt.Errorf("upload to %s rejected: %w", remotePath, err)
+	}
+	return nil
+}
+
+// QuotaHook returns a PreUploadHook rejecting uploads below root that would make it exceed
+// maxBytes with an error matching ErrQuotaExceeded, see CheckQuota. Streams are counted
+// with a size of zero.
+func (c *Client) QuotaHook(root string, maxBytes int64) PreUploadHook {
+	prefix := strings.TrimPrefix(manifestPrefix(root), "/")
+	return func(ctx context.Context, localInfo fs.FileInfo, remotePath string) error {
+		if !strings.HasPrefix(strings.TrimPrefix(remotePath, "/"), prefix) {
+			return nil
+		}
+
+		var incoming int64
+		if localInfo != nil {
+			incoming = localInfo.Size()
+		}
+		return c.CheckQuota(root, maxBytes, incoming)
+	}
+}
+
+// OpResult describes a completed mutating operation: an upload, share, deletion, move
+// or copy. Paths are the ones passed to the operation.
+type OpResult struct {
+	Operation   Operation
+	Path        string
+	Destination string        // Destination of a move or copy
+	Bytes       int64         // Bytes uploaded
+	ShareHash   string        // Hash of the created share
+	Duration    time.Duration // Wall time of the whole operation
+	Err         error         // Error the operation finished with, if any
+	Attempts    []AttemptInfo // HTTP attempts of the operation, if Client.Verbose is set
+}
+
+// PostOpHook is called after every mutating operation, successful or not, enabling cache
+// busting, CDN purges and notifications from one place. It runs synchronously, so slow
+// work should be handed off.
+type PostOpHook func(op OpResult)
+
+// postOp passes the result of a mutating operation started at start to the PostOpHook
+func (c *Client) postOp(result OpResult, start time.Time) {
+	if c.PostOpHook == nil {
+		return
+	}
+	result.Duration = time.Since(start)
+	c.PostOpHook(result)
+}
@@ -0,0 +1,148 @@
+package filebrowser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreUploadHook(t *testing.T) {
+	server := newMemServer(t, nil)
+	errNaming := errors.New("names must be lower case")
+
+	var calls []string
+	var sizes []int64
+	client := &Client{
+		URL:      server.URL,
+		ReqLogin: ReqLogin{Username: "user", Password: "pass"},
+		PreUploadHook: func(ctx context.Context, localInfo fs.FileInfo, remotePath string) error {
+			calls = append(calls, remotePath)
+			size := int64(-1)
+			if localInfo != nil {
+				size = localInfo.Size()
+			}
+			sizes = append(sizes, size)
+			if remotePath == "docs/README.txt" {
+				return errNaming
+			}
+			return nil
+		},
+	}
+
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Upload(localPath, "docs/README.txt"); !errors.Is(err, errNaming) {
+		t.Errorf("Upload() error = %v, want hook error", err)
+	}
+	if _, ok := server.file("docs/README.txt"); ok {
+		t.Error("rejected upload reached the server")
+	}
+	if _, err := client.Upload(localPath, "docs/readme.txt"); err != nil {
+		t.Errorf("Upload() error = %v", err)
+	}
+
+	if len(calls) != 2 || sizes[0] != 7 || sizes[1] != 7 {
+		t.Errorf("hook called for %v with sizes %v", calls, sizes)
+	}
+}
+
+func TestPreUploadHookSpooledStream(t *testing.T) {
+	server := httptest.NewServer(&tusTestServer{corruptFrom: -1})
+	defer server.Close()
+
+	var infos []fs.FileInfo
+	client := &Client{
+		URL:      server.URL,
+		ReqLogin: ReqLogin{Username: "user", Password: "pass"},
+		PreUploadHook: func(ctx context.Context, localInfo fs.FileInfo, remotePath string) error {
+			infos = append(infos, localInfo)
+			return nil
+		},
+	}
+
+	if err := client.UploadStream(bytes.NewReader([]byte("stream")), "stream.bin"); err != nil {
+		t.Fatalf("UploadStream() error = %v", err)
+	}
+	if len(infos) != 1 || infos[0] != nil {
+		t.Errorf("hook called with %v, want once without file info", infos)
+	}
+}
+
+func TestQuotaHook(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"tenant/a.bin": make([]byte, 60)})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	client.PreUploadHook = client.QuotaHook("tenant", 100)
+
+	localPath := filepath.Join(t.TempDir(), "b.bin")
+	if err := os.WriteFile(localPath, make([]byte, 50), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Upload(localPath, "tenant/b.bin"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Upload() error = %v, want ErrQuotaExceeded", err)
+	}
+	if _, err := client.Upload(localPath, "other/b.bin"); err != nil {
+		t.Errorf("Upload() outside the quota root error = %v", err)
+	}
+}
+
+func TestPostOpHook(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("a")})
+
+	var results []OpResult
+	client := &Client{
+		URL:        server.URL,
+		ReqLogin:   ReqLogin{Username: "user", Password: "pass"},
+		PostOpHook: func(op OpResult) { results = append(results, op) },
+	}
+
+	localPath := filepath.Join(t.TempDir(), "b.txt")
+	if err := os.WriteFile(localPath, []byte("bravo"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Upload(localPath, "b.txt"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if err := client.Copy("b.txt", "c.txt", false); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if err := client.Move("a.txt", "c.txt", false); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("Move() error = %v, want ErrAlreadyExists", err)
+	}
+	hash, err := client.Share("c.txt", 0, "", "")
+	if err != nil {
+		t.Fatalf("Share() error = %v", err)
+	}
+	if err := client.DeleteResource("b.txt"); err != nil {
+		t.Fatalf("DeleteResource() error = %v", err)
+	}
+	if _, err := client.GetResource("c.txt"); err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+
+	expected := []OpResult{
+		{Operation: OpUpload, Path: "b.txt", Bytes: 5},
+		{Operation: OpCopyResource, Path: "b.txt", Destination: "c.txt"},
+		{Operation: OpMoveResource, Path: "a.txt", Destination: "c.txt"},
+		{Operation: OpShare, Path: "c.txt", ShareHash: hash},
+		{Operation: OpDeleteResource, Path: "b.txt"},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("hook called with %+v, want %d mutating operations", results, len(expected))
+	}
+	for i, want := range expected {
+		got := results[i]
+		if got.Operation != want.Operation || got.Path != want.Path || got.Destination != want.Destination ||
+			got.Bytes != want.Bytes || got.ShareHash != want.ShareHash || (got.Err != nil) != (want.Operation == OpMoveResource) {
+			t.Errorf("result %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
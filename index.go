@@ -0,0 +1,349 @@
+package filebrowser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// defaultIndexMaxSize is the largest file indexed when Indexer.MaxSize is zero
+const defaultIndexMaxSize = 10 * 1024 * 1024
+
+// TextExtractor extracts searchable text from the content of a file
+type TextExtractor func(r io.Reader) (string, error)
+
+// PlainTextExtractor indexes the content of a file as is
+func PlainTextExtractor(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DefaultExtractors are used by an Indexer without extractors. Other formats, such as PDF,
+// are indexed by registering an extractor for their extension.
+var DefaultExtractors = map[string]TextExtractor{
+	".txt":      PlainTextExtractor,
+	".md":       PlainTextExtractor,
+	".markdown": PlainTextExtractor,
+}
+
+// TextIndex is a full-text index of remote files. Any search engine can be plugged in by
+// implementing it; MemoryIndex and its persistent FileIndex are simple built-in
+// implementations, and the bleveindex module provides one backed by bleve. Implementations
+// must be safe for concurrent use. If they also implement Flush() error, Indexer.Sync
+// calls it when it finishes.
+type TextIndex interface {
+	// Index adds or replaces the text of a file. The version identifies the indexed
+	// state of the file, so unchanged files can be skipped.
+	Index(path string, version string, text string) error
+	// Delete removes a file, it is not an error if the file is unknown
+	Delete(path string) error
+	// Versions returns the version of every indexed file keyed by path
+	Versions() (map[string]string, error)
+	// Query returns the paths of the files matching q
+	Query(q string) ([]string, error)
+}
+
+// IndexReport summarizes an Indexer.Sync run
+type IndexReport struct {
+	Indexed   int // Files added or updated
+	Unchanged int // Files already indexed in their current version
+	Removed   int // Files removed from the index as they no longer exist
+	Skipped   int // Files without extractor or larger than the size limit
+}
+
+// Indexer maintains a local full-text index of the files below a remote root
+type Indexer struct {
+	Client *Client
+	Index  TextIndex
+	// Extractors extract text by lower case file extension, DefaultExtractors if nil
+	Extractors map[string]TextExtractor
+	// MaxSize skips files larger than this many bytes, 10MB if zero
+	MaxSize int64
+}
+
+// Sync walks the remote tree rooted at root and brings the index up to date: new and
+// modified files are downloaded and indexed, files that no longer exist are removed
+func (i *Indexer) Sync(root string) (_ *IndexReport, err error) {
+	if flusher, ok := i.Index.(interface{ Flush() error }); ok {
+		// Keep what was indexed, even if the sync fails
+		defer func() {
+			if flushErr := flusher.Flush(); flushErr != nil && err == nil {
+				err = fmt.Errorf("failed to save index: %w", flushErr)
+			}
+		}()
+	}
+
+	extractors := i.Extractors
+	if extractors == nil {
+		extractors = DefaultExtractors
+	}
+	maxSize := i.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultIndexMaxSize
+	}
+
+	versions, err := i.Index.Versions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	report := &IndexReport{}
+	seen := map[string]bool{}
+	err = i.Client.Walk(root, func(resource *RespResource) error {
+		if resource.IsDir {
+			return nil
+		}
+		extract, ok := extractors[strings.ToLower(path.Ext(resource.Path))]
+		if !ok || resource.Size > maxSize {
+			report.Skipped++
+			return nil
+		}
+
+		seen[resource.Path] = true
+		version := fmt.Sprintf("%s/%d", resource.Modified.Format(time.RFC3339Nano), resource.Size)
+		if versions[resource.Path] == version {
+			report.Unchanged++
+			return nil
+		}
+
+		text, err := i.extract(resource.Path, extract)
+		if errors.Is(err, ErrNotFound) {
+			// Removed since it was listed
+			delete(seen, resource.Path)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := i.Index.Index(resource.Path, version, text); err != nil {
+			return fmt.Errorf("failed to index %s: %w", resource.Path, err)
+		}
+		report.Indexed++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := manifestPrefix(root)
+	for indexedPath := range versions {
+		if seen[indexedPath] || !strings.HasPrefix(indexedPath, prefix) {
+			continue
+		}
+		if err := i.Index.Delete(indexedPath); err != nil {
+			return nil, fmt.Errorf("failed to remove %s from index: %w", indexedPath, err)
+		}
+		report.Removed++
+	}
+
+	return report, nil
+}
+
+// Query returns the remote paths of the indexed files matching q
+func (i *Indexer) Query(q string) ([]string, error) {
+	return i.Index.Query(q)
+}
+
+// extract downloads a remote file and extracts its text
+func (i *Indexer) extract(remotePath string, extract TextExtractor) (string, error) {
+	body, _, err := i.Client.openRaw(context.Background(), resourcePath(remotePath))
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	text, err := extract(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract text from %s: %w", remotePath, err)
+	}
+	return text, nil
+}
+
+// indexedDocument is a file stored in a MemoryIndex
+type indexedDocument struct {
+	Version string   `json:"version"`
+	Terms   []string `json:"terms"`
+}
+
+// MemoryIndex is a TextIndex kept in memory, matching files containing every word of a
+// query regardless of case. It can be persisted with WriteTo and ReadMemoryIndex.
+type MemoryIndex struct {
+	mu        sync.RWMutex
+	documents map[string]indexedDocument
+	postings  map[string]map[string]bool // paths by term
+}
+
+// NewMemoryIndex creates an empty MemoryIndex
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{documents: map[string]indexedDocument{}, postings: map[string]map[string]bool{}}
+}
+
+// ReadMemoryIndex loads a MemoryIndex previously written with WriteTo
+func ReadMemoryIndex(r io.Reader) (*MemoryIndex, error) {
+	documents := map[string]indexedDocument{}
+	if err := json.NewDecoder(r).Decode(&documents); err != nil {
+		return nil, fmt.Errorf("failed to decode index: %w", err)
+	}
+
+	index := NewMemoryIndex()
+	for docPath, doc := range documents {
+		index.add(docPath, doc)
+	}
+	return index, nil
+}
+
+// WriteTo writes the index to w so it can be loaded with ReadMemoryIndex
+func (m *MemoryIndex) WriteTo(w io.Writer) (int64, error) {
+	m.mu.RLock()
+	data, err := json.Marshal(m.documents)
+	m.mu.RUnlock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode index: %w", err)
+	}
+
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Index adds or replaces the text of a file
+func (m *MemoryIndex) Index(path string, version string, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(path)
+	m.add(path, indexedDocument{Version: version, Terms: indexTerms(text)})
+	return nil
+}
+
+// Delete removes a file from the index
+func (m *MemoryIndex) Delete(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(path)
+	return nil
+}
+
+// Versions returns the version of every indexed file keyed by path
+func (m *MemoryIndex) Versions() (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	versions := make(map[string]string, len(m.documents))
+	for docPath, doc := range m.documents {
+		versions[docPath] = doc.Version
+	}
+	return versions, nil
+}
+
+// Query returns the sorted paths of the files containing every word of q
+func (m *MemoryIndex) Query(q string) ([]string, error) {
+	terms := indexTerms(q)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matches []string
+	for docPath := range m.postings[terms[0]] {
+		all := true
+		for _, term := range terms[1:] {
+			all = all && m.postings[term][docPath]
+		}
+		if all {
+			matches = append(matches, docPath)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// add stores a document and its postings, the caller must hold the write lock
+func (m *MemoryIndex) add(path string, doc indexedDocument) {
+	m.documents[path] = doc
+	for _, term := range doc.Terms {
+		if m.postings[term] == nil {
+			m.postings[term] = map[string]bool{}
+		}
+		m.postings[term][path] = true
+	}
+}
+
+// remove deletes a document and its postings, the caller must hold the write lock
+func (m *MemoryIndex) remove(path string) {
+	for _, term := range m.documents[path].Terms {
+		delete(m.postings[term], path)
+		if len(m.postings[term]) == 0 {
+			delete(m.postings, term)
+		}
+	}
+	delete(m.documents, path)
+}
+
+// FileIndex is a MemoryIndex persisted to a local file, keeping the index across runs.
+// Changes are saved by Flush, which Indexer.Sync calls when it finishes.
+type FileIndex struct {
+	*MemoryIndex
+	path string
+}
+
+// OpenFileIndex loads the index stored at path, or creates an empty one if the file does
+// not exist yet
+func OpenFileIndex(path string) (*FileIndex, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &FileIndex{MemoryIndex: NewMemoryIndex(), path: path}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index: %w", err)
+	}
+	defer f.Close()
+
+	index, err := ReadMemoryIndex(f)
+	if err != nil {
+		return nil, err
+	}
+	return &FileIndex{MemoryIndex: index, path: path}, nil
+}
+
+// Flush writes the index to its file, replacing it atomically
+func (f *FileIndex) Flush() error {
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(f.path, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// indexTerms splits text into its distinct lower case words
+func indexTerms(text string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,107 @@
+package filebrowser
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndexer(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"docs/guide.md":   []byte("# Deploying\nRun the Deploy script on staging."),
+		"docs/notes.txt":  []byte("Staging credentials rotate weekly."),
+		"docs/report.pdf": []byte("%PDF binary deploy"),
+		"docs/image.png":  []byte("deploy"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	index := NewMemoryIndex()
+	indexer := &Indexer{Client: client, Index: index, Extractors: map[string]TextExtractor{
+		".md":  PlainTextExtractor,
+		".txt": PlainTextExtractor,
+		".pdf": func(r io.Reader) (string, error) { return "quarterly deploy report", nil },
+	}}
+
+	report, err := indexer.Sync("docs")
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if *report != (IndexReport{Indexed: 3, Skipped: 1}) {
+		t.Errorf("Sync() report = %+v", report)
+	}
+
+	assertQuery := func(q string, want ...string) {
+		t.Helper()
+		got, err := indexer.Query(q)
+		if err != nil {
+			t.Fatalf("Query(%q) error = %v", q, err)
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("Query(%q) = %v, want %v", q, got, want)
+		}
+	}
+	assertQuery("deploy", "/docs/guide.md", "/docs/report.pdf")
+	assertQuery("STAGING", "/docs/guide.md", "/docs/notes.txt")
+	assertQuery("deploy staging", "/docs/guide.md")
+	assertQuery("missing")
+
+	// Modify one file and remove another
+	server.mu.Lock()
+	server.files["docs/notes.txt"] = testFile{content: []byte("Production only"), modified: time.Now().Add(time.Hour)}
+	delete(server.files, "docs/guide.md")
+	server.mu.Unlock()
+
+	if report, err = indexer.Sync("docs"); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if *report != (IndexReport{Indexed: 1, Unchanged: 1, Removed: 1, Skipped: 1}) {
+		t.Errorf("second Sync() report = %+v", report)
+	}
+	assertQuery("staging")
+	assertQuery("production", "/docs/notes.txt")
+
+	// Persist and reload the index
+	var buf bytes.Buffer
+	if _, err := index.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	loaded, err := ReadMemoryIndex(&buf)
+	if err != nil {
+		t.Fatalf("ReadMemoryIndex() error = %v", err)
+	}
+	if got, _ := loaded.Query("deploy report"); len(got) != 1 || got[0] != "/docs/report.pdf" {
+		t.Errorf("reloaded Query() = %v, want /docs/report.pdf", got)
+	}
+}
+
+func TestFileIndex(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"docs/guide.md": []byte("Run the deploy script"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	indexPath := filepath.Join(t.TempDir(), "index", "docs.json")
+
+	index, err := OpenFileIndex(indexPath)
+	if err != nil {
+		t.Fatalf("OpenFileIndex() error = %v", err)
+	}
+	if _, err := (&Indexer{Client: client, Index: index}).Sync("docs"); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	// A later run finds the index saved by Sync
+	reopened, err := OpenFileIndex(indexPath)
+	if err != nil {
+		t.Fatalf("OpenFileIndex() error = %v", err)
+	}
+	if got, _ := reopened.Query("deploy"); len(got) != 1 || got[0] != "/docs/guide.md" {
+		t.Errorf("reopened Query() = %v, want /docs/guide.md", got)
+	}
+	report, err := (&Indexer{Client: client, Index: reopened}).Sync("docs")
+	if err != nil || *report != (IndexReport{Unchanged: 1}) {
+		t.Errorf("Sync() of the reopened index = %+v, %v, want the file unchanged", report, err)
+	}
+}
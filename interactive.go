@@ -0,0 +1,221 @@
+package filebrowser
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// InteractiveLogin configures LoginInteractive
+type InteractiveLogin struct {
+	// In is read for the pasted token, os.Stdin if nil. Reads of files other than on
+	// Windows stop once the login finishes, so no later input is consumed.
+	In io.Reader
+	// Out receives the instructions for the user, os.Stderr if nil
+	Out io.Writer
+	// OpenBrowser, if set, opens the login page, otherwise the user opens the printed URL
+	OpenBrowser func(url string) error
+	// CallbackAddr, if set, is the local address (e.g. "127.0.0.1:0") of a server accepting
+	// the token at /callback?state=&token=, so a bookmarklet on the Filebrowser page can
+	// send it. The state is a random value printed with the bookmarklet, other requests
+	// are refused.
+	CallbackAddr string
+	// Tokens, if set, stores the token for later runs and becomes the Tokens of the
+	// returned client, see Client.Tokens
+	Tokens TokenStore
+}
+
+// LoginInteractive lets a user log in to the Filebrowser server at url in the browser and
+// hand the token over, so no password needs to be embedded in automation. It opens the
+// login page and waits for the token to be pasted to In or sent to the local callback,
+// then returns a client authenticated with it. The client cannot log in with a password,
+// so once the token expires it needs a newer one stored in Tokens, e.g. by another login.
+func LoginInteractive(ctx context.Context, url string, opts InteractiveLogin) (*Client, error) {
+	if url == "" {
+		return nil, fmt.Errorf("URL cannot be empty")
+	}
+	url = strings.TrimSuffix(url, "/")
+	in, out := opts.In, opts.Out
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stderr
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tokens := make(chan string, 1)
+	if file, ok := in.(*os.File); ok {
+		in = contextReader{ctx: ctx, file: file}
+	}
+
+	loginURL := url + "/login"
+	fmt.Fprintf(out, "Log in at %s, then paste the token (the \"jwt\" entry of the page's local storage) here.\n", loginURL)
+
+	if opts.CallbackAddr != "" {
+		callbackURL, err := serveTokenCallback(ctx, opts.CallbackAddr, tokens)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(out, "Or, once logged in, run this bookmarklet on the Filebrowser page:\n"+
+			"javascript:location='%s&token='+encodeURIComponent(localStorage.getItem('jwt'))\n", callbackURL)
+	}
+
+	if opts.OpenBrowser != nil {
+		if err := opts.OpenBrowser(loginURL); err != nil {
+			fmt.Fprintf(out, "Could not open the browser (%v), open the URL manually.\n", err)
+		}
+	}
+
+	pasted, reading := make(chan error, 1), make(chan struct{})
+	go func() {
+		defer close(reading)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			if token := strings.TrimSpace(scanner.Text()); token != "" {
+				select {
+				case tokens <- token:
+				default:
+				}
+				return
+			}
+		}
+		pasted <- errors.Join(io.ErrUnexpectedEOF, scanner.Err())
+	}()
+	defer func() {
+		// Wait for the read to stop, unless it blocks until input arrives
+		cancel()
+		if _, ok := in.(contextReader); ok && pollReadable {
+			<-reading
+		}
+	}()
+
+	var token string
+	for token == "" {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("interactive login aborted: %w", ctx.Err())
+		case token = <-tokens:
+		case err := <-pasted:
+			// Without a callback nothing else can deliver the token
+			if opts.CallbackAddr == "" {
+				return nil, fmt.Errorf("failed to read token: %w", err)
+			}
+		}
+	}
+
+	username, err := tokenUsername(token)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := tokenExpiry(token)
+	if (StoredToken{ExpiresAt: expiresAt}).expired(time.Now()) {
+		return nil, fmt.Errorf("token is expired")
+	}
+
+	client := &Client{URL: url, ReqLogin: ReqLogin{Username: username}, Token: token, Tokens: opts.Tokens}
+	if _, err := client.lookupResource("/"); err != nil {
+		return nil, fmt.Errorf("token rejected: %w", err)
+	}
+
+	if opts.Tokens != nil {
+		stored := StoredToken{URL: url, Username: username, Token: token, ExpiresAt: expiresAt}
+		if err := opts.Tokens.Save(stored); err != nil {
+			return nil, fmt.Errorf("failed to store token: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// serveTokenCallback serves the local callback receiving the token until ctx is done and
+// returns its URL, including the state it must be called with
+func serveTokenCallback(ctx context.Context, addr string, tokens chan<- string) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate callback state: %w", err)
+	}
+	state := hex.EncodeToString(nonce)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen for the login callback: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		// Only the printed bookmarklet knows the state, not other pages or local processes
+		if subtle.ConstantTimeCompare([]byte(r.FormValue("state")), []byte(state)) != 1 {
+			http.Error(w, "Invalid login state, use the bookmarklet printed by the login.", http.StatusForbidden)
+			return
+		}
+		token := strings.TrimSpace(r.FormValue("token"))
+		if token == "" || token == "null" {
+			http.Error(w, "No token received, log in to Filebrowser first.", http.StatusBadRequest)
+			return
+		}
+		select {
+		case tokens <- token:
+		default:
+		}
+		io.WriteString(w, "Logged in, you can close this page.\n")
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	return "http://" + listener.Addr().String() + "/callback?state=" + state, nil
+}
+
+// contextReader reads a file, failing once ctx is done instead of waiting for input
+type contextReader struct {
+	ctx  context.Context
+	file *os.File
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := waitReadable(r.ctx, r.file); err != nil {
+		return 0, err
+	}
+	return r.file.Read(p)
+}
+
+// tokenUsername returns the user a Filebrowser JWT was issued to
+func tokenUsername(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("token is not a Filebrowser JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("failed to decode token: %w", err)
+	}
+
+	var claims struct {
+		User struct {
+			Username string `json:"username"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("failed to decode token: %w", err)
+	}
+	if claims.User.Username == "" {
+		return "", fmt.Errorf("token names no user")
+	}
+	return claims.User.Username, nil
+}
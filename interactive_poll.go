@@ -0,0 +1,48 @@
+//go:build linux || darwin || freebsd
+
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// pollReadable reports whether waitReadable can stop waiting for input once its context is
+// done
+const pollReadable = true
+
+// pollInterval is how often waitReadable checks whether its context is done
+const pollInterval = 100 * time.Millisecond
+
+// waitReadable waits until a read of file does not block, failing with the error of ctx once
+// it is done. Errors of polling are left to the read.
+func waitReadable(ctx context.Context, file *os.File) error {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return nil
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var ready bool
+		var pollErr error
+		err := conn.Control(func(fd uintptr) {
+			fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+			var n int
+			n, pollErr = unix.Poll(fds, int(pollInterval/time.Millisecond))
+			ready = n > 0
+		})
+		switch {
+		case err != nil:
+			return nil
+		case errors.Is(pollErr, unix.EINTR):
+		case pollErr != nil || ready:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !freebsd
+
+package filebrowser
+
+import (
+	"context"
+	"os"
+)
+
+// The system cannot poll files, so reads of them block until input arrives
+const pollReadable = false
+
+// waitReadable returns immediately, reads of file block until input arrives
+func waitReadable(ctx context.Context, file *os.File) error {
+	return nil
+}
@@ -0,0 +1,126 @@
+package filebrowser
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testUserJWT returns an unsigned Filebrowser JWT of username expiring at exp
+func testUserJWT(username string, exp time.Time) string {
+	claims := fmt.Sprintf(`{"user":{"id":1,"username":%q},"exp":%d}`, username, exp.Unix())
+	return "header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+}
+
+func TestLoginInteractivePaste(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("content")})
+	token := testUserJWT("alice", time.Now().Add(time.Hour))
+
+	var opened string
+	var out strings.Builder
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	client, err := LoginInteractive(context.Background(), server.URL+"/", InteractiveLogin{
+		In:          strings.NewReader("\n" + token + "\n"),
+		Out:         &out,
+		OpenBrowser: func(url string) error { opened = url; return nil },
+		Tokens:      store,
+	})
+	if err != nil {
+		t.Fatalf("LoginInteractive() error = %v", err)
+	}
+	if opened != server.URL+"/login" {
+		t.Errorf("opened %q, want the login page", opened)
+	}
+	if client.Username != "alice" || client.Token != token || client.Password != "" || client.Tokens != store {
+		t.Errorf("client = %q, %q, %q, %v, want alice with the token and store and no password", client.Username, client.Token, client.Password, client.Tokens)
+	}
+	if _, err := client.GetResource("a.txt"); err != nil {
+		t.Errorf("GetResource() error = %v", err)
+	}
+	if stored, ok, err := store.Load(server.URL, "alice"); err != nil || !ok || stored.Token != token {
+		t.Errorf("Load() = %+v, %v, %v, want the token", stored, ok, err)
+	}
+
+	// Expired and malformed tokens are refused
+	for _, pasted := range []string{testUserJWT("alice", time.Now().Add(-time.Hour)), "not-a-token", ""} {
+		_, err := LoginInteractive(context.Background(), server.URL, InteractiveLogin{In: strings.NewReader(pasted), Out: io.Discard})
+		if err == nil {
+			t.Errorf("LoginInteractive(%q) succeeded, want error", pasted)
+		}
+	}
+}
+
+func TestLoginInteractiveCallback(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("content")})
+	token := testUserJWT("bob", time.Now().Add(time.Hour))
+
+	// Nothing is pasted, the token arrives through the callback of the printed bookmarklet
+	stdin, input, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	defer input.Close()
+	instructions, out := io.Pipe()
+	defer instructions.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	forged := make(chan int, 1)
+	go func() {
+		scanner := bufio.NewScanner(instructions)
+		for scanner.Scan() {
+			bookmarklet, ok := strings.CutPrefix(scanner.Text(), "javascript:location='")
+			if !ok {
+				continue
+			}
+			// The bookmarklet appends the token to the callback URL
+			callbackURL, _, _ := strings.Cut(bookmarklet, "'")
+			forgedURL, _ := url.Parse(callbackURL)
+			forgedURL.RawQuery = "state=guessed&token="
+			if resp, err := http.Get(forgedURL.String() + url.QueryEscape(token)); err == nil {
+				resp.Body.Close()
+				forged <- resp.StatusCode
+			}
+			if resp, err := http.Get(callbackURL + url.QueryEscape(token)); err == nil {
+				resp.Body.Close()
+			}
+			break
+		}
+		io.Copy(io.Discard, instructions)
+	}()
+
+	client, err := LoginInteractive(ctx, server.URL, InteractiveLogin{In: stdin, Out: out, CallbackAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("LoginInteractive() error = %v", err)
+	}
+	if client.Username != "bob" || client.Token != token {
+		t.Errorf("client = %q, %q, want bob with the token", client.Username, client.Token)
+	}
+	select {
+	case status := <-forged:
+		if status != http.StatusForbidden {
+			t.Errorf("callback with wrong state status = %d, want %d", status, http.StatusForbidden)
+		}
+	default:
+		t.Error("callback with wrong state was not sent")
+	}
+
+	// The login stopped reading stdin, so later input is left to the program
+	if pollReadable {
+		io.WriteString(input, "later input\n")
+		stdin.SetReadDeadline(time.Now().Add(5 * time.Second))
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil || line != "later input\n" {
+			t.Errorf("later input = %q, %v, want it unread by the login", line, err)
+		}
+	}
+}
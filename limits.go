@@ -0,0 +1,119 @@
+package filebrowser
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits the requests of the client to requestsPerSecond on average, see
+// RateLimit
+func WithRateLimit(requestsPerSecond float64) Option {
+	return func(o *clientOptions) { o.middleware = append(o.middleware, RateLimit(requestsPerSecond)) }
+}
+
+// WithMaxConcurrentTransfers limits the uploads and downloads of the client in progress at
+// once, see MaxConcurrentTransfers
+func WithMaxConcurrentTransfers(n int) Option {
+	return func(o *clientOptions) { o.middleware = append(o.middleware, MaxConcurrentTransfers(n)) }
+}
+
+// RateLimit returns middleware spacing requests evenly to requestsPerSecond on average.
+// Every request counts, including logins, retries and each TUS chunk. Requests wait for
+// their turn until their context is done. Clients sharing the returned middleware share
+// the limit. A rate of zero or less is unlimited.
+func RateLimit(requestsPerSecond float64) Middleware {
+	if requestsPerSecond <= 0 {
+		return func(next Handler) Handler { return next }
+	}
+	interval := time.Duration(float64(time.Second) / requestsPerSecond)
+	var (
+		mu   sync.Mutex
+		next time.Time
+	)
+	return func(handler Handler) Handler {
+		return func(r *http.Request) (*http.Response, error) {
+			// Reserve the next free slot
+			mu.Lock()
+			now := time.Now()
+			if next.Before(now) {
+				next = now
+			}
+			due := next
+			next = next.Add(interval)
+			mu.Unlock()
+
+			if delay := time.Until(due); delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-r.Context().Done():
+					timer.Stop()
+					return nil, r.Context().Err()
+				}
+			}
+			return handler(r)
+		}
+	}
+}
+
+// MaxConcurrentTransfers returns middleware allowing at most n requests transferring file
+// content at once: TUS uploads and chunks, raw and public downloads, and resources written
+// with a body. A download keeps its slot until its body is closed. Metadata requests are
+// not limited. Clients sharing the returned middleware share the limit. Zero or less is
+// unlimited.
+func MaxConcurrentTransfers(n int) Middleware {
+	if n <= 0 {
+		return func(next Handler) Handler { return next }
+	}
+	slots := make(chan struct{}, n)
+	return func(next Handler) Handler {
+		return func(r *http.Request) (*http.Response, error) {
+			if !isTransfer(r) {
+				return next(r)
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-r.Context().Done():
+				return nil, r.Context().Err()
+			}
+			var once sync.Once
+			release := func() { once.Do(func() { <-slots }) }
+
+			resp, err := next(r)
+			if err != nil {
+				release()
+				return nil, err
+			}
+			resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		}
+	}
+}
+
+// isTransfer reports whether r uploads or downloads file content
+func isTransfer(r *http.Request) bool {
+	switch {
+	case strings.Contains(r.URL.Path, "/api/tus/"),
+		strings.Contains(r.URL.Path, "/api/raw/"),
+		strings.Contains(r.URL.Path, "/api/public/dl/"):
+		return true
+	case strings.Contains(r.URL.Path, "/api/resources/"):
+		return (r.Method == http.MethodPost || r.Method == http.MethodPut) && r.ContentLength != 0
+	}
+	return false
+}
+
+// releasingBody calls release once the response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close implements io.Closer
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,108 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("a")})
+	client, err := NewClient(server.URL, "user", "pass", WithRateLimit(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The login and five requests are spaced 50ms apart
+	start := time.Now()
+	for range 5 {
+		if _, err := client.GetResource("a.txt"); err != nil {
+			t.Fatalf("GetResource() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 240*time.Millisecond {
+		t.Errorf("6 requests at 20/s took %v, want at least 250ms", elapsed)
+	}
+
+	// A request waiting for its turn gives up with its context
+	limit := RateLimit(1)(func(r *http.Request) (*http.Response, error) { return &http.Response{}, nil })
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	if _, err := limit(r); err != nil {
+		t.Fatalf("first request error = %v", err)
+	}
+	if _, err := limit(r); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second request error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestMaxConcurrentTransfers(t *testing.T) {
+	files := map[string][]byte{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		files[name+".txt"] = []byte(name)
+	}
+	server := newMemServer(t, files)
+
+	var mu sync.Mutex
+	var inFlight, peak int
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/raw/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		next.ServeHTTP(w, r)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	})
+
+	client, err := NewClient(server.URL, "user", "pass", WithMaxConcurrentTransfers(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Login(); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, len(files))
+	for name := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- client.Download(name, io.Discard)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Download() error = %v", err)
+		}
+	}
+	if peak > 2 {
+		t.Errorf("peak concurrent downloads = %d, want at most 2", peak)
+	}
+
+	// Metadata requests are not limited
+	if !isTransfer(contentRequest(http.MethodPut, "/api/resources/a.txt", "content")) || isTransfer(contentRequest(http.MethodPost, "/api/resources/dir/", "")) {
+		t.Error("isTransfer() should only match requests with content")
+	}
+}
+
+// contentRequest creates a request to path with the given body
+func contentRequest(method string, path string, body string) *http.Request {
+	r, _ := http.NewRequest(method, "http://example.com"+path, strings.NewReader(body))
+	return r
+}
@@ -0,0 +1,49 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LockSuffix is appended to the path of a file to name its lock sentinel. Files with a
+// sentinel are being edited, e.g. by a person through the Filebrowser UI.
+const LockSuffix = ".lock"
+
+// ErrLocked is returned when a remote file has a lock sentinel
+var ErrLocked = errors.New("resource is locked")
+
+// IsLocked reports whether the lock sentinel of a remote file exists
+func (c *Client) IsLocked(remotePath string) (bool, error) {
+	resource, err := c.lookupResource(remotePath + LockSuffix)
+	if err != nil {
+		return false, fmt.Errorf("failed to check lock of %s: %w", remotePath, err)
+	}
+	return resource != nil, nil
+}
+
+// acquireLock fails with an error matching ErrLocked if remotePath has a lock sentinel,
+// otherwise it creates one and returns the function removing it. Locks are advisory: a
+// sentinel created between the check and the creation is not detected.
+func (c *Client) acquireLock(remotePath string) (func(), error) {
+	locked, err := c.IsLocked(remotePath)
+	if err != nil {
+		return nil, err
+	}
+	if locked {
+		return nil, fmt.Errorf("cannot write %s: %w", remotePath, ErrLocked)
+	}
+
+	lockPath := remotePath + LockSuffix
+	owner := fmt.Sprintf("locked by filebrowser-sdk at %s\n", time.Now().UTC().Format(time.RFC3339))
+	if err := c.UploadStream(strings.NewReader(owner), lockPath); err != nil {
+		return nil, fmt.Errorf("failed to create lock %s: %w", lockPath, err)
+	}
+
+	return func() {
+		if err := c.DeleteResource(lockPath); err != nil {
+			c.log().Warn("Failed to remove lock", "path", lockPath, "error", err)
+		}
+	}, nil
+}
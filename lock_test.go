@@ -0,0 +1,70 @@
+package filebrowser
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUploadLock(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"shared/edited.txt.lock": []byte("alice")})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	var locked []bool
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && r.URL.Path == "/api/tus/shared/free.txt" {
+			_, ok := server.file("shared/free.txt.lock")
+			locked = append(locked, ok)
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.UploadWithOptions(localPath, "shared/edited.txt", UploadOptions{Lock: true})
+	if !errors.Is(err, ErrLocked) {
+		t.Errorf("UploadWithOptions() on locked file error = %v, want ErrLocked", err)
+	}
+	if _, ok := server.file("shared/edited.txt"); ok {
+		t.Error("locked file was written")
+	}
+
+	if _, err := client.UploadWithOptions(localPath, "shared/free.txt", UploadOptions{Lock: true}); err != nil {
+		t.Fatalf("UploadWithOptions() error = %v", err)
+	}
+	if len(locked) != 1 || !locked[0] {
+		t.Error("file was not locked while uploading")
+	}
+	if _, ok := server.file("shared/free.txt.lock"); ok {
+		t.Error("lock was not removed after uploading")
+	}
+	if _, ok := server.file("shared/edited.txt.lock"); !ok {
+		t.Error("foreign lock was removed")
+	}
+}
+
+func TestAnalyzeSyncSkipLocked(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"remote/b.txt.lock": nil})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	localDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(localDir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := client.AnalyzeSyncWithOptions(localDir, "remote", SyncOptions{SkipLocked: true})
+	if err != nil {
+		t.Fatalf("AnalyzeSyncWithOptions() error = %v", err)
+	}
+	if report.TransferFiles != 1 || report.SkipFiles != 1 || report.Entries[1].Reason != SyncReasonLocked {
+		t.Errorf("AnalyzeSyncWithOptions() = %+v, want b.txt skipped as locked", report)
+	}
+}
@@ -0,0 +1,83 @@
+package filebrowser
+
+// Logger receives the log messages of the SDK. Arguments are alternating keys and values,
+// so a *slog.Logger can be used directly.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// NopLogger discards every message. It is used when no logger is configured.
+type NopLogger struct{}
+
+// Debug implements Logger
+func (NopLogger) Debug(string, ...any) {}
+
+// Info implements Logger
+func (NopLogger) Info(string, ...any) {}
+
+// Warn implements Logger
+func (NopLogger) Warn(string, ...any) {}
+
+// Error implements Logger
+func (NopLogger) Error(string, ...any) {}
+
+// redactingLogger scrubs secrets from messages and their string and error arguments
+type redactingLogger struct {
+	Logger
+	redact func(s string) string
+}
+
+// Debug implements Logger
+func (l redactingLogger) Debug(msg string, args ...any) {
+	l.Logger.Debug(l.redact(msg), l.redactArgs(args)...)
+}
+
+// Info implements Logger
+func (l redactingLogger) Info(msg string, args ...any) {
+	l.Logger.Info(l.redact(msg), l.redactArgs(args)...)
+}
+
+// Warn implements Logger
+func (l redactingLogger) Warn(msg string, args ...any) {
+	l.Logger.Warn(l.redact(msg), l.redactArgs(args)...)
+}
+
+// Error implements Logger
+func (l redactingLogger) Error(msg string, args ...any) {
+	l.Logger.Error(l.redact(msg), l.redactArgs(args)...)
+}
+
+// redactArgs returns a copy of args with strings and errors scrubbed
+func (l redactingLogger) redactArgs(args []any) []any {
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			redacted[i] = l.redact(v)
+		case error:
+			redacted[i] = l.redact(v.Error())
+		default:
+			redacted[i] = arg
+		}
+	}
+	return redacted
+}
+
+// log returns the client's logger, scrubbing the client's secrets from every message
+func (c *Client) log() Logger {
+	if c.Logger == nil {
+		return NopLogger{}
+	}
+	return redactingLogger{Logger: c.Logger, redact: c.redact}
+}
+
+// packageLog returns l scrubbing credentials from URLs, for functions without a client
+func packageLog(l Logger) Logger {
+	if l == nil {
+		return NopLogger{}
+	}
+	return redactingLogger{Logger: l, redact: func(s string) string { return scrub(s) }}
+}
@@ -0,0 +1,275 @@
+package filebrowser
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Format is the encoding of a tree manifest
+type Format string
+
+const (
+	// FormatCSV encodes a manifest as CSV with a header row
+	FormatCSV Format = "csv"
+	// FormatJSON encodes a manifest as JSON lines, one entry per line
+	FormatJSON Format = "json"
+)
+
+// manifestChecksumAlgorithm is the checksum algorithm recorded in manifests
+const manifestChecksumAlgorithm = "sha256"
+
+// manifestHeader is the header row of CSV manifests
+var manifestHeader = []string{"path", "size", "modified", "checksum"}
+
+// ManifestEntry describes a single file of a tree manifest. Paths are relative to the
+// manifest root and checksums are SHA-256.
+type ManifestEntry struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Checksum string    `json:"checksum"`
+}
+
+// ExportManifest walks the remote tree rooted at root and streams one entry per file to w,
+// in the given format. Checksums are computed by the server, one request per file.
+func (c *Client) ExportManifest(root string, w io.Writer, format Format) error {
+	_, err := c.ExportManifestWithOptions(root, w, format, WalkOptions{})
+	return err
+}
+
+// ExportManifestWithOptions exports a manifest like ExportManifest, applying the given walk
+// options. An interrupted export returns the checkpoint to resume it; the entries of the
+// resumed export are meant to be appended to the same output, so no CSV header is written.
+func (c *Client) ExportManifestWithOptions(root string, w io.Writer, format Format, opts WalkOptions) (_ Checkpoint, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	writeEntry, flush, err := newManifestWriter(w, format, opts.Checkpoint == "")
+	if err != nil {
+		return "", err
+	}
+
+	prefix := manifestPrefix(root)
+	checkpoint, err := c.WalkWithOptions(root, func(resource *RespResource) error {
+		if resource.IsDir {
+			return nil
+		}
+
+		checksum, err := c.GetChecksum(resourcePath(resource.Path), manifestChecksumAlgorithm)
+		if err != nil {
+			return err
+		}
+
+		return writeEntry(ManifestEntry{
+			Path:     strings.TrimPrefix(resource.Path, prefix),
+			Size:     resource.Size,
+			Modified: resource.Modified,
+			Checksum: checksum,
+		})
+	}, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return checkpoint, flush()
+}
+
+// newManifestWriter returns functions writing entries to w in the given format and
+// flushing buffered output. The CSV header is only written if header is set.
+func newManifestWriter(w io.Writer, format Format, header bool) (func(ManifestEntry) error, func() error, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if header {
+			if err := cw.Write(manifestHeader); err != nil {
+				return nil, nil, fmt.Errorf("failed to write manifest header: %w", err)
+			}
+		}
+		write := func(entry ManifestEntry) error {
+			record := []string{
+				entry.Path,
+				strconv.FormatInt(entry.Size, 10),
+				entry.Modified.Format(time.RFC3339Nano),
+				entry.Checksum,
+			}
+			if err := cw.Write(record); err != nil {
+				return fmt.Errorf("failed to write manifest entry: %w", err)
+			}
+			return nil
+		}
+		flush := func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+		return write, flush, nil
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		write := func(entry ManifestEntry) error {
+			if err := enc.Encode(entry); err != nil {
+				return fmt.Errorf("failed to write manifest entry: %w", err)
+			}
+			return nil
+		}
+		return write, func() error { return nil }, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported manifest format: %q", format)
+	}
+}
+
+// manifestPrefix returns the server path prefix stripped from resources below root
+func manifestPrefix(root string) string {
+	if root = strings.Trim(root, "/"); root == "" {
+		return "/"
+	}
+	return "/" + root + "/"
+}
+
+// ManifestReport lists the differences between a manifest and the current remote tree.
+// Paths are relative to the manifest root.
+type ManifestReport struct {
+	// Missing lists files recorded in the manifest that no longer exist remotely
+	Missing []string
+	// Changed lists files whose size or checksum differs from the manifest
+	Changed []string
+	// Extra lists remote files not recorded in the manifest
+	Extra []string
+}
+
+// Matches reports whether the remote tree matched the manifest exactly
+func (r *ManifestReport) Matches() bool {
+	return len(r.Missing) == 0 && len(r.Changed) == 0 && len(r.Extra) == 0
+}
+
+// VerifyManifest checks a manifest previously written by ExportManifest, in either format,
+// against the current state of the remote tree rooted at root. Files are compared by size
+// and checksum; modification times are ignored as restores do not preserve them.
+func (c *Client) VerifyManifest(root string, r io.Reader) (_ *ManifestReport, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	entries, err := readManifest(r)
+	if err != nil {
+		return nil, err
+	}
+	expected := make(map[string]ManifestEntry, len(entries))
+	for _, entry := range entries {
+		expected[entry.Path] = entry
+	}
+
+	report := &ManifestReport{}
+	seen := make(map[string]bool, len(entries))
+	prefix := manifestPrefix(root)
+	err = c.Walk(root, func(resource *RespResource) error {
+		if resource.IsDir {
+			return nil
+		}
+
+		relPath := strings.TrimPrefix(resource.Path, prefix)
+		entry, ok := expected[relPath]
+		if !ok {
+			report.Extra = append(report.Extra, relPath)
+			return nil
+		}
+		seen[relPath] = true
+
+		if resource.Size != entry.Size {
+			report.Changed = append(report.Changed, relPath)
+			return nil
+		}
+		checksum, err := c.GetChecksum(resourcePath(resource.Path), manifestChecksumAlgorithm)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(checksum, entry.Checksum) {
+			report.Changed = append(report.Changed, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !seen[entry.Path] {
+			report.Missing = append(report.Missing, entry.Path)
+		}
+	}
+
+	return report, nil
+}
+
+// readManifest decodes a manifest, detecting whether it is CSV or JSON lines
+func readManifest(r io.Reader) ([]ManifestEntry, error) {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.Peek(1)
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			if b[0] == '{' {
+				return readJSONManifest(br)
+			}
+			return readCSVManifest(br)
+		}
+		br.ReadByte()
+	}
+}
+
+// readJSONManifest decodes a JSON lines manifest
+func readJSONManifest(r io.Reader) ([]ManifestEntry, error) {
+	var entries []ManifestEntry
+	dec := json.NewDecoder(r)
+	for {
+		var entry ManifestEntry
+		if err := dec.Decode(&entry); err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid manifest entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// readCSVManifest decodes a CSV manifest
+func readCSVManifest(r io.Reader) ([]ManifestEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(manifestHeader)
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest header: %w", err)
+	}
+	if strings.Join(header, ",") != strings.Join(manifestHeader, ",") {
+		return nil, fmt.Errorf("invalid manifest header: %v", header)
+	}
+
+	var entries []ManifestEntry
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest entry: %w", err)
+		}
+
+		size, err := strconv.ParseInt(record[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size for %s: %w", record[0], err)
+		}
+		modified, err := time.Parse(time.RFC3339Nano, record[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid modification time for %s: %w", record[0], err)
+		}
+		entries = append(entries, ManifestEntry{Path: record[0], Size: size, Modified: modified, Checksum: record[3]})
+	}
+}
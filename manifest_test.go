@@ -0,0 +1,137 @@
+package filebrowser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportManifest(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	server := newTreeServer(t, map[string]testFile{
+		"data/a.txt":     {content: []byte("alpha"), modified: modified},
+		"data/sub/b.txt": {content: []byte("beta"), modified: modified},
+		"other/c.txt":    {content: []byte("gamma"), modified: modified},
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := client.ExportManifest("data", &buf, FormatCSV); err != nil {
+			t.Fatalf("ExportManifest() error = %v", err)
+		}
+
+		expected := "path,size,modified,checksum\n" +
+			"a.txt,5,2024-05-01T12:00:00Z," + sha256Hex([]byte("alpha")) + "\n" +
+			"sub/b.txt,4,2024-05-01T12:00:00Z," + sha256Hex([]byte("beta")) + "\n"
+		if buf.String() != expected {
+			t.Errorf("ExportManifest() =\n%s\nwant\n%s", buf.String(), expected)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := client.ExportManifest("/", &buf, FormatJSON); err != nil {
+			t.Fatalf("ExportManifest() error = %v", err)
+		}
+
+		var entries []ManifestEntry
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			var entry ManifestEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("invalid manifest line %q: %v", scanner.Text(), err)
+			}
+			entries = append(entries, entry)
+		}
+
+		if len(entries) != 3 {
+			t.Fatalf("ExportManifest() wrote %d entries, want 3", len(entries))
+		}
+		nested := entries[1]
+		if nested.Path != "data/sub/b.txt" || nested.Size != 4 || !nested.Modified.Equal(modified) || nested.Checksum != sha256Hex([]byte("beta")) {
+			t.Errorf("unexpected entry %+v", nested)
+		}
+	})
+
+	t.Run("Resumed", func(t *testing.T) {
+		var buf bytes.Buffer
+		checkpoint, err := client.ExportManifestWithOptions("data", &buf, FormatCSV, WalkOptions{Deadline: time.Now().Add(-time.Second)})
+		for runs := 0; err == nil && checkpoint != ""; runs++ {
+			if runs > 10 {
+				t.Fatal("export did not complete")
+			}
+			checkpoint, err = client.ExportManifestWithOptions("data", &buf, FormatCSV, WalkOptions{Checkpoint: checkpoint, Deadline: time.Now().Add(time.Hour)})
+		}
+		if err != nil {
+			t.Fatalf("ExportManifestWithOptions() error = %v", err)
+		}
+
+		// Appended runs form a single manifest with one header
+		report, err := client.VerifyManifest("data", &buf)
+		if err != nil || !report.Matches() {
+			t.Errorf("VerifyManifest() = %+v, %v, want match", report, err)
+		}
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		err := client.ExportManifest("data", &bytes.Buffer{}, Format("xml"))
+		if err == nil || !strings.Contains(err.Error(), "unsupported manifest format") {
+			t.Errorf("ExportManifest() error = %v, want unsupported format", err)
+		}
+	})
+}
+
+func TestVerifyManifest(t *testing.T) {
+	original := map[string]testFile{
+		"data/same.txt":    {content: []byte("same")},
+		"data/changed.txt": {content: []byte("before")},
+		"data/resized.txt": {content: []byte("short")},
+		"data/gone.txt":    {content: []byte("gone")},
+	}
+	current := map[string]testFile{
+		"data/same.txt":    {content: []byte("same"), modified: time.Now()},
+		"data/changed.txt": {content: []byte("after!")},
+		"data/resized.txt": {content: []byte("much longer")},
+		"data/extra.txt":   {content: []byte("extra")},
+	}
+
+	originalServer := newTreeServer(t, original)
+	originalClient := &Client{URL: originalServer.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	currentServer := newTreeServer(t, current)
+	currentClient := &Client{URL: currentServer.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	for _, format := range []Format{FormatCSV, FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var manifest bytes.Buffer
+			if err := originalClient.ExportManifest("data", &manifest, format); err != nil {
+				t.Fatalf("ExportManifest() error = %v", err)
+			}
+
+			report, err := originalClient.VerifyManifest("data", bytes.NewReader(manifest.Bytes()))
+			if err != nil {
+				t.Fatalf("VerifyManifest() error = %v", err)
+			}
+			if !report.Matches() {
+				t.Errorf("VerifyManifest() against unchanged tree = %+v, want match", report)
+			}
+
+			report, err = currentClient.VerifyManifest("data", bytes.NewReader(manifest.Bytes()))
+			if err != nil {
+				t.Fatalf("VerifyManifest() error = %v", err)
+			}
+			if strings.Join(report.Missing, ",") != "gone.txt" {
+				t.Errorf("Missing = %v, want [gone.txt]", report.Missing)
+			}
+			if strings.Join(report.Changed, ",") != "changed.txt,resized.txt" {
+				t.Errorf("Changed = %v, want [changed.txt resized.txt]", report.Changed)
+			}
+			if strings.Join(report.Extra, ",") != "extra.txt" {
+				t.Errorf("Extra = %v, want [extra.txt]", report.Extra)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package filebrowser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Register decoders used by ImageProbe
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// SidecarSuffix is appended to the remote path of an upload to name its sidecar file
+const SidecarSuffix = ".meta.json"
+
+// MediaInfo contains metadata probed from a media file
+type MediaInfo struct {
+	Width    int               `json:"width,omitempty"`
+	Height   int               `json:"height,omitempty"`
+	Duration time.Duration     `json:"duration,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"` // e.g. artist and title of audio files
+}
+
+// MediaProbe extracts metadata from a local file. Probes return nil without error for
+// files they do not understand.
+type MediaProbe interface {
+	Probe(localPath string) (*MediaInfo, error)
+}
+
+// MediaProbeFunc adapts a function to the MediaProbe interface
+type MediaProbeFunc func(localPath string) (*MediaInfo, error)
+
+// Probe calls f(localPath)
+func (f MediaProbeFunc) Probe(localPath string) (*MediaInfo, error) {
+	return f(localPath)
+}
+
+// ImageProbe reads the dimensions of GIF, JPEG and PNG images
+var ImageProbe MediaProbe = MediaProbeFunc(func(localPath string) (*MediaInfo, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if errors.Is(err, image.ErrFormat) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &MediaInfo{Width: config.Width, Height: config.Height}, nil
+})
+
+// FFProbe probes video and audio files with the ffprobe command of FFmpeg, reporting
+// duration, video dimensions and container tags
+type FFProbe struct {
+	// Path is the ffprobe executable, looked up in PATH if empty
+	Path string
+}
+
+// Probe runs ffprobe on the file
+func (p FFProbe) Probe(localPath string) (*MediaInfo, error) {
+	command := p.Path
+	if command == "" {
+		command = "ffprobe"
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(command, "-v", "error", "-print_format", "json", "-show_format", "-show_streams", localPath)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// Not a media file ffprobe understands
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to run ffprobe: %w", err)
+	}
+
+	return parseFFProbe(output)
+}
+
+// parseFFProbe decodes the JSON output of ffprobe
+func parseFFProbe(output []byte) (*MediaInfo, error) {
+	var result struct {
+		Format struct {
+			Duration string            `json:"duration"`
+			Tags     map[string]string `json:"tags"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode ffprobe output: %w", err)
+	}
+
+	info := &MediaInfo{Tags: result.Format.Tags}
+	if result.Format.Duration != "" {
+		seconds, err := strconv.ParseFloat(result.Format.Duration, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q: %w", result.Format.Duration, err)
+		}
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	for _, stream := range result.Streams {
+		if stream.CodecType == "video" {
+			info.Width, info.Height = stream.Width, stream.Height
+			break
+		}
+	}
+	return info, nil
+}
+
+// probeMedia runs every probe on the file and merges their results, earlier probes
+// taking precedence. It returns nil if no probe understood the file.
+func probeMedia(localPath string, probes []MediaProbe) (*MediaInfo, error) {
+	var merged *MediaInfo
+	for _, probe := range probes {
+		info, err := probe.Probe(localPath)
+		if err != nil {
+			return nil, fmt.Errorf("media probe failed: %w", err)
+		}
+		if info == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &MediaInfo{}
+		}
+		merged.merge(info)
+	}
+	return merged, nil
+}
+
+// merge fills the fields of m not set yet from other
+func (m *MediaInfo) merge(other *MediaInfo) {
+	if m.Width == 0 && m.Height == 0 {
+		m.Width, m.Height = other.Width, other.Height
+	}
+	if m.Duration == 0 {
+		m.Duration = other.Duration
+	}
+	for key, value := range other.Tags {
+		if m.Tags == nil {
+			m.Tags = map[string]string{}
+		}
+		if _, ok := m.Tags[key]; !ok {
+			m.Tags[key] = value
+		}
+	}
+}
+
+// Sidecar is the metadata uploaded next to a file when UploadOptions.Sidecar is set
+type Sidecar struct {
+	Size              int64      `json:"size"`
+	ChecksumAlgorithm string     `json:"checksum_algorithm,omitempty"`
+	Checksum          string     `json:"checksum,omitempty"`
+	Media             *MediaInfo `json:"media,omitempty"`
+}
+
+// uploadSidecar uploads the sidecar of a completed upload
+func (c *Client) uploadSidecar(result *UploadResult, checksumAlgorithm string) error {
+	sidecar := Sidecar{Size: result.Bytes, Checksum: result.Checksum, Media: result.Media}
+	if result.Checksum != "" {
+		sidecar.ChecksumAlgorithm = checksumAlgorithm
+	}
+
+	data, err := json.MarshalIndent(sidecar, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sidecar: %w", err)
+	}
+	if err := c.UploadStream(bytes.NewReader(data), result.RemotePath+SidecarSuffix); err != nil {
+		return fmt.Errorf("failed to upload sidecar: %w", err)
+	}
+	return nil
+}
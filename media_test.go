@@ -0,0 +1,92 @@
+package filebrowser
+
+import (
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestImageProbe(t *testing.T) {
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "image.png")
+	file, err := os.Create(imagePath)
+	if err != nil {
+		t.Fatalf("Failed to create image: %v", err)
+	}
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 40, 30))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	file.Close()
+
+	info, err := ImageProbe.Probe(imagePath)
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if info == nil || info.Width != 40 || info.Height != 30 {
+		t.Errorf("Probe() = %+v, want 40x30", info)
+	}
+
+	textPath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(textPath, []byte("not an image"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if info, err := ImageProbe.Probe(textPath); info != nil || err != nil {
+		t.Errorf("Probe() of text file = %+v, %v, want nil", info, err)
+	}
+}
+
+func TestParseFFProbe(t *testing.T) {
+	output := []byte(`{
+		"streams": [{"codec_type": "audio"}, {"codec_type": "video", "width": 1920, "height": 1080}],
+		"format": {"duration": "62.500000", "tags": {"title": "Demo"}}
+	}`)
+
+	info, err := parseFFProbe(output)
+	if err != nil {
+		t.Fatalf("parseFFProbe() error = %v", err)
+	}
+	if info.Width != 1920 || info.Height != 1080 || info.Duration != 62500*time.Millisecond || info.Tags["title"] != "Demo" {
+		t.Errorf("parseFFProbe() = %+v", info)
+	}
+}
+
+func TestUploadWithProbesAndSidecar(t *testing.T) {
+	localPath, _ := writeTestFile(t, 64)
+	server := newMemServer(t, nil)
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	probes := []MediaProbe{
+		MediaProbeFunc(func(string) (*MediaInfo, error) { return nil, nil }),
+		MediaProbeFunc(func(string) (*MediaInfo, error) {
+			return &MediaInfo{Duration: time.Second, Tags: map[string]string{"artist": "A"}}, nil
+		}),
+		MediaProbeFunc(func(string) (*MediaInfo, error) {
+			return &MediaInfo{Duration: time.Minute, Tags: map[string]string{"artist": "B", "album": "C"}}, nil
+		}),
+	}
+	result, err := client.UploadWithOptions(localPath, "media/track.bin", UploadOptions{Checksum: "sha256", Probes: probes, Sidecar: true})
+	if err != nil {
+		t.Fatalf("UploadWithOptions() error = %v", err)
+	}
+
+	want := MediaInfo{Duration: time.Second, Tags: map[string]string{"artist": "A", "album": "C"}}
+	if result.Media == nil || result.Media.Duration != want.Duration || len(result.Media.Tags) != 2 || result.Media.Tags["artist"] != "A" {
+		t.Errorf("UploadResult.Media = %+v, want %+v", result.Media, want)
+	}
+
+	data, ok := server.file("media/track.bin" + SidecarSuffix)
+	if !ok {
+		t.Fatal("sidecar was not uploaded")
+	}
+	var sidecar Sidecar
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		t.Fatalf("invalid sidecar: %v", err)
+	}
+	if sidecar.Size != 64 || sidecar.ChecksumAlgorithm != "sha256" || sidecar.Checksum != result.Checksum || sidecar.Media.Duration != time.Second {
+		t.Errorf("sidecar = %+v", sidecar)
+	}
+}
@@ -0,0 +1,35 @@
+package filebrowser
+
+import (
+	"net/http"
+
+	"github.com/imroc/req/v3"
+)
+
+// Handler sends a request to the Filebrowser server and returns its response, like
+// http.RoundTripper
+type Handler func(r *http.Request) (*http.Response, error)
+
+// Middleware wraps the Handler sending requests, e.g. to log, measure, trace or modify
+// them. It is called once per client; the returned Handler is called for every request,
+// including each retry, TUS chunk and login, and must be safe for concurrent use.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware wraps every request of the client with middleware, see Client.Middleware
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *clientOptions) { o.middleware = append(o.middleware, middleware...) }
+}
+
+// withMiddleware returns a copy of client sending its requests through middleware, the
+// first one being the outermost
+func withMiddleware(client *req.Client, middleware []Middleware) *req.Client {
+	client = client.Clone()
+	client.GetTransport().WrapRoundTripFunc(func(rt http.RoundTripper) req.HttpRoundTripFunc {
+		next := Handler(rt.RoundTrip)
+		for i := len(middleware) - 1; i >= 0; i-- {
+			next = middleware[i](next)
+		}
+		return req.HttpRoundTripFunc(next)
+	})
+	return client
+}
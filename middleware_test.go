@@ -0,0 +1,75 @@
+package filebrowser
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	server := newMemServer(t, nil)
+
+	// Every request must carry the header added by the middleware
+	var unmarked []string
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Request-Tag") != "tagged" {
+			unmarked = append(unmarked, r.Method+" "+r.URL.Path)
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(r *http.Request) (*http.Response, error) {
+				mu.Lock()
+				calls = append(calls, name+" "+r.Method)
+				mu.Unlock()
+				return next(r)
+			}
+		}
+	}
+	tag := func(next Handler) Handler {
+		return func(r *http.Request) (*http.Response, error) {
+			r.Header.Set("X-Request-Tag", "tagged")
+			return next(r)
+		}
+	}
+
+	client, err := NewClient(server.URL, "user", "pass", WithMiddleware(record("outer"), record("inner"), tag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Upload(localPath, "dir/a.txt"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if _, err := client.GetResource("dir/a.txt"); err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+
+	if len(unmarked) > 0 {
+		t.Errorf("requests bypassed the middleware: %v", unmarked)
+	}
+	if len(calls) < 4 || calls[0] != "outer POST" || calls[1] != "inner POST" {
+		t.Fatalf("calls = %v, want the outer middleware first", calls)
+	}
+	if !slices.ContainsFunc(calls, func(call string) bool { return strings.HasSuffix(call, " PATCH") }) {
+		t.Errorf("calls = %v, want the TUS chunks included", calls)
+	}
+
+	// Clients without middleware share the default HTTP client untouched
+	plain := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	if plain.http() != defaultHTTPClient {
+		t.Error("client without middleware does not use the default HTTP client")
+	}
+}
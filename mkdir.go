@@ -0,0 +1,84 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// MkdirAll creates the remote directory dir together with any missing parents, like
+// os.MkdirAll. Existing directories are left alone; an existing file fails.
+func (c *Client) MkdirAll(dir string) (err error) {
+	_, start := c.startOp(context.Background(), OpMkdir, dir)
+	defer func() { err = c.finishOp(OpMkdir, dir, 0, start, err) }()
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return c.mkdirAll(dir)
+}
+
+// ensureParents creates the missing parent directories of remotePath if CreateParents is set
+func (c *Client) ensureParents(remotePath string) error {
+	if !c.CreateParents {
+		return nil
+	}
+	if err := c.mkdirAll(path.Dir("/" + strings.Trim(remotePath, "/"))); err != nil {
+		return fmt.Errorf("failed to create parent directories: %w", err)
+	}
+	return nil
+}
+
+// mkdirAll creates dir and its missing parents, from the deepest existing one down
+func (c *Client) mkdirAll(dir string) error {
+	dir = strings.Trim(dir, "/")
+	if dir == "" || dir == "." {
+		return nil // The root always exists
+	}
+
+	resource, err := c.lookupResource(dir)
+	if err != nil {
+		return err
+	}
+	if resource != nil {
+		if !resource.IsDir {
+			return fmt.Errorf("%s exists and is not a directory: %w", dir, ErrAlreadyExists)
+		}
+		return nil
+	}
+
+	if err := c.mkdirAll(path.Dir(dir)); err != nil {
+		return err
+	}
+	return c.mkdir(dir)
+}
+
+// mkdir creates the directory dir, whose parent must exist
+func (c *Client) mkdir(dir string) error {
+	url, err := c.apiURL("resources", dir)
+	if err != nil {
+		return err
+	}
+	// A trailing slash makes the server create a directory instead of a file
+	resp, err := c.newRequest(context.Background()).Post(url + "/")
+	if err != nil {
+		return fmt.Errorf("mkdir request failed: %w", err)
+	}
+
+	if err := checkDenied(resp, dir); err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		c.log().Info("Created directory", "path", dir)
+		return c.applyMode(dir, c.DirMode)
+	case http.StatusConflict:
+		// Created concurrently since it was looked up
+		return nil
+	default:
+		return fmt.Errorf("mkdir request failed with %w", newAPIError(resp))
+	}
+}
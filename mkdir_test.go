@@ -0,0 +1,108 @@
+package filebrowser
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newStrictParentsServer starts a server that, like some Filebrowser setups, fails moves
+// into missing directories. It holds the given files and directories.
+func newStrictParentsServer(t *testing.T, entries map[string]bool) (*httptest.Server, func() map[string]bool) {
+	t.Helper()
+
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path == "/api/login" {
+			w.Write([]byte("test-token"))
+			return
+		}
+		name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/resources"), "/")
+		parentExists := func(name string) bool {
+			parent := path.Dir(name)
+			return parent == "." || entries[parent]
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			isDir, ok := entries[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(RespResource{Path: "/" + name, IsDir: isDir})
+		case http.MethodPost:
+			if _, ok := entries[name]; ok {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			if !parentExists(name) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			entries[name] = true
+		case http.MethodPatch:
+			dst := strings.Trim(r.URL.Query().Get("destination"), "/")
+			if !parentExists(dst) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			entries[dst] = entries[name]
+			delete(entries, name)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() map[string]bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return entries
+	}
+}
+
+func TestMkdirAll(t *testing.T) {
+	server, entries := newStrictParentsServer(t, map[string]bool{"a": true, "a/file.txt": false})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	if err := client.MkdirAll("/a/b/c/"); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := client.MkdirAll("a/b"); err != nil {
+		t.Errorf("MkdirAll(existing) error = %v", err)
+	}
+	if got := entries(); !got["a/b"] || !got["a/b/c"] {
+		t.Errorf("MkdirAll() left entries %v", got)
+	}
+	if err := client.MkdirAll("a/file.txt/d"); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("MkdirAll(below file) error = %v, want ErrAlreadyExists", err)
+	}
+}
+
+func TestCreateParents(t *testing.T) {
+	server, entries := newStrictParentsServer(t, map[string]bool{"file.txt": false})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	// Without the option the move fails on the missing parents
+	if err := client.Move("file.txt", "x/y/file.txt", false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Move() error = %v, want ErrNotFound", err)
+	}
+
+	client.CreateParents = true
+	if err := client.Move("file.txt", "x/y/file.txt", false); err != nil {
+		t.Fatalf("Move() with CreateParents error = %v", err)
+	}
+	if got := entries(); !got["x"] || !got["x/y"] {
+		t.Errorf("Move() left entries %v, want parents created", got)
+	}
+	if _, ok := entries()["x/y/file.txt"]; !ok {
+		t.Error("Move() did not move the file")
+	}
+}
@@ -0,0 +1,201 @@
+package filebrowser
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/imroc/req/v3"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultHTTPClient is shared by clients created without an HTTP client, so connections
+// are reused across calls. It keeps no cookies as authentication relies on a header.
+var defaultHTTPClient = req.C().SetCookieJar(nil)
+
+// Option configures a Client created by NewClient
+type Option func(*clientOptions)
+
+// clientOptions collects the options passed to NewClient
+type clientOptions struct {
+	httpClient  *req.Client
+	stdClient   bool // httpClient delegates to a transport it cannot configure
+	timeout     time.Duration
+	userAgent   string
+	insecureTLS bool
+	logger      Logger
+	retry       *RetryPolicy
+	timeouts    Timeouts
+	tokens      TokenStore
+	prompt      func(url string, username string) (string, error)
+	middleware  []Middleware
+	stats       StatsCollector
+	auth        AuthMethod
+	proxyHeader string
+	token       string
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithHTTPClient sends requests with the given client instead of a shared default one.
+// Options configuring the connection are applied to a copy of it.
+func WithHTTPClient(client *req.Client) Option {
+	return func(o *clientOptions) { o.httpClient = client }
+}
+
+// WithStdHTTPClient sends requests through the transport of a standard library client,
+// e.g. one configured with a proxy, custom CA pool or client certificates, instead of a
+// shared default one. The timeout and cookie jar of the client are kept. It cannot be
+// combined with WithInsecureTLS, which configures the transport.
+func WithStdHTTPClient(client *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient, o.stdClient = newStdHTTPClient(client), true
+	}
+}
+
+// newStdHTTPClient creates a req client delegating its requests to client
+func newStdHTTPClient(client *http.Client) *req.Client {
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	c := req.C().SetCookieJar(client.Jar).SetTimeout(client.Timeout)
+	c.GetTransport().WrapRoundTripFunc(func(http.RoundTripper) req.HttpRoundTripFunc {
+		return transport.RoundTrip
+	})
+	return c
+}
+
+// WithTimeout limits the duration of every request, including each upload chunk
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) { o.timeout = timeout }
+}
+
+// WithUserAgent sets the User-Agent header of every request
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) { o.userAgent = userAgent }
+}
+
+// WithInsecureTLS disables verification of the server certificate, for servers using
+// self-signed certificates in development
+func WithInsecureTLS() Option {
+	return func(o *clientOptions) { o.insecureTLS = true }
+}
+
+// WithLogger sends the log messages of the client to logger, see Client.Logger
+func WithLogger(logger Logger) Option {
+	return func(o *clientOptions) { o.logger = logger }
+}
+
+// WithStats reports the statistics of every operation to collector, see Client.Stats.
+// Combined with WithTracerProvider or WithMeterProvider, both receive them.
+func WithStats(collector StatsCollector) Option {
+	return func(o *clientOptions) { o.stats = collector }
+}
+
+// WithRetry retries transient failures according to policy, see Client.Retry
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *clientOptions) { o.retry = &policy }
+}
+
+// WithTimeouts limits the duration of requests per class of operation, see Client.Timeouts
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(o *clientOptions) { o.timeouts = timeouts }
+}
+
+// WithTokenStore persists the login token between runs, see Client.Tokens. The password
+// may then be empty.
+func WithTokenStore(store TokenStore) Option {
+	return func(o *clientOptions) { o.tokens = store }
+}
+
+// WithPasswordPrompt asks for the password when a login is needed, see
+// Client.PasswordPrompt. The password may then be empty.
+func WithPasswordPrompt(prompt func(url string, username string) (string, error)) Option {
+	return func(o *clientOptions) { o.prompt = prompt }
+}
+
+// NewClient creates a client for the Filebrowser server at url authenticating as the given
+// user. Unlike a Client created as a struct literal it can carry connection settings.
+func NewClient(url string, username string, password string, opts ...Option) (*Client, error) {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	client := &Client{
+		URL:      url,
+		ReqLogin: ReqLogin{Username: username, Password: password},
+		Retry:    o.retry,
+		Logger:   o.logger,
+		Timeouts: o.timeouts,
+
+		Tokens:         o.tokens,
+		PasswordPrompt: o.prompt,
+		Stats:          o.stats,
+		Middleware:     o.middleware,
+		Auth:           o.auth,
+		ProxyHeader:    o.proxyHeader,
+		Token:          o.token,
+	}
+	if err := client.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid client configuration: %w", err)
+	}
+	if o.stdClient && o.insecureTLS {
+		return nil, fmt.Errorf("invalid client configuration: WithInsecureTLS cannot configure the transport of an http.Client")
+	}
+
+	httpClient := o.httpClient
+	if o.timeout > 0 || o.userAgent != "" || o.insecureTLS {
+		if httpClient == nil {
+			httpClient = defaultHTTPClient
+		}
+		httpClient = httpClient.Clone()
+		if o.timeout > 0 {
+			httpClient.SetTimeout(o.timeout)
+		}
+		if o.userAgent != "" {
+			httpClient.SetUserAgent(o.userAgent)
+		}
+		if o.insecureTLS {
+			httpClient.EnableInsecureSkipVerify()
+		}
+	}
+	client.httpClient = httpClient
+
+	if o.tracerProvider != nil || o.meterProvider != nil {
+		telemetry, err := NewTelemetry(o.tracerProvider, o.meterProvider)
+		if err != nil {
+			return nil, err
+		}
+		client.Stats = telemetry
+		if o.stats != nil {
+			client.Stats = MultiStats(o.stats, telemetry)
+		}
+	}
+
+	return client, nil
+}
+
+// http returns the HTTP client requests are sent with, wrapped with the middleware of
+// the client and, with a token store, renewing rejected stored tokens
+func (c *Client) http() *req.Client {
+	client := c.httpClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	if len(c.Middleware) == 0 && c.Tokens == nil {
+		return client
+	}
+	c.middlewareOnce.Do(func() {
+		middleware := c.Middleware
+		if c.Tokens != nil {
+			middleware = append([]Middleware{c.renewRejectedToken}, middleware...)
+		}
+		c.middlewareClient = withMiddleware(client, middleware)
+	})
+	return c.middlewareClient
+}
@@ -0,0 +1,116 @@
+package filebrowser
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewClient(t *testing.T) {
+	server := newMemServer(t, nil)
+
+	var mu sync.Mutex
+	agents := map[string]bool{}
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents[r.UserAgent()] = true
+		mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+
+	var logs bytes.Buffer
+	client, err := NewClient(server.URL, "user", "pass",
+		WithUserAgent("uploader/1.0"),
+		WithTimeout(5*time.Second),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithRetry(RetryPolicy{MaxAttempts: 3}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.Retry == nil || client.Retry.MaxAttempts != 3 {
+		t.Errorf("NewClient() Retry = %+v, want policy", client.Retry)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Upload(localPath, "a.txt"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if _, err := client.Share("a.txt", 0, "", ""); err != nil {
+		t.Fatalf("Share() error = %v", err)
+	}
+
+	// API and TUS requests share the configured HTTP client
+	if len(agents) != 1 || !agents["uploader/1.0"] {
+		t.Errorf("requests sent with user agents %v, want only uploader/1.0", agents)
+	}
+	if !bytes.Contains(logs.Bytes(), []byte("Uploaded file")) {
+		t.Errorf("logger received %q", logs.String())
+	}
+	if defaultHTTPClient.Headers.Get("User-Agent") == "uploader/1.0" {
+		t.Error("options modified the shared default HTTP client")
+	}
+
+	if _, err := NewClient("", "user", "pass"); err == nil {
+		t.Error("NewClient() without URL should fail")
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestWithStdHTTPClient(t *testing.T) {
+	server := newMemServer(t, nil)
+
+	var mu sync.Mutex
+	paths := map[string]int{}
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		mu.Lock()
+		paths[strings.SplitN(strings.TrimPrefix(r.URL.Path, "/api/"), "/", 2)[0]]++
+		mu.Unlock()
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	client, err := NewClient(server.URL, "user", "pass",
+		WithStdHTTPClient(&http.Client{Transport: transport, Timeout: 5 * time.Second}),
+		WithUserAgent("uploader/1.0"),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Upload(localPath, "a.txt"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if _, err := client.Share("a.txt", 0, "", ""); err != nil {
+		t.Fatalf("Share() error = %v", err)
+	}
+
+	// API and TUS requests go through the injected transport
+	for _, api := range []string{"login", "tus", "share"} {
+		if paths[api] == 0 {
+			t.Errorf("no %s request went through the transport, got %v", api, paths)
+		}
+	}
+
+	_, err = NewClient(server.URL, "user", "pass", WithStdHTTPClient(&http.Client{}), WithInsecureTLS())
+	if err == nil {
+		t.Error("NewClient() with an http.Client and WithInsecureTLS should fail")
+	}
+}
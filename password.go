@@ -0,0 +1,82 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrWeakSharePassword is returned when a share password violates the password policy
+var ErrWeakSharePassword = errors.New("weak share password")
+
+// SharePasswordPolicy defines the requirements share passwords must meet
+type SharePasswordPolicy struct {
+	RequirePassword bool     // Reject shares without a password
+	MinLength       int      // Minimum number of characters
+	RequireUpper    bool     // At least one upper case letter
+	RequireLower    bool     // At least one lower case letter
+	RequireDigit    bool     // At least one digit
+	RequireSymbol   bool     // At least one character that is neither a letter nor a digit
+	Blocklist       []string // Rejected passwords, compared case-insensitively
+}
+
+// DefaultSharePasswordPolicy is a reasonable policy for shares exposed to the internet
+var DefaultSharePasswordPolicy = SharePasswordPolicy{
+	MinLength:    12,
+	RequireUpper: true,
+	RequireLower: true,
+	RequireDigit: true,
+	Blocklist: []string{
+		"password", "password1", "password123", "123456789012", "qwertyuiopas",
+		"letmein12345", "welcome12345", "changeme1234", "Passw0rd1234",
+	},
+}
+
+// Validate checks a share password against the policy. An empty password only fails
+// if the policy requires one. Violations wrap ErrWeakSharePassword.
+func (p *SharePasswordPolicy) Validate(password string) error {
+	if password == "" {
+		if p.RequirePassword {
+			return fmt.Errorf("%w: a password is required", ErrWeakSharePassword)
+		}
+		return nil
+	}
+
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters long", ErrWeakSharePassword, p.MinLength)
+	}
+
+	for _, blocked := range p.Blocklist {
+		if strings.EqualFold(password, blocked) {
+			return fmt.Errorf("%w: password is too common", ErrWeakSharePassword)
+		}
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	switch {
+	case p.RequireUpper && !upper:
+		return fmt.Errorf("%w: must contain an upper case letter", ErrWeakSharePassword)
+	case p.RequireLower && !lower:
+		return fmt.Errorf("%w: must contain a lower case letter", ErrWeakSharePassword)
+	case p.RequireDigit && !digit:
+		return fmt.Errorf("%w: must contain a digit", ErrWeakSharePassword)
+	case p.RequireSymbol && !symbol:
+		return fmt.Errorf("%w: must contain a symbol", ErrWeakSharePassword)
+	}
+
+	return nil
+}
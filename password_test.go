@@ -0,0 +1,84 @@
+package filebrowser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSharePasswordPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   SharePasswordPolicy
+		password string
+		wantErr  bool
+	}{
+		{
+			name:     "Strong password",
+			policy:   DefaultSharePasswordPolicy,
+			password: "Correct-Horse-42",
+			wantErr:  false,
+		},
+		{
+			name:     "Too short",
+			policy:   DefaultSharePasswordPolicy,
+			password: "123",
+			wantErr:  true,
+		},
+		{
+			name:     "Blocklisted",
+			policy:   DefaultSharePasswordPolicy,
+			password: "PASSW0RD1234",
+			wantErr:  true,
+		},
+		{
+			name:     "Missing digit",
+			policy:   DefaultSharePasswordPolicy,
+			password: "CorrectHorseBattery",
+			wantErr:  true,
+		},
+		{
+			name:     "Missing symbol",
+			policy:   SharePasswordPolicy{RequireSymbol: true},
+			password: "abc123",
+			wantErr:  true,
+		},
+		{
+			name:     "Empty password allowed",
+			policy:   DefaultSharePasswordPolicy,
+			password: "",
+			wantErr:  false,
+		},
+		{
+			name:     "Empty password required",
+			policy:   SharePasswordPolicy{RequirePassword: true},
+			password: "",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrWeakSharePassword) {
+				t.Errorf("Validate() error = %v, want ErrWeakSharePassword", err)
+			}
+		})
+	}
+}
+
+func TestShareRejectsWeakPassword(t *testing.T) {
+	client := &Client{
+		URL:                 "http://127.0.0.1:0",
+		ReqLogin:            ReqLogin{Username: "user", Password: "pass"},
+		SharePasswordPolicy: &DefaultSharePasswordPolicy,
+	}
+
+	// Validation happens before any request is made
+	_, err := client.Share("dir/file.txt", 1, "123", "days")
+	if !errors.Is(err, ErrWeakSharePassword) {
+		t.Errorf("Share() error = %v, want ErrWeakSharePassword", err)
+	}
+}
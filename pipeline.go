@@ -0,0 +1,440 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Names of the stages of a Pipeline, passed to its hooks
+const (
+	StageDownload  = "download"
+	StageTransform = "transform"
+	StageCheck     = "check"
+	StageUpload    = "upload"
+	StageShare     = "share"
+)
+
+// PipelineState carries the data of one pipeline run from stage to stage. Stages and
+// hooks may change it, e.g. a hook after the download may rename RemotePath.
+type PipelineState struct {
+	Context      context.Context // Context of the run, stages should stop once it is done
+	Auth         FilebrowserAuth
+	ExternalURL  string
+	RemotePathFn func(string) string
+	Params       ActionParams
+	Client       *Client // Client authenticated with Auth, nil if API is not a *Client
+	// API is the Filebrowser API the default stages use: Client, or the implementation
+	// passed to RunWithAPI
+	API FileBrowserAPI
+
+	LocalPath    string        // Downloaded file, set by the download stage
+	RemotePath   string        // Destination, set by the download stage
+	Checked      bool          // Set by the check stage once it looked up the remote file
+	Resource     *RespResource // Remote file found by the check stage, nil if missing
+	ShouldUpload bool          // Set by the check stage
+	Upload       *UploadResult // Set by the upload stage if the file was uploaded
+	Result       *ShareResult  // Set by the share stage
+
+	// Progress, if set, receives the progress of the stages transferring data
+	Progress PipelineProgress
+
+	transformed string        // Temporary file written by the last TransformFile step
+	stream      io.ReadCloser // Body of the external URL opened by StreamStage
+	streamSize  int64
+}
+
+// progress returns the progress callback of a stage, nil if the run reports no progress
+func (s *PipelineState) progress(stage string) ProgressFunc {
+	if s.Progress == nil {
+		return nil
+	}
+	return func(bytesDone int64, bytesTotal int64) { s.Progress(stage, bytesDone, bytesTotal) }
+}
+
+// log returns the logger of the client of the run
+func (s *PipelineState) log() Logger {
+	if s.Client != nil {
+		return s.Client.log()
+	}
+	return packageLog(s.Params.Logger)
+}
+
+// downloadOptions returns the options downloading the external URL
+func (s *PipelineState) downloadOptions() DownloadOptions {
+	policy := s.Params.URLPolicy
+	if policy == nil {
+		policy = &URLPolicy{}
+	}
+	return DownloadOptions{
+		Progress:  s.progress(StageDownload),
+		Logger:    s.Params.Logger,
+		Auth:      s.Params.SourceAuth,
+		URLPolicy: policy,
+		MaxBytes:  s.Params.MaxDownloadBytes,
+
+		Connections: s.Params.DownloadConnections,
+	}
+}
+
+// Stage is one step of a Pipeline
+type Stage func(state *PipelineState) error
+
+// PipelineProgress reports the bytes transferred by a stage of a Pipeline
+type PipelineProgress func(stage string, bytesDone int64, bytesTotal int64)
+
+// PipelineHook is called around the stages of a Pipeline with the name of the stage.
+// An error aborts the run.
+type PipelineHook func(stage string, state *PipelineState) error
+
+// Pipeline downloads a file from an external URL, optionally transforms it, uploads it to
+// Filebrowser and shares it, like SaveAndShare, in replaceable stages. Nil stages run the
+// defaults (DownloadStage, CheckStage, UploadStage and ShareStage), so custom stages can
+// wrap them.
+type Pipeline struct {
+	Download Stage
+	Check    Stage
+	Upload   Stage
+	Share    Stage
+
+	// Transforms run in order between the download and the check, e.g. TransformFile
+	// steps converting the downloaded file
+	Transforms []Stage
+	// Progress, if set, receives the progress of the download, the TransformFile steps and
+	// the upload
+	Progress PipelineProgress
+
+	// Before, if set, is called before every stage, e.g. to scan the downloaded file
+	Before PipelineHook
+	// After, if set, is called after every successful stage
+	After PipelineHook
+}
+
+// Run executes the pipeline. Runs writing the same remote file are serialized within
+// the process from the check stage on.
+func (p *Pipeline) Run(auth FilebrowserAuth, externalURL string, remotePathFn func(string) string, actionParams ActionParams) (*ShareResult, error) {
+	return p.RunContext(context.Background(), auth, externalURL, remotePathFn, actionParams)
+}
+
+// RunContext is like Run, aborting once ctx is done. The default stages pass ctx to their
+// requests, and no further stage starts after it is done. Dry runs plan with the default
+// stages, without calling custom stages or hooks.
+func (p *Pipeline) RunContext(ctx context.Context, auth FilebrowserAuth, externalURL string, remotePathFn func(string) string, actionParams ActionParams) (*ShareResult, error) {
+	// Validate authentication
+	if err := auth.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid authentication: %w", err)
+	}
+
+	client, actionParams := newRunClient(auth, actionParams)
+	return p.runWith(ctx, client, auth, externalURL, remotePathFn, actionParams)
+}
+
+// newRunClient creates the client of runs with the given parameters, filling in the unset
+// ones from the package defaults, and returns it with the completed parameters
+func newRunClient(auth FilebrowserAuth, actionParams ActionParams) (*Client, ActionParams) {
+	d := CurrentDefaults()
+	if actionParams.Logger == nil {
+		actionParams.Logger = d.Logger
+	}
+	if actionParams.Retry == nil {
+		actionParams.Retry = d.Retry
+	}
+
+	client := &Client{
+		URL: auth.URL,
+		ReqLogin: ReqLogin{
+			Username: auth.Username,
+			Password: auth.Password,
+		},
+		Stats:               actionParams.Stats,
+		SharePasswordPolicy: actionParams.PasswordPolicy,
+		Retry:               actionParams.Retry,
+		Logger:              actionParams.Logger,
+		Timeouts:            d.Timeouts,
+		PreUploadHook:       actionParams.PreUploadHook,
+		CreateParents:       actionParams.CreateParents,
+		FileMode:            actionParams.FileMode,
+	}
+	return client, actionParams
+}
+
+// runWith executes the pipeline with api, which runs may share to log in only once
+func (p *Pipeline) runWith(ctx context.Context, api FileBrowserAPI, auth FilebrowserAuth, externalURL string, remotePathFn func(string) string, actionParams ActionParams) (*ShareResult, error) {
+	// Validate input parameters
+	if externalURL == "" {
+		return nil, fmt.Errorf("external URL cannot be empty")
+	}
+	if remotePathFn == nil {
+		return nil, fmt.Errorf("remote path function cannot be nil")
+	}
+	if actionParams.PasswordPolicy != nil {
+		if err := actionParams.PasswordPolicy.Validate(actionParams.ShareParams.Password); err != nil {
+			return nil, err
+		}
+	}
+	// Refuse invalid expirations before transferring anything. The share stage resolves
+	// them again when sharing, so relative expirations count from then.
+	if _, err := actionParams.ShareParams.resolve(time.Now()); err != nil {
+		return nil, err
+	}
+
+	state := &PipelineState{
+		Context:      ctx,
+		Auth:         auth,
+		ExternalURL:  externalURL,
+		RemotePathFn: remotePathFn,
+		Params:       actionParams,
+		API:          api,
+		Progress:     p.Progress,
+	}
+	state.Client, _ = api.(*Client)
+	defer func() {
+		if state.transformed != "" {
+			os.Remove(state.transformed)
+		}
+		if state.stream != nil {
+			state.stream.Close()
+		}
+	}()
+
+	if actionParams.DryRun {
+		plan, err := planRun(state)
+		if err != nil {
+			return nil, err
+		}
+		return &ShareResult{RemotePath: plan.RemotePath, Plan: plan}, nil
+	}
+
+	if err := p.run(StageDownload, p.Download, DownloadStage, state); err != nil {
+		return nil, err
+	}
+	for _, transform := range p.Transforms {
+		if transform == nil {
+			continue
+		}
+		if err := p.run(StageTransform, transform, nil, state); err != nil {
+			return nil, err
+		}
+	}
+	if state.RemotePath == "" {
+		return nil, fmt.Errorf("remote path cannot be empty")
+	}
+
+	// Serialize saves of the same file within the process
+	unlock := saveLocks.lock(auth.URL + "\x00" + state.RemotePath)
+	defer unlock()
+
+	if err := p.run(StageCheck, p.Check, CheckStage, state); err != nil {
+		return nil, err
+	}
+	if err := p.run(StageUpload, p.Upload, UploadStage, state); err != nil {
+		return nil, err
+	}
+	if err := p.run(StageShare, p.Share, ShareStage, state); err != nil {
+		return nil, err
+	}
+	if state.Result == nil {
+		return nil, fmt.Errorf("share stage returned no result")
+	}
+
+	return state.Result, nil
+}
+
+// run executes one stage with its hooks, falling back to the default stage if nil
+func (p *Pipeline) run(name string, stage Stage, fallback Stage, state *PipelineState) error {
+	if stage == nil {
+		stage = fallback
+	}
+	if err := state.Context.Err(); err != nil {
+		return fmt.Errorf("%s aborted: %w", name, err)
+	}
+	if p.Before != nil {
+		if err := p.Before(name, state); err != nil {
+			return fmt.Errorf("%s aborted: %w", name, err)
+		}
+	}
+	if err := stage(state); err != nil {
+		return err
+	}
+	if p.After != nil {
+		if err := p.After(name, state); err != nil {
+			return fmt.Errorf("%s aborted: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// DownloadStage downloads the external URL to a local file, as allowed by the URL policy,
+// and derives the remote path from its name
+func DownloadStage(state *PipelineState) error {
+	ctx, start := startStats(state.Context, state.Params.Stats, OpDownload, state.ExternalURL)
+	localPath, err := DownloadToLocalWithOptionsContext(ctx, state.ExternalURL, state.Params.FileSize, state.downloadOptions())
+	reportStats(OperationStats{Operation: OpDownload, Path: state.ExternalURL, Bytes: localFileSize(localPath), Err: err}, start)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+
+	state.LocalPath = localPath
+	state.RemotePath = state.RemotePathFn(filepath.Base(localPath))
+	return nil
+}
+
+// CheckStage looks up the remote file and decides whether to upload: missing files are
+// uploaded, existing ones if forced or as decided by the skip strategy, by default if
+// they have a different size than expected. In strict mode an existing file is only kept
+// if its content matches the downloaded one.
+func CheckStage(state *PipelineState) error {
+	resource, err := findResource(state.Context, state.API, state.RemotePath)
+	if err != nil {
+		return fmt.Errorf("failed to get resource info: %w", err)
+	}
+	state.Checked, state.Resource = true, resource
+
+	state.ShouldUpload = state.Params.uploadNeeded(resource)
+	if !state.ShouldUpload && state.Params.Skip == SkipByHash {
+		same, err := sameContent(state.API, state.LocalPath, state.RemotePath, resource)
+		if err != nil {
+			return err
+		}
+		state.ShouldUpload = !same
+	}
+	if !state.ShouldUpload {
+		if state.Params.Strict {
+			if err := verifyContent(state.API, state.LocalPath, state.RemotePath, resource); err != nil {
+				return err
+			}
+		}
+		state.log().Info("Resource already exists, skipping upload", "path", state.RemotePath, "strategy", state.Params.Skip)
+	}
+	return nil
+}
+
+// verifyContent fails with a ContentMismatchError unless the remote file has the size and
+// SHA-256 checksum of the local file
+func verifyContent(api FileBrowserAPI, localPath string, remotePath string, resource *RespResource) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat local file: %w", err)
+	}
+	mismatch := &ContentMismatchError{
+		RemotePath: remotePath,
+		LocalPath:  localPath,
+		LocalSize:  info.Size(),
+		RemoteSize: resource.Size,
+	}
+	if mismatch.LocalSize != mismatch.RemoteSize {
+		return mismatch
+	}
+
+	if mismatch.LocalSHA256, err = fileSHA256(localPath); err != nil {
+		return err
+	}
+	if mismatch.RemoteSHA256, err = sha256Of(api, remotePath); err != nil {
+		return fmt.Errorf("failed to get remote checksum: %w", err)
+	}
+	if mismatch.LocalSHA256 != mismatch.RemoteSHA256 {
+		return mismatch
+	}
+	return nil
+}
+
+// UploadStage replaces the remote file with the downloaded one, or the stream opened by
+// StreamStage, if the check stage decided to upload. An existing file is only replaced
+// once the new content was uploaded next to it, and a remote file changed since the
+// check fails with ErrConcurrentModification.
+func UploadStage(state *PipelineState) error {
+	if !state.ShouldUpload {
+		return nil
+	}
+	api, remotePath, resource := state.API, state.RemotePath, state.Resource
+
+	// Another process may have written the file since it was inspected
+	if state.Checked {
+		if err := checkUnchanged(api, remotePath, resource); err != nil {
+			return err
+		}
+	}
+	if !state.Checked || resource == nil {
+		return uploadTo(state, remotePath)
+	}
+
+	switch {
+	case state.Params.Force || state.Params.Skip == SkipNever:
+		state.log().Info("Force flag set, replacing existing resource", "path", remotePath)
+	case state.Params.Skip == SkipByHash:
+		state.log().Info("Content mismatch, replacing existing resource", "path", remotePath)
+	default:
+		state.log().Info("File size mismatch, replacing existing resource", "path", remotePath,
+			"local_size", state.Params.FileSize, "remote_size", resource.Size)
+	}
+	tmpPath, err := tempSibling(remotePath, "upload")
+	if err != nil {
+		return err
+	}
+	err = uploadTo(state, tmpPath)
+	if err == nil {
+		err = checkUnchanged(api, remotePath, resource)
+	}
+	if err == nil {
+		if err = api.MoveContext(state.Context, tmpPath, remotePath, true); err != nil {
+			err = fmt.Errorf("failed to replace existing resource: %w", err)
+		}
+	}
+	if err != nil {
+		if deleteErr := api.DeleteResourceContext(state.Context, tmpPath); deleteErr != nil {
+			state.log().Warn("Failed to clean up temporary file", "path", tmpPath, "error", deleteErr)
+		}
+		return err
+	}
+	state.Upload.RemotePath = remotePath
+	return nil
+}
+
+// uploadTo uploads the downloaded file, or the stream opened by StreamStage, to remotePath
+func uploadTo(state *PipelineState, remotePath string) error {
+	if state.stream != nil {
+		return uploadStream(state, remotePath)
+	}
+	result, err := state.API.UploadWithOptionsContext(state.Context, state.LocalPath, remotePath, UploadOptions{
+		Progress: state.progress(StageUpload),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	state.Upload = result
+	return nil
+}
+
+// ShareStage shares the remote file with the share parameters, reusing an existing share
+// if ActionParams.ReuseShare is set
+func ShareStage(state *PipelineState) error {
+	api, now := state.API, time.Now()
+	share, err := state.Params.ShareParams.resolve(now)
+	if err != nil {
+		return err
+	}
+	if state.Params.ReuseShare {
+		link, err := reusableShare(api, state.RemotePath, share, now)
+		if err != nil {
+			return fmt.Errorf("failed to list shares: %w", err)
+		}
+		if link != nil {
+			state.Result = reusedShareResult(api.ServerURL(), state.RemotePath, *link)
+			state.log().Info("Reused share", "url", state.Result.ViewUrl)
+			return nil
+		}
+	}
+
+	hash, err := api.ShareContext(state.Context, state.RemotePath, share.Expires, share.Password, share.Unit)
+	if err != nil {
+		return fmt.Errorf("failed to create share: %w", err)
+	}
+
+	state.Result = newShareResult(api.ServerURL(), state.RemotePath, hash, share, now)
+
+	state.log().Info("Shared file", "url", state.Result.ViewUrl)
+	return nil
+}
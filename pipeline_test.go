@@ -0,0 +1,342 @@
+package filebrowser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// localOrigin allows downloads from the test servers, which listen on the loopback address
+var localOrigin = &URLPolicy{AllowPrivateNetworks: true}
+
+func TestPipeline(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("payload of " + r.URL.Path))
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, nil)
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	remotePathFn := func(name string) string { return "inbox/" + name }
+
+	errInfected := errors.New("infected")
+	var stages []string
+	pipeline := &Pipeline{
+		Before: func(stage string, state *PipelineState) error {
+			stages = append(stages, stage)
+			if stage == StageUpload && strings.Contains(state.LocalPath, "virus") {
+				return errInfected
+			}
+			return nil
+		},
+		After: func(stage string, state *PipelineState) error {
+			if stage == StageDownload {
+				state.RemotePath = path.Join(path.Dir(state.RemotePath), "renamed-"+path.Base(state.RemotePath))
+			}
+			return nil
+		},
+	}
+
+	result, err := pipeline.Run(auth, origin.URL+"/clean.txt", remotePathFn, ActionParams{URLPolicy: localOrigin})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.HasPrefix(result.ViewUrl, server.URL+"/share/") {
+		t.Errorf("ViewUrl = %v, want a share of %v", result.ViewUrl, server.URL)
+	}
+	if content, ok := server.file("inbox/renamed-clean.txt"); !ok || string(content) != "payload of /clean.txt" {
+		t.Errorf("renamed file = %q, %v, want the downloaded payload", content, ok)
+	}
+	if want := []string{StageDownload, StageCheck, StageUpload, StageShare}; strings.Join(stages, ",") != strings.Join(want, ",") {
+		t.Errorf("stages = %v, want %v", stages, want)
+	}
+
+	if _, err := pipeline.Run(auth, origin.URL+"/virus.txt", remotePathFn, ActionParams{URLPolicy: localOrigin}); !errors.Is(err, errInfected) {
+		t.Errorf("Run() of infected file error = %v, want errInfected", err)
+	}
+	if _, ok := server.file("inbox/renamed-virus.txt"); ok {
+		t.Error("infected file should not be uploaded")
+	}
+
+	// Custom stages can wrap the defaults
+	uploads := 0
+	pipeline = &Pipeline{Upload: func(state *PipelineState) error {
+		uploads++
+		return UploadStage(state)
+	}}
+	if _, err := pipeline.Run(auth, origin.URL+"/other.txt", remotePathFn, ActionParams{URLPolicy: localOrigin}); err != nil {
+		t.Fatalf("Run() with custom upload stage error = %v", err)
+	}
+	if _, ok := server.file("inbox/other.txt"); !ok || uploads != 1 {
+		t.Errorf("custom upload stage ran %d times, file uploaded = %v", uploads, ok)
+	}
+}
+
+func TestPipelineTransform(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("payload of " + r.URL.Path))
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, map[string][]byte{})
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	remotePathFn := func(name string) string { return name }
+
+	upper := TransformFile(func(ctx context.Context, r io.Reader, w io.Writer) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bytes.ToUpper(data))
+		return err
+	})
+	suffix := TransformFile(func(ctx context.Context, r io.Reader, w io.Writer) error {
+		if _, err := io.Copy(w, r); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "!")
+		return err
+	})
+	progress := map[string]int64{}
+	pipeline := &Pipeline{
+		Transforms: []Stage{upper, suffix},
+		Progress: func(stage string, bytesDone int64, bytesTotal int64) {
+			progress[stage] = bytesDone
+		},
+	}
+
+	if _, err := pipeline.Run(auth, origin.URL+"/a.txt", remotePathFn, ActionParams{URLPolicy: localOrigin}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if content, _ := server.file("a.txt"); string(content) != "PAYLOAD OF /A.TXT!" {
+		t.Errorf("uploaded content = %q, want the transformed file", content)
+	}
+	want := map[string]int64{StageDownload: 17, StageTransform: 17, StageUpload: 18}
+	for stage, bytes := range want {
+		if progress[stage] != bytes {
+			t.Errorf("progress of %s = %d, want %d", stage, progress[stage], bytes)
+		}
+	}
+
+	// Only the download is kept for later runs
+	entries, _ := os.ReadDir(filepath.Dir(LocalPathForDownload(origin.URL + "/a.txt")))
+	if len(entries) != 1 {
+		t.Errorf("run left %d files behind, want only the download", len(entries))
+	}
+
+	// A failing transform aborts the run before anything is uploaded
+	errBroken := errors.New("broken")
+	pipeline.Transforms = []Stage{TransformFile(func(ctx context.Context, r io.Reader, w io.Writer) error { return errBroken })}
+	if _, err := pipeline.Run(auth, origin.URL+"/b.txt", remotePathFn, ActionParams{URLPolicy: localOrigin}); !errors.Is(err, errBroken) {
+		t.Errorf("Run() with failing transform error = %v, want errBroken", err)
+	}
+	if _, ok := server.file("b.txt"); ok {
+		t.Error("b.txt was uploaded despite the failing transform")
+	}
+}
+
+func TestPipelineContext(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("payload"))
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, map[string][]byte{})
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pipeline := &Pipeline{
+		After: func(stage string, state *PipelineState) error {
+			if stage == StageDownload {
+				cancel()
+			}
+			return nil
+		},
+	}
+	_, err := pipeline.RunContext(ctx, auth, origin.URL+"/a.txt", func(name string) string { return name }, ActionParams{URLPolicy: localOrigin})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RunContext() error = %v, want context.Canceled", err)
+	}
+	if _, ok := server.file("a.txt"); ok {
+		t.Error("a.txt was uploaded after the context was cancelled")
+	}
+}
+
+func TestPipelineStrict(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("payload of " + r.URL.Path))
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, map[string][]byte{
+		"same.txt":    []byte("payload of /same.txt"),
+		"changed.txt": []byte("PAYLOAD OF /CHANGED.TXT"),
+	})
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	remotePathFn := func(name string) string { return name }
+
+	// Without strict mode a file of the same size is trusted
+	if _, err := SaveAndShare(auth, origin.URL+"/changed.txt", remotePathFn, ActionParams{URLPolicy: localOrigin}); err != nil {
+		t.Fatalf("SaveAndShare() error = %v", err)
+	}
+
+	_, err := SaveAndShare(auth, origin.URL+"/changed.txt", remotePathFn, ActionParams{Strict: true, URLPolicy: localOrigin})
+	var mismatch *ContentMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, ErrContentMismatch) {
+		t.Fatalf("SaveAndShare() strict error = %v, want ContentMismatchError", err)
+	}
+	if mismatch.RemotePath != "changed.txt" || mismatch.LocalSHA256 == "" || mismatch.LocalSHA256 == mismatch.RemoteSHA256 {
+		t.Errorf("mismatch report = %+v", mismatch)
+	}
+	if content, _ := server.file("changed.txt"); string(content) != "PAYLOAD OF /CHANGED.TXT" {
+		t.Errorf("remote file = %q, want it untouched", content)
+	}
+
+	if _, err := SaveAndShare(auth, origin.URL+"/same.txt", remotePathFn, ActionParams{Strict: true, URLPolicy: localOrigin}); err != nil {
+		t.Errorf("SaveAndShare() strict of identical file error = %v", err)
+	}
+}
+
+func TestPipelineMaxDownloadBytes(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	payload := strings.Repeat("x", 64*1024)
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/streamed.txt" {
+			// Without a Content-Length the limit is only noticed while streaming
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte(payload))
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, map[string][]byte{})
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	remotePathFn := func(name string) string { return name }
+	params := ActionParams{MaxDownloadBytes: 1024, URLPolicy: localOrigin}
+
+	for _, name := range []string{"announced.txt", "streamed.txt"} {
+		_, err := SaveAndShare(auth, origin.URL+"/"+name, remotePathFn, params)
+		var tooLarge *DownloadTooLargeError
+		if !errors.As(err, &tooLarge) || !errors.Is(err, ErrDownloadTooLarge) || tooLarge.Limit != 1024 {
+			t.Errorf("SaveAndShare() of %s error = %v, want DownloadTooLargeError", name, err)
+		}
+		if _, ok := server.file(name); ok {
+			t.Errorf("%s was uploaded despite exceeding the limit", name)
+		}
+	}
+	if entries, _ := os.ReadDir(StagingDir()); len(entries) != 0 {
+		t.Errorf("download left %d files behind", len(entries))
+	}
+
+	params.MaxDownloadBytes = int64(len(payload))
+	if _, err := SaveAndShare(auth, origin.URL+"/exact.txt", remotePathFn, params); err != nil {
+		t.Errorf("SaveAndShare() of file at the limit error = %v", err)
+	}
+}
+
+func TestPipelineReuseShare(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("payload of " + r.URL.Path))
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, nil)
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	remotePathFn := func(name string) string { return name }
+
+	first, err := SaveAndShare(auth, origin.URL+"/a.txt", remotePathFn, ActionParams{ReuseShare: true, URLPolicy: localOrigin})
+	if err != nil {
+		t.Fatalf("SaveAndShare() error = %v", err)
+	}
+	if first.Reused || first.CreatedAt.IsZero() {
+		t.Errorf("first SaveAndShare() Reused = %v, CreatedAt = %v, want a new share", first.Reused, first.CreatedAt)
+	}
+
+	second, err := SaveAndShare(auth, origin.URL+"/a.txt", remotePathFn, ActionParams{ReuseShare: true, URLPolicy: localOrigin})
+	if err != nil {
+		t.Fatalf("SaveAndShare() error = %v", err)
+	}
+	if !second.Reused || second.ViewUrl != first.ViewUrl || !second.CreatedAt.IsZero() {
+		t.Errorf("second SaveAndShare() = %+v, want the reused share %v", second, first.ViewUrl)
+	}
+
+	// A permanent share does not stand in for an expiring one
+	params := ActionParams{ReuseShare: true, URLPolicy: localOrigin, ShareParams: ShareParams{Expires: 1, Unit: "hours"}}
+	third, err := SaveAndShare(auth, origin.URL+"/a.txt", remotePathFn, params)
+	if err != nil {
+		t.Fatalf("SaveAndShare() error = %v", err)
+	}
+	if third.Reused || third.ViewUrl == first.ViewUrl {
+		t.Errorf("SaveAndShare() with expiry reused %v", third.ViewUrl)
+	}
+}
+
+func TestPipelineReplace(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("payload of " + r.URL.Path))
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("old")})
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	remotePathFn := func(name string) string { return name }
+	params := ActionParams{Force: true, URLPolicy: localOrigin}
+
+	assertOnly := func(want string) {
+		t.Helper()
+		if content, _ := server.file("a.txt"); string(content) != want {
+			t.Errorf("remote file = %q, want %q", content, want)
+		}
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		if len(server.files) != 1 {
+			t.Errorf("remote files = %d, want no temporary file left behind", len(server.files))
+		}
+	}
+
+	// A failed upload keeps the existing file
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && strings.Contains(r.URL.Path, ".upload-") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+	if _, err := SaveAndShare(auth, origin.URL+"/a.txt", remotePathFn, params); err == nil {
+		t.Fatal("SaveAndShare() with failing upload succeeded")
+	}
+	assertOnly("old")
+
+	server.Config.Handler = next
+	result, err := SaveAndShare(auth, origin.URL+"/a.txt", remotePathFn, params)
+	if err != nil {
+		t.Fatalf("SaveAndShare() error = %v", err)
+	}
+	if result.RemotePath != "a.txt" {
+		t.Errorf("RemotePath = %v, want a.txt", result.RemotePath)
+	}
+	assertOnly("payload of /a.txt")
+}
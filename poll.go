@@ -0,0 +1,112 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// Default backoff applied by Poll when PollOptions fields are zero
+const (
+	defaultPollInitialInterval = 500 * time.Millisecond
+	defaultPollMaxInterval     = 10 * time.Second
+	defaultPollMultiplier      = 2.0
+	defaultPollJitter          = 0.2
+)
+
+// PollFunc checks the state of a long running operation. It returns true once the
+// operation completed; a non-nil error stops polling immediately.
+type PollFunc func(ctx context.Context) (done bool, err error)
+
+// PollOptions configures the backoff between Poll attempts. Zero fields use defaults.
+type PollOptions struct {
+	// InitialInterval is the delay after the first unsuccessful attempt
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between attempts
+	MaxInterval time.Duration
+	// Multiplier grows the delay after each unsuccessful attempt
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction in either direction
+	Jitter float64
+}
+
+// withDefaults returns the options with zero fields replaced by defaults
+func (o PollOptions) withDefaults() PollOptions {
+	if o.InitialInterval <= 0 {
+		o.InitialInterval = defaultPollInitialInterval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = defaultPollMaxInterval
+	}
+	if o.MaxInterval < o.InitialInterval {
+		o.MaxInterval = o.InitialInterval
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = defaultPollMultiplier
+	}
+	if o.Jitter <= 0 {
+		o.Jitter = defaultPollJitter
+	}
+	if o.Jitter > 1 {
+		o.Jitter = 1
+	}
+	return o
+}
+
+// Poll calls fn until it reports completion, returns an error or ctx is done, waiting with
+// jittered exponential backoff between attempts.
+func Poll(ctx context.Context, fn PollFunc, opts PollOptions) error {
+	if fn == nil {
+		return fmt.Errorf("poll function cannot be nil")
+	}
+	opts = opts.withDefaults()
+
+	interval := opts.InitialInterval
+	for {
+		done, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		timer := time.NewTimer(jitter(interval, opts.Jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("polling stopped: %w", ctx.Err())
+		case <-timer.C:
+		}
+
+		interval = min(time.Duration(float64(interval)*opts.Multiplier), opts.MaxInterval)
+	}
+}
+
+// jitter randomizes d by up to the given fraction in either direction
+func jitter(d time.Duration, fraction float64) time.Duration {
+	return time.Duration(float64(d) * (1 + fraction*(2*rand.Float64()-1)))
+}
+
+// WaitReady polls the server health endpoint until it reports healthy or ctx is done, for
+// use after starting or restarting a Filebrowser instance.
+func (c *Client) WaitReady(ctx context.Context, opts PollOptions) (err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if c.URL == "" {
+		return fmt.Errorf("URL cannot be empty")
+	}
+
+	client := c.http()
+	url := fmt.Sprintf("%s/health", c.URL)
+	return Poll(ctx, func(ctx context.Context) (bool, error) {
+		resp, err := client.R().SetContext(ctx).Get(url)
+		if err != nil {
+			c.log().Debug("Filebrowser not ready yet", "error", err)
+			return false, nil
+		}
+		return resp.StatusCode == http.StatusOK, nil
+	}, opts)
+}
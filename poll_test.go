@@ -0,0 +1,73 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	opts := PollOptions{InitialInterval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+
+	t.Run("Completes", func(t *testing.T) {
+		attempts := 0
+		err := Poll(context.Background(), func(context.Context) (bool, error) {
+			attempts++
+			return attempts == 3, nil
+		}, opts)
+		if err != nil || attempts != 3 {
+			t.Errorf("Poll() error = %v after %d attempts, want nil after 3", err, attempts)
+		}
+	})
+
+	t.Run("Stops on error", func(t *testing.T) {
+		errPermanent := errors.New("permanent")
+		err := Poll(context.Background(), func(context.Context) (bool, error) {
+			return false, errPermanent
+		}, opts)
+		if !errors.Is(err, errPermanent) {
+			t.Errorf("Poll() error = %v, want %v", err, errPermanent)
+		}
+	})
+
+	t.Run("Stops on context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := Poll(ctx, func(context.Context) (bool, error) { return false, nil }, opts)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Poll() error = %v, want deadline exceeded", err)
+		}
+	})
+}
+
+func TestPollOptionsDefaults(t *testing.T) {
+	opts := PollOptions{InitialInterval: time.Minute, Jitter: 3}.withDefaults()
+	if opts.MaxInterval != time.Minute || opts.Multiplier != defaultPollMultiplier || opts.Jitter != 1 {
+		t.Errorf("withDefaults() = %+v", opts)
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	var checks atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" || checks.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL}
+	err := client.WaitReady(context.Background(), PollOptions{InitialInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("WaitReady() error = %v", err)
+	}
+	if checks.Load() != 3 {
+		t.Errorf("WaitReady() made %d checks, want 3", checks.Load())
+	}
+}
@@ -0,0 +1,164 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/imroc/req/v3"
+)
+
+// Preview sizes rendered by the server
+const (
+	PreviewThumb = "thumb"
+	PreviewBig   = "big"
+)
+
+// defaultWarmConcurrency is the number of concurrent preview requests when none is given
+const defaultWarmConcurrency = 4
+
+// PreviewReport summarizes a WarmPreviews run
+type PreviewReport struct {
+	Warmed      int              // Images whose previews were generated in every size
+	Unsupported []string         // Files skipped because the server cannot preview their type
+	Failed      map[string]error // Images whose previews failed, keyed by path
+}
+
+// WarmPreviews requests the previews of every image below root in the given sizes
+// (PreviewThumb and PreviewBig if empty), so galleries built on shares load instantly.
+// At most concurrency requests are made at once. progress, if set, is called with the
+// number of images done and found. Failures of single images are collected in the report.
+func (c *Client) WarmPreviews(root string, sizes []string, concurrency int, progress ProgressFunc) (_ *PreviewReport, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if len(sizes) == 0 {
+		sizes = []string{PreviewThumb, PreviewBig}
+	}
+	if concurrency <= 0 {
+		concurrency = defaultWarmConcurrency
+	}
+
+	report := &PreviewReport{Failed: map[string]error{}}
+	var images []string
+	err = c.Walk(root, func(resource *RespResource) error {
+		switch {
+		case resource.IsDir:
+		case resource.Type == "image":
+			images = append(images, resourcePath(resource.Path))
+		default:
+			report.Unsupported = append(report.Unsupported, resource.Path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		done  int64
+		slots = make(chan struct{}, concurrency)
+	)
+	for _, image := range images {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			var errs []error
+			for _, size := range sizes {
+				if err := c.warmPreview(size, image); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", size, err))
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err := errors.Join(errs...); err != nil {
+				report.Failed[image] = err
+			} else {
+				report.Warmed++
+			}
+			done++
+			if progress != nil {
+				progress(done, int64(len(images)))
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report, nil
+}
+
+// Preview is a preview image rendered by the server
+type Preview struct {
+	Data []byte
+	// ContentType is the media type sent by the server, or detected from Data if the
+	// server sent none
+	ContentType string
+}
+
+// GetPreview returns the preview of a remote image rendered by the server in the given
+// size, PreviewThumb or PreviewBig, e.g. to show thumbnails of uploaded media. Files the
+// server cannot preview fail with an error matching ErrNotFound.
+func (c *Client) GetPreview(remotePath string, size string) (_ *Preview, err error) {
+	var received int64
+	_, start := c.startOp(context.Background(), OpGetPreview, remotePath)
+	defer func() { err = c.finishOp(OpGetPreview, remotePath, received, start, err) }()
+
+	if size != PreviewThumb && size != PreviewBig {
+		return nil, fmt.Errorf("unsupported preview size %q", size)
+	}
+
+	resp, err := c.requestPreview(size, remotePath)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &Preview{Data: resp.Bytes(), ContentType: resp.Header.Get("Content-Type")}
+	if preview.ContentType == "" || preview.ContentType == "application/octet-stream" {
+		preview.ContentType = http.DetectContentType(preview.Data)
+	}
+	received = int64(len(preview.Data))
+	return preview, nil
+}
+
+// warmPreview requests the preview of a remote image in the given size
+func (c *Client) warmPreview(size string, remotePath string) (err error) {
+	_, start := c.startOp(context.Background(), OpGetPreview, remotePath)
+	defer func() { err = c.finishOp(OpGetPreview, remotePath, 0, start, err) }()
+
+	_, err = c.requestPreview(size, remotePath)
+	return err
+}
+
+// requestPreview requests the preview of a remote image in the given size, failing
+// unless the server rendered it
+func (c *Client) requestPreview(size string, remotePath string) (*req.Response, error) {
+	if err := c.ensureAuthenticated(); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	url, err := c.apiURL("preview/"+size, remotePath)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.newRequest(context.Background()).Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("preview request failed: %w", err)
+	}
+
+	if err := checkDenied(resp, remotePath); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("preview request failed with %w", newAPIError(resp))
+	}
+	return resp, nil
+}
@@ -0,0 +1,75 @@
+package filebrowser
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWarmPreviews(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"photos/a.jpg":        []byte("a"),
+		"photos/b.png":        []byte("b"),
+		"photos/album/c.gif":  []byte("c"),
+		"photos/notes.txt":    []byte("notes"),
+		"photos/album/raw.nf": []byte("raw"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	var lastDone, lastTotal int64
+	report, err := client.WarmPreviews("photos", nil, 2, func(done, total int64) {
+		lastDone, lastTotal = done, total
+	})
+	if err != nil {
+		t.Fatalf("WarmPreviews() error = %v", err)
+	}
+
+	if report.Warmed != 3 || len(report.Failed) != 0 {
+		t.Errorf("WarmPreviews() report = %+v, want 3 warmed", report)
+	}
+	if got := strings.Join(report.Unsupported, ","); got != "/photos/notes.txt,/photos/album/raw.nf" {
+		t.Errorf("Unsupported = %v", report.Unsupported)
+	}
+	if lastDone != 3 || lastTotal != 3 {
+		t.Errorf("progress = %d/%d, want 3/3", lastDone, lastTotal)
+	}
+	if server.previews != 6 {
+		t.Errorf("server rendered %d previews, want 6", server.previews)
+	}
+}
+
+func TestGetPreview(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"photos/a.jpg": []byte("a"), "notes.txt": []byte("notes")})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	preview, err := client.GetPreview("photos/a.jpg", PreviewThumb)
+	if err != nil {
+		t.Fatalf("GetPreview() error = %v", err)
+	}
+	if string(preview.Data) != "preview" || !strings.HasPrefix(preview.ContentType, "text/plain") {
+		t.Errorf("GetPreview() = %q (%s)", preview.Data, preview.ContentType)
+	}
+
+	// Servers sending no content type get it detected
+	png := []byte("\x89PNG\r\n\x1a\n")
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/preview/big/") {
+			w.Header()["Content-Type"] = nil
+			w.Write(png)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+	if preview, err = client.GetPreview("photos/a.jpg", PreviewBig); err != nil || preview.ContentType != "image/png" {
+		t.Errorf("GetPreview() = %+v, %v, want image/png", preview, err)
+	}
+
+	if _, err := client.GetPreview("notes.txt", PreviewThumb); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetPreview() of text file error = %v, want ErrNotFound", err)
+	}
+	if _, err := client.GetPreview("photos/a.jpg", "huge"); err == nil {
+		t.Error("GetPreview() accepted an unknown size")
+	}
+}
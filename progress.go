@@ -0,0 +1,215 @@
+package filebrowser
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// ProgressFunc is called as data is transferred with the number of bytes processed so far
+// and the expected total, or -1 if the total is unknown
+type ProgressFunc func(bytesDone int64, bytesTotal int64)
+
+// ProgressReader wraps an io.Reader and reports every read to a ProgressFunc
+type ProgressReader struct {
+	r     io.Reader
+	total int64
+	done  int64
+	fn    ProgressFunc
+}
+
+// NewProgressReader wraps r so that fn is called after every read.
+// total is the expected number of bytes, or -1 if unknown.
+func NewProgressReader(r io.Reader, total int64, fn ProgressFunc) *ProgressReader {
+	return &ProgressReader{r: r, total: total, fn: fn}
+}
+
+// Read implements io.Reader
+func (p *ProgressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.done += int64(n)
+		if p.fn != nil {
+			p.fn(p.done, p.total)
+		}
+	}
+	return n, err
+}
+
+// BytesRead returns the number of bytes read so far
+func (p *ProgressReader) BytesRead() int64 {
+	return p.done
+}
+
+// ProgressWriter wraps an io.Writer and reports every write to a ProgressFunc
+type ProgressWriter struct {
+	w     io.Writer
+	total int64
+	done  int64
+	fn    ProgressFunc
+}
+
+// NewProgressWriter wraps w so that fn is called after every write.
+// total is the expected number of bytes, or -1 if unknown.
+func NewProgressWriter(w io.Writer, total int64, fn ProgressFunc) *ProgressWriter {
+	return &ProgressWriter{w: w, total: total, fn: fn}
+}
+
+// Write implements io.Writer
+func (p *ProgressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.done += int64(n)
+		if p.fn != nil {
+			p.fn(p.done, p.total)
+		}
+	}
+	return n, err
+}
+
+// BytesWritten returns the number of bytes written so far
+func (p *ProgressWriter) BytesWritten() int64 {
+	return p.done
+}
+
+// transferProgress accumulates the bytes transferred by possibly concurrent chunks and
+// reports the running total to a ProgressFunc, if set. A nil transferProgress discards
+// reports.
+type transferProgress struct {
+	mu    sync.Mutex
+	fn    ProgressFunc
+	total int64
+	done  int64
+}
+
+// newTransferProgress returns a transferProgress reporting to fn, or nil if fn is nil
+func newTransferProgress(fn ProgressFunc, total int64) *transferProgress {
+	if fn == nil {
+		return nil
+	}
+	return &transferProgress{fn: fn, total: total}
+}
+
+// add records n more bytes transferred
+func (p *transferProgress) add(n int64) {
+	if p == nil || n <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	if p.fn != nil {
+		p.fn(p.done, p.total)
+	}
+}
+
+// received returns the bytes transferred so far
+func (p *transferProgress) received() int64 {
+	if p == nil {
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.done
+}
+
+// rateLimiter paces transfers to an average number of bytes per second.
+// It is safe for concurrent use, so one limiter can be shared by several streams.
+type rateLimiter struct {
+	mu        sync.Mutex
+	perSecond int64
+	start     time.Time
+	consumed  int64
+}
+
+// newRateLimiter creates a limiter for the given rate, or nil if the rate is unlimited
+func newRateLimiter(bytesPerSecond int64) *rateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{perSecond: bytesPerSecond}
+}
+
+// burst returns the largest amount of bytes transferred in one step
+func (l *rateLimiter) burst() int {
+	return int(max(l.perSecond/10, 1))
+}
+
+// wait records n transferred bytes and sleeps until the average rate is respected
+func (l *rateLimiter) wait(n int) {
+	l.mu.Lock()
+	if l.start.IsZero() {
+		l.start = time.Now()
+	}
+	l.consumed += int64(n)
+	due := l.start.Add(time.Duration(float64(l.consumed) / float64(l.perSecond) * float64(time.Second)))
+	l.mu.Unlock()
+
+	if delay := time.Until(due); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// rateLimitedReader limits the throughput of an io.Reader
+type rateLimitedReader struct {
+	r       io.Reader
+	limiter *rateLimiter
+}
+
+// NewRateLimitedReader wraps r so that reads don't exceed bytesPerSecond on average.
+// A non-positive rate returns r unchanged.
+func NewRateLimitedReader(r io.Reader, bytesPerSecond int64) io.Reader {
+	limiter := newRateLimiter(bytesPerSecond)
+	if limiter == nil {
+		return r
+	}
+	return &rateLimitedReader{r: r, limiter: limiter}
+}
+
+// Read implements io.Reader
+func (l *rateLimitedReader) Read(b []byte) (int, error) {
+	if len(b) > l.limiter.burst() {
+		b = b[:l.limiter.burst()]
+	}
+	n, err := l.r.Read(b)
+	if n > 0 {
+		l.limiter.wait(n)
+	}
+	return n, err
+}
+
+// rateLimitedWriter limits the throughput of an io.Writer
+type rateLimitedWriter struct {
+	w       io.Writer
+	limiter *rateLimiter
+}
+
+// NewRateLimitedWriter wraps w so that writes don't exceed bytesPerSecond on average.
+// A non-positive rate returns w unchanged.
+func NewRateLimitedWriter(w io.Writer, bytesPerSecond int64) io.Writer {
+	limiter := newRateLimiter(bytesPerSecond)
+	if limiter == nil {
+		return w
+	}
+	return &rateLimitedWriter{w: w, limiter: limiter}
+}
+
+// Write implements io.Writer, splitting large writes so the rate stays smooth
+func (l *rateLimitedWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		step := min(len(b), l.limiter.burst())
+		n, err := l.w.Write(b[:step])
+		written += n
+		if n > 0 {
+			l.limiter.wait(n)
+		}
+		if err != nil {
+			return written, err
+		}
+		b = b[step:]
+	}
+	return written, nil
+}
@@ -0,0 +1,87 @@
+package filebrowser
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestProgressReader(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 10000)
+
+	var calls int
+	var lastDone, lastTotal int64
+	reader := NewProgressReader(bytes.NewReader(content), int64(len(content)), func(done, total int64) {
+		calls++
+		lastDone, lastTotal = done, total
+	})
+
+	if _, err := io.CopyBuffer(struct{ io.Writer }{io.Discard}, reader, make([]byte, 1000)); err != nil {
+		t.Fatalf("Copy error = %v", err)
+	}
+
+	if calls != 10 {
+		t.Errorf("progress called %d times, want 10", calls)
+	}
+	if lastDone != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Errorf("last progress = %d/%d, want %d/%d", lastDone, lastTotal, len(content), len(content))
+	}
+	if reader.BytesRead() != int64(len(content)) {
+		t.Errorf("BytesRead() = %d, want %d", reader.BytesRead(), len(content))
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var lastDone int64
+	writer := NewProgressWriter(&buf, -1, func(done, total int64) {
+		lastDone = done
+		if total != -1 {
+			t.Errorf("total = %d, want -1", total)
+		}
+	})
+
+	writer.Write([]byte("hello "))
+	writer.Write([]byte("world"))
+
+	if lastDone != 11 || writer.BytesWritten() != 11 {
+		t.Errorf("progress = %d, BytesWritten() = %d, want 11", lastDone, writer.BytesWritten())
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 20000)
+
+	tests := []struct {
+		name string
+		copy func() (int64, error)
+	}{
+		{
+			name: "Reader",
+			copy: func() (int64, error) {
+				return io.Copy(io.Discard, NewRateLimitedReader(bytes.NewReader(content), 100000))
+			},
+		},
+		{
+			name: "Writer",
+			copy: func() (int64, error) {
+				return io.Copy(NewRateLimitedWriter(io.Discard, 100000), bytes.NewReader(content))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			n, err := tt.copy()
+			if err != nil || n != int64(len(content)) {
+				t.Fatalf("copy = %d, %v, want %d, nil", n, err, len(content))
+			}
+			// 20KB at 100KB/s takes at least 200ms
+			if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+				t.Errorf("copy took %v, want at least 150ms", elapsed)
+			}
+		})
+	}
+}
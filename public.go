@@ -0,0 +1,118 @@
+package filebrowser
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/imroc/req/v3"
+)
+
+// sharePasswordHeader carries the password of protected shares
+const sharePasswordHeader = "X-SHARE-PASSWORD"
+
+// SharePublicClient lists and downloads the files of a share without account credentials,
+// as anyone given a share link can
+type SharePublicClient struct {
+	URL      string // Filebrowser server
+	Hash     string // Hash of the share
+	Password string // Password of the share, if it is protected
+}
+
+// NewSharePublicClient creates a client for a share link as created by Share, e.g.
+// "https://files.example.com/share/abc123". The password may be empty.
+func NewSharePublicClient(shareURL string, password string) (*SharePublicClient, error) {
+	u, err := url.Parse(shareURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid share URL: %w", err)
+	}
+	base, hash, ok := strings.Cut(u.Path, "/share/")
+	hash, _, _ = strings.Cut(hash, "/")
+	if u.Scheme == "" || u.Host == "" || !ok || hash == "" {
+		return nil, fmt.Errorf("invalid share URL %q", shareURL)
+	}
+
+	u.Path, u.RawQuery, u.Fragment = base, "", ""
+	return &SharePublicClient{URL: u.String(), Hash: hash, Password: password}, nil
+}
+
+// Validate checks if the client configuration is valid
+func (s *SharePublicClient) Validate() error {
+	if s.URL == "" {
+		return fmt.Errorf("URL cannot be empty")
+	}
+	if s.Hash == "" {
+		return fmt.Errorf("share hash cannot be empty")
+	}
+	return nil
+}
+
+// newRequest creates a request carrying the share password
+func (s *SharePublicClient) newRequest() *req.Request {
+	r := traceTimeouts(defaultHTTPClient.R())
+	if s.Password != "" {
+		r.SetHeader(sharePasswordHeader, s.Password)
+	}
+	return r
+}
+
+// sharePath returns the URL of a path inside the share below the given API
+func (s *SharePublicClient) sharePath(api string, sharedPath string) string {
+	u := fmt.Sprintf("%s/api/public/%s/%s", strings.TrimSuffix(s.URL, "/"), api, s.Hash)
+	if sharedPath = strings.Trim(sharedPath, "/"); sharedPath != "" {
+		u += "/" + sharedPath
+	}
+	return u
+}
+
+// List retrieves information about the shared file, or a file or directory below a
+// shared directory given by its path relative to the share; "" is the share itself.
+// A wrong or missing password fails with an error matching ErrUnauthorized.
+func (s *SharePublicClient) List(sharedPath string) (*RespResource, error) {
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid share client configuration: %w", err)
+	}
+
+	var result RespResource
+	resp, err := s.newRequest().
+		SetSuccessResult(&result).
+		Get(s.sharePath("share", sharedPath))
+	if err != nil {
+		return nil, fmt.Errorf("share request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("share request failed with %w", newAPIError(resp))
+	}
+
+	return &result, nil
+}
+
+// Download writes the content of the shared file, or of a file below a shared directory
+// given by its path relative to the share, to w.
+// A wrong or missing password fails with an error matching ErrUnauthorized.
+func (s *SharePublicClient) Download(sharedPath string, w io.Writer) error {
+	if err := s.Validate(); err != nil {
+		return fmt.Errorf("invalid share client configuration: %w", err)
+	}
+	if w == nil {
+		return fmt.Errorf("writer cannot be nil")
+	}
+
+	resp, err := s.newRequest().
+		DisableAutoReadResponse().
+		Get(s.sharePath("dl", sharedPath))
+	if err != nil {
+		return fmt.Errorf("share download request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("share download request failed with %w", httpAPIError(resp.Response))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to download shared file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package filebrowser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSharePublicClient(t *testing.T) {
+	files := map[string]string{"a.txt": "a", "sub/b.txt": "b"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(sharePasswordHeader) != "pw" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		api, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/public/"), "/")
+		hash, name, _ := strings.Cut(rest, "/")
+		if hash != "abc" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch api {
+		case "share":
+			if name != "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(RespResource{Path: "/", IsDir: true, Items: []RespResource{
+				{Path: "/a.txt", Name: "a.txt", IsDir: false},
+				{Path: "/sub", Name: "sub", IsDir: true},
+			}})
+		case "dl":
+			content, ok := files[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(content))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	share, err := NewSharePublicClient(server.URL+"/share/abc", "pw")
+	if err != nil {
+		t.Fatalf("NewSharePublicClient() error = %v", err)
+	}
+
+	dir, err := share.List("")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(dir.Items) != 2 || dir.Items[0].Name != "a.txt" {
+		t.Errorf("List() = %+v, want the shared directory", dir)
+	}
+
+	var buf bytes.Buffer
+	if err := share.Download("/sub/b.txt", &buf); err != nil || buf.String() != "b" {
+		t.Errorf("Download() = %q, %v, want b", buf.String(), err)
+	}
+	if err := share.Download("missing.txt", &buf); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Download() of missing file error = %v, want ErrNotFound", err)
+	}
+
+	share.Password = "wrong"
+	if _, err := share.List(""); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("List() with wrong password error = %v, want ErrUnauthorized", err)
+	}
+	if err := share.Download("a.txt", &buf); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Download() with wrong password error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestNewSharePublicClient(t *testing.T) {
+	share, err := NewSharePublicClient("https://example.com/files/share/abc123?x=1", "")
+	if err != nil {
+		t.Fatalf("NewSharePublicClient() error = %v", err)
+	}
+	if share.URL != "https://example.com/files" || share.Hash != "abc123" {
+		t.Errorf("NewSharePublicClient() = %q, %q, want https://example.com/files and abc123", share.URL, share.Hash)
+	}
+
+	for _, link := range []string{"https://example.com/abc123", "https://example.com/share/", "/share/abc123"} {
+		if _, err := NewSharePublicClient(link, ""); err == nil {
+			t.Errorf("NewSharePublicClient(%q) succeeded, want error", link)
+		}
+	}
+}
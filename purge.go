@@ -0,0 +1,173 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Default API endpoints of the built-in purgers
+const (
+	defaultCloudflareEndpoint = "https://api.cloudflare.com/client/v4"
+	defaultFastlyEndpoint     = "https://api.fastly.com"
+)
+
+// cloudflarePurgeBatch is the largest number of URLs Cloudflare purges in one request
+const cloudflarePurgeBatch = 30
+
+// Purger invalidates the copies of public URLs cached by a CDN
+type Purger interface {
+	Purge(ctx context.Context, urls []string) error
+}
+
+// PurgerFunc adapts an ordinary function to the Purger interface
+type PurgerFunc func(ctx context.Context, urls []string) error
+
+// Purge calls f(ctx, urls)
+func (f PurgerFunc) Purge(ctx context.Context, urls []string) error {
+	return f(ctx, urls)
+}
+
+// PublicURLsFunc returns the public URLs serving a remote path, if any
+type PublicURLsFunc func(remotePath string) []string
+
+// PublicURLPrefix maps remote paths to URLs below base, for files published by a CDN
+// mirroring the tree
+func PublicURLPrefix(base string) PublicURLsFunc {
+	base = strings.TrimSuffix(base, "/")
+	return func(remotePath string) []string {
+		return []string{base + "/" + strings.TrimPrefix(remotePath, "/")}
+	}
+}
+
+// PurgeHook returns a PostOpHook purging the public URLs of files overwritten, moved, or
+// deleted through the client, so a CDN never serves stale copies. Purge failures are
+// logged as errors, the operation itself already succeeded.
+func (c *Client) PurgeHook(purger Purger, publicURLs PublicURLsFunc) PostOpHook {
+	return func(op OpResult) {
+		if op.Err != nil {
+			return
+		}
+
+		var changed []string
+		switch op.Operation {
+		case OpUpload, OpDeleteResource:
+			changed = []string{op.Path}
+		case OpMoveResource:
+			changed = []string{op.Path, op.Destination}
+		case OpCopyResource:
+			changed = []string{op.Destination}
+		}
+
+		var urls []string
+		for _, remotePath := range changed {
+			urls = append(urls, publicURLs(remotePath)...)
+		}
+		if len(urls) == 0 {
+			return
+		}
+
+		if err := purger.Purge(context.Background(), urls); err != nil {
+			c.log().Error("Failed to purge CDN cache", "path", op.Path, "error", err)
+			return
+		}
+		c.log().Info("Purged CDN cache", "path", op.Path, "urls", len(urls))
+	}
+}
+
+// CloudflarePurger purges URLs from the Cloudflare cache of a zone
+type CloudflarePurger struct {
+	ZoneID   string
+	APIToken string // Token with the Cache Purge permission
+	// Endpoint is the API base URL, the public Cloudflare API if empty
+	Endpoint string
+}
+
+// Purge implements Purger
+func (p CloudflarePurger) Purge(ctx context.Context, urls []string) error {
+	if p.ZoneID == "" || p.APIToken == "" {
+		return fmt.Errorf("cloudflare zone ID and API token cannot be empty")
+	}
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = defaultCloudflareEndpoint
+	}
+
+	for start := 0; start < len(urls); start += cloudflarePurgeBatch {
+		batch := urls[start:min(start+cloudflarePurgeBatch, len(urls))]
+
+		var result struct {
+			Success bool `json:"success"`
+			Errors  []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		resp, err := defaultHTTPClient.R().
+			SetContext(ctx).
+			SetBearerAuthToken(p.APIToken).
+			SetBody(map[string][]string{"files": batch}).
+			SetSuccessResult(&result).
+			SetErrorResult(&result).
+			Post(fmt.Sprintf("%s/zones/%s/purge_cache", strings.TrimSuffix(endpoint, "/"), p.ZoneID))
+		if err != nil {
+			return fmt.Errorf("cloudflare purge request failed: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK || !result.Success {
+			var messages []string
+			for _, e := range result.Errors {
+				messages = append(messages, e.Message)
+			}
+			return fmt.Errorf("cloudflare purge failed with status code %d: %s", resp.StatusCode, strings.Join(messages, "; "))
+		}
+	}
+	return nil
+}
+
+// FastlyPurger purges URLs from the Fastly cache, one request per URL
+type FastlyPurger struct {
+	APIToken string // Token with the purge_select scope
+	// Endpoint is the API base URL, the public Fastly API if empty
+	Endpoint string
+}
+
+// Purge implements Purger
+func (p FastlyPurger) Purge(ctx context.Context, urls []string) error {
+	if p.APIToken == "" {
+		return fmt.Errorf("fastly API token cannot be empty")
+	}
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = defaultFastlyEndpoint
+	}
+
+	var errs []error
+	for _, rawURL := range urls {
+		if err := p.purge(ctx, strings.TrimSuffix(endpoint, "/"), rawURL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// purge purges a single URL
+func (p FastlyPurger) purge(ctx context.Context, endpoint string, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid URL to purge: %q", rawURL)
+	}
+
+	resp, err := defaultHTTPClient.R().
+		SetContext(ctx).
+		SetHeader("Fastly-Key", p.APIToken).
+		Post(endpoint + "/purge/" + parsed.Host + parsed.EscapedPath())
+	if err != nil {
+		return fmt.Errorf("fastly purge request for %s failed: %w", rawURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fastly purge of %s failed with status code: %d", rawURL, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package filebrowser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCloudflarePurger(t *testing.T) {
+	var batches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/zones/zone1/purge_cache" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"success":false,"errors":[{"message":"Authentication error"}]}`))
+			return
+		}
+		var body struct {
+			Files []string `json:"files"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		batches = append(batches, body.Files)
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	var urls []string
+	for i := 0; i < 31; i++ {
+		urls = append(urls, fmt.Sprintf("https://cdn.example.com/%d.txt", i))
+	}
+	purger := CloudflarePurger{ZoneID: "zone1", APIToken: "token", Endpoint: server.URL}
+	if err := purger.Purge(context.Background(), urls); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if len(batches) != 2 || len(batches[0]) != 30 || len(batches[1]) != 1 {
+		t.Errorf("Purge() sent batches of %v URLs, want 30 and 1", batches)
+	}
+
+	purger.APIToken = "wrong"
+	if err := purger.Purge(context.Background(), urls[:1]); err == nil {
+		t.Error("Purge() with rejected token should fail")
+	}
+}
+
+func TestFastlyPurger(t *testing.T) {
+	var purged []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Fastly-Key") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		purged = append(purged, r.URL.Path)
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	purger := FastlyPurger{APIToken: "token", Endpoint: server.URL}
+	if err := purger.Purge(context.Background(), []string{"https://cdn.example.com/a.txt", "https://cdn.example.com/dir/b.txt"}); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	expected := []string{"/purge/cdn.example.com/a.txt", "/purge/cdn.example.com/dir/b.txt"}
+	if !reflect.DeepEqual(purged, expected) {
+		t.Errorf("Purge() requested %v, want %v", purged, expected)
+	}
+}
+
+func TestPurgeHook(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"public/a.txt": []byte("a")})
+
+	var purged []string
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	client.PostOpHook = client.PurgeHook(PurgerFunc(func(ctx context.Context, urls []string) error {
+		purged = append(purged, urls...)
+		return nil
+	}), PublicURLPrefix("https://cdn.example.com/"))
+
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("updated"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Upload(localPath, "public/a.txt"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if _, err := client.Share("public/a.txt", 0, "", ""); err != nil {
+		t.Fatalf("Share() error = %v", err)
+	}
+	if err := client.Move("public/a.txt", "public/b.txt", false); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+
+	expected := []string{
+		"https://cdn.example.com/public/a.txt",
+		"https://cdn.example.com/public/a.txt",
+		"https://cdn.example.com/public/b.txt",
+	}
+	if !reflect.DeepEqual(purged, expected) {
+		t.Errorf("purged %v, want %v", purged, expected)
+	}
+}
@@ -0,0 +1,23 @@
+package filebrowser
+
+import (
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// qrCodeSize is the width and height in pixels of the QR codes of shares
+const qrCodeSize = 256
+
+// QRCodePNG renders the view URL of the share as a QR code in PNG format, e.g. to print
+// it or show it to phones
+func (r *ShareResult) QRCodePNG() ([]byte, error) {
+	if r.ViewUrl == "" {
+		return nil, fmt.Errorf("share has no view URL")
+	}
+	png, err := qrcode.Encode(r.ViewUrl, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	return png, nil
+}
@@ -0,0 +1,142 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrQuotaExceeded is returned when usage below a directory exceeds its byte budget
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaPolicy decides how EnforceQuota keeps a directory within its budget
+type QuotaPolicy int
+
+const (
+	// QuotaDeleteOldest deletes the least recently modified files until usage fits
+	QuotaDeleteOldest QuotaPolicy = iota
+	// QuotaReject deletes nothing and fails with ErrQuotaExceeded, new uploads being
+	// rejected with CheckQuota instead
+	QuotaReject
+)
+
+// QuotaReport describes the usage below a directory and the files deleted to enforce it
+type QuotaReport struct {
+	Usage   int64    // Bytes used after enforcement
+	Limit   int64    // Byte budget
+	Deleted []string // Files deleted, oldest first
+	Freed   int64    // Bytes freed by the deleted files
+}
+
+// QuotaOptions contains optional parameters for EnforceQuotaWithOptions and
+// ExplainQuotaWithOptions
+type QuotaOptions struct {
+	// Filter, if set, limits the files counted against the budget and deleted to enforce
+	// it. Other files are kept and use none of the budget.
+	Filter Filter
+}
+
+// EnforceQuota measures the bytes used by the files below root and keeps them within
+// maxBytes according to policy. With QuotaReject an exceeded budget returns the report
+// together with an error matching ErrQuotaExceeded. QuotaDeleteOldest refuses roots the
+// client may not delete, see Client.MinDeleteDepth.
+func (c *Client) EnforceQuota(root string, maxBytes int64, policy QuotaPolicy) (*QuotaReport, error) {
+	return c.EnforceQuotaWithOptions(root, maxBytes, policy, QuotaOptions{})
+}
+
+// EnforceQuotaWithOptions keeps root within maxBytes like EnforceQuota, applying the given
+// options
+func (c *Client) EnforceQuotaWithOptions(root string, maxBytes int64, policy QuotaPolicy, opts QuotaOptions) (_ *QuotaReport, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("quota cannot be negative")
+	}
+	if policy != QuotaDeleteOldest && policy != QuotaReject {
+		return nil, fmt.Errorf("unsupported quota policy: %d", policy)
+	}
+	if policy == QuotaDeleteOldest {
+		if err := c.checkDestructive(root); err != nil {
+			return nil, err
+		}
+	}
+
+	files, usage, err := c.quotaUsage(root, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &QuotaReport{Usage: usage, Limit: maxBytes}
+	if usage <= maxBytes {
+		return report, nil
+	}
+	if policy == QuotaReject {
+		return report, fmt.Errorf("%s uses %d of %d bytes: %w", root, usage, maxBytes, ErrQuotaExceeded)
+	}
+
+	for _, file := range oldestOverQuota(files, usage, maxBytes) {
+		if err := c.DeleteResource(resourcePath(file.Path)); err != nil {
+			return report, fmt.Errorf("failed to delete %s: %w", file.Path, err)
+		}
+		report.Deleted = append(report.Deleted, file.Path)
+		report.Freed += file.Size
+		report.Usage -= file.Size
+	}
+
+	c.log().Info("Enforced quota", "path", root, "max_bytes", maxBytes, "deleted", len(report.Deleted))
+	return report, nil
+}
+
+// CheckQuota returns an error matching ErrQuotaExceeded if uploading incoming bytes below
+// root would exceed maxBytes. It is meant to be called before uploads into directories
+// governed by QuotaReject.
+func (c *Client) CheckQuota(root string, maxBytes int64, incoming int64) (err error) {
+	defer func() { err = c.redactError(err) }()
+
+	_, usage, err := c.quotaUsage(root, nil)
+	if err != nil {
+		return err
+	}
+	if usage+incoming > maxBytes {
+		return fmt.Errorf("uploading %d bytes to %s would use %d of %d bytes: %w", incoming, root, usage+incoming, maxBytes, ErrQuotaExceeded)
+	}
+	return nil
+}
+
+// oldestOverQuota returns the least recently modified files to delete for usage to fit
+// maxBytes, oldest first
+func oldestOverQuota(files []RespResource, usage int64, maxBytes int64) []RespResource {
+	// Oldest first, by path for files modified at the same time
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Modified.Equal(files[j].Modified) {
+			return files[i].Path < files[j].Path
+		}
+		return files[i].Modified.Before(files[j].Modified)
+	})
+	for i, file := range files {
+		if usage <= maxBytes {
+			return files[:i]
+		}
+		usage -= file.Size
+	}
+	return files
+}
+
+// quotaUsage walks root and returns its files matched by filter with their total size
+func (c *Client) quotaUsage(root string, filter Filter) ([]RespResource, int64, error) {
+	var files []RespResource
+	var usage int64
+	_, err := c.WalkWithOptions(root, func(resource *RespResource) error {
+		if resource.IsDir {
+			return nil
+		}
+
+		files = append(files, *resource)
+		usage += resource.Size
+		return nil
+	}, WalkOptions{Filter: filter})
+	if err != nil {
+		return nil, 0, err
+	}
+	return files, usage, nil
+}
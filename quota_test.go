@@ -0,0 +1,79 @@
+package filebrowser
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnforceQuota(t *testing.T) {
+	server := newMemServer(t, nil)
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	server.files = map[string]testFile{
+		"tenant/old.bin":     {content: make([]byte, 40), modified: base},
+		"tenant/sub/mid.bin": {content: make([]byte, 30), modified: base.Add(time.Hour)},
+		"tenant/new.bin":     {content: make([]byte, 50), modified: base.Add(2 * time.Hour)},
+		"other/big.bin":      {content: make([]byte, 500), modified: base},
+	}
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	report, err := client.EnforceQuota("tenant", 100, QuotaReject)
+	if !errors.Is(err, ErrQuotaExceeded) || report == nil || report.Usage != 120 {
+		t.Fatalf("EnforceQuota(QuotaReject) = %+v, %v, want usage 120 and ErrQuotaExceeded", report, err)
+	}
+	if err := client.CheckQuota("tenant", 200, 81); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("CheckQuota() error = %v, want ErrQuotaExceeded", err)
+	}
+	if err := client.CheckQuota("tenant", 200, 80); err != nil {
+		t.Errorf("CheckQuota() error = %v", err)
+	}
+
+	report, err = client.EnforceQuota("tenant", 60, QuotaDeleteOldest)
+	if err != nil {
+		t.Fatalf("EnforceQuota(QuotaDeleteOldest) error = %v", err)
+	}
+	if len(report.Deleted) != 2 || report.Deleted[0] != "/tenant/old.bin" || report.Deleted[1] != "/tenant/sub/mid.bin" || report.Freed != 70 || report.Usage != 50 {
+		t.Errorf("EnforceQuota(QuotaDeleteOldest) = %+v, want the two oldest files deleted", report)
+	}
+	if _, ok := server.file("tenant/new.bin"); !ok {
+		t.Error("newest file should be kept")
+	}
+	if _, ok := server.file("other/big.bin"); !ok {
+		t.Error("files outside the root should be kept")
+	}
+}
+
+func TestEnforceQuotaFilter(t *testing.T) {
+	server := newMemServer(t, nil)
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	server.files = map[string]testFile{
+		"logs/old.log":  {content: make([]byte, 40), modified: base},
+		"logs/keep.txt": {content: make([]byte, 500), modified: base},
+		"logs/mid.log":  {content: make([]byte, 30), modified: base.Add(time.Hour)},
+		"logs/new.log":  {content: make([]byte, 50), modified: base.Add(2 * time.Hour)},
+	}
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	opts := QuotaOptions{Filter: Extensions(".log")}
+
+	plan, err := client.ExplainQuotaWithOptions("logs", 60, opts)
+	if err != nil {
+		t.Fatalf("ExplainQuotaWithOptions() error = %v", err)
+	}
+	if len(plan.Operations) != 2 || plan.Operations[0].RemotePath != "logs/old.log" || plan.Operations[1].RemotePath != "logs/mid.log" {
+		t.Errorf("ExplainQuotaWithOptions() = %+v, want old.log and mid.log deleted", plan.Operations)
+	}
+
+	report, err := client.EnforceQuotaWithOptions("logs", 60, QuotaDeleteOldest, opts)
+	if err != nil {
+		t.Fatalf("EnforceQuotaWithOptions() error = %v", err)
+	}
+	if len(report.Deleted) != 2 || report.Usage != 50 {
+		t.Errorf("EnforceQuotaWithOptions() = %+v, want the two oldest logs deleted", report)
+	}
+	if _, ok := server.file("logs/keep.txt"); !ok {
+		t.Error("files excluded by the filter should be kept")
+	}
+	if _, ok := server.file("logs/new.log"); !ok {
+		t.Error("newest log should be kept")
+	}
+}
@@ -0,0 +1,55 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidPath is returned for remote paths that cannot be sent to the server, e.g.
+// paths with ".." segments
+var ErrInvalidPath = errors.New("invalid remote path")
+
+// cleanRemotePath normalizes a remote path relative to the user's root: duplicate slashes
+// and "." segments are dropped, as are leading and trailing slashes. ".." segments are
+// rejected rather than resolved, as they are never intended in a remote path.
+func cleanRemotePath(remotePath string) (string, error) {
+	var segments []string
+	for _, segment := range strings.Split(remotePath, "/") {
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			return "", fmt.Errorf("%q contains \"..\": %w", remotePath, ErrInvalidPath)
+		}
+		if strings.ContainsRune(segment, 0) {
+			return "", fmt.Errorf("%q contains a NUL byte: %w", remotePath, ErrInvalidPath)
+		}
+		segments = append(segments, segment)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// escapeRemotePath cleans a remote path and URL-escapes each of its segments, so spaces,
+// '#', '?', '%' and non-ASCII characters reach the server as part of the path
+func escapeRemotePath(remotePath string) (string, error) {
+	cleaned, err := cleanRemotePath(remotePath)
+	if err != nil {
+		return "", err
+	}
+	segments := strings.Split(cleaned, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// apiURL returns the URL of a remote path below an API endpoint, e.g. "resources"
+func (c *Client) apiURL(api string, remotePath string) (string, error) {
+	escaped, err := escapeRemotePath(remotePath)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/api/%s/%s", c.URL, api, escaped), nil
+}
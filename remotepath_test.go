@@ -0,0 +1,60 @@
+package filebrowser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEscapeRemotePath(t *testing.T) {
+	tests := map[string]string{
+		"a.txt":                "a.txt",
+		"/dir//sub/./a.txt/":   "dir/sub/a.txt",
+		"my files/a #1.txt":    "my%20files/a%20%231.txt",
+		"q?/100%.txt":          "q%3F/100%25.txt",
+		"photos/été/ü.jpg":     "photos/%C3%A9t%C3%A9/%C3%BC.jpg",
+		"/":                    "",
+		"dir/..hidden/a..b.md": "dir/..hidden/a..b.md",
+	}
+	for remotePath, want := range tests {
+		if got, err := escapeRemotePath(remotePath); err != nil || got != want {
+			t.Errorf("escapeRemotePath(%q) = %q, %v, want %q", remotePath, got, err, want)
+		}
+	}
+
+	for _, remotePath := range []string{"../etc/passwd", "dir/../../a.txt", "dir/..", "a\x00.txt"} {
+		if _, err := escapeRemotePath(remotePath); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("escapeRemotePath(%q) error = %v, want ErrInvalidPath", remotePath, err)
+		}
+	}
+}
+
+func TestSpecialCharacterPaths(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	localPath, content := writeTestFile(t, 16)
+
+	remotePath := "my files/report #1 (ünïcode).txt"
+	if _, err := client.Upload(localPath, remotePath); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if got, _ := server.file(remotePath); string(got) != string(content) {
+		t.Fatalf("uploaded %q to %q, want the file content", got, remotePath)
+	}
+	resource, err := client.GetResource("/my files//report #1 (ünïcode).txt")
+	if err != nil || resource.Size != int64(len(content)) {
+		t.Fatalf("GetResource() = %+v, %v, want the uploaded file", resource, err)
+	}
+	if _, err := client.Share(remotePath, 0, "", ""); err != nil {
+		t.Errorf("Share() error = %v", err)
+	}
+	if err := client.DeleteResource(remotePath); err != nil {
+		t.Fatalf("DeleteResource() error = %v", err)
+	}
+	if _, ok := server.file(remotePath); ok {
+		t.Error("file still exists after DeleteResource()")
+	}
+
+	if _, err := client.Upload(localPath, "../outside.txt"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Upload() outside the root error = %v, want ErrInvalidPath", err)
+	}
+}
@@ -0,0 +1,180 @@
+package filebrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"path"
+	"strings"
+	"time"
+)
+
+// shareExpiryLayout formats share expiry times in rendered messages
+const shareExpiryLayout = "2006-01-02 15:04 MST"
+
+// ShareRenderer formats a ShareResult as a message, e.g. for a chat platform
+type ShareRenderer interface {
+	Render(share *ShareResult) (string, error)
+}
+
+// ShareRendererFunc adapts an ordinary function to the ShareRenderer interface
+type ShareRendererFunc func(share *ShareResult) (string, error)
+
+// Render calls f(share)
+func (f ShareRendererFunc) Render(share *ShareResult) (string, error) {
+	return f(share)
+}
+
+// shareExpiry returns the time a share created at now with the given expiration expires,
+// the zero time if it never does. Filebrowser counts in hours when no unit is given.
+func shareExpiry(now time.Time, expires int64, unit string) time.Time {
+	if expires <= 0 {
+		return time.Time{}
+	}
+	var step time.Duration
+	switch unit {
+	case "seconds":
+		step = time.Second
+	case "minutes":
+		step = time.Minute
+	case "days":
+		step = 24 * time.Hour
+	default:
+		step = time.Hour
+	}
+	return now.Add(time.Duration(expires) * step)
+}
+
+// shareTitle returns the file name of a share, or a generic title if it is unknown
+func shareTitle(share *ShareResult) string {
+	if share.RemotePath == "" {
+		return "Shared file"
+	}
+	return path.Base(share.RemotePath)
+}
+
+// shareNotes returns the expiry and password notes of a share
+func shareNotes(share *ShareResult) []string {
+	var notes []string
+	if !share.Expires.IsZero() {
+		notes = append(notes, "Expires "+share.Expires.Format(shareExpiryLayout))
+	}
+	if share.PasswordProtected {
+		notes = append(notes, "Password protected, the password is sent separately")
+	}
+	return notes
+}
+
+// TextRenderer renders a share as plain text, one line per detail
+type TextRenderer struct{}
+
+// Render implements ShareRenderer
+func (TextRenderer) Render(share *ShareResult) (string, error) {
+	lines := []string{
+		shareTitle(share),
+		"View: " + share.ViewUrl,
+		"Download: " + share.DownloadUrl,
+	}
+	return strings.Join(append(lines, shareNotes(share)...), "\n"), nil
+}
+
+// MarkdownRenderer renders a share as Markdown
+type MarkdownRenderer struct{}
+
+// Render implements ShareRenderer
+func (MarkdownRenderer) Render(share *ShareResult) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**\n\n", markdownEscaper.Replace(shareTitle(share)))
+	fmt.Fprintf(&b, "[View](%s) | [Download](%s)\n", share.ViewUrl, share.DownloadUrl)
+	for _, note := range shareNotes(share) {
+		fmt.Fprintf(&b, "\n_%s_", note)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// markdownEscaper escapes the Markdown syntax characters of file names
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+)
+
+// HTMLRenderer renders a share as an HTML snippet
+type HTMLRenderer struct{}
+
+// Render implements ShareRenderer
+func (HTMLRenderer) Render(share *ShareResult) (string, error) {
+	var b strings.Builder
+	b.WriteString(`<div class="filebrowser-share">`)
+	fmt.Fprintf(&b, "<strong>%s</strong> ", html.EscapeString(shareTitle(share)))
+	fmt.Fprintf(&b, `<a href="%s">View</a> | <a href="%s">Download</a>`,
+		html.EscapeString(share.ViewUrl), html.EscapeString(share.DownloadUrl))
+	for _, note := range shareNotes(share) {
+		fmt.Fprintf(&b, "<br><small>%s</small>", html.EscapeString(note))
+	}
+	b.WriteString("</div>")
+	return b.String(), nil
+}
+
+// SlackRenderer renders a share as a Slack Block Kit message payload
+type SlackRenderer struct {
+	// Text is the notification fallback text, the file name if empty
+	Text string
+}
+
+// slackBlock is a Block Kit layout block
+type slackBlock struct {
+	Type     string        `json:"type"`
+	Text     *slackText    `json:"text,omitempty"`
+	Elements []slackObject `json:"elements,omitempty"`
+}
+
+// slackText is a Block Kit text object
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackObject is a Block Kit element of an actions or context block: a button with a
+// text object, or a text element of a context block with a string
+type slackObject struct {
+	Type string `json:"type"`
+	Text any    `json:"text"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Render implements ShareRenderer
+func (r SlackRenderer) Render(share *ShareResult) (string, error) {
+	text := r.Text
+	if text == "" {
+		text = shareTitle(share)
+	}
+
+	blocks := []slackBlock{
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: "*" + slackEscaper.Replace(shareTitle(share)) + "*"}},
+		{Type: "actions", Elements: []slackObject{
+			{Type: "button", Text: slackText{Type: "plain_text", Text: "View"}, URL: share.ViewUrl},
+			{Type: "button", Text: slackText{Type: "plain_text", Text: "Download"}, URL: share.DownloadUrl},
+		}},
+	}
+	if notes := shareNotes(share); len(notes) > 0 {
+		context := slackBlock{Type: "context"}
+		for _, note := range notes {
+			context.Elements = append(context.Elements, slackObject{Type: "mrkdwn", Text: slackEscaper.Replace(note)})
+		}
+		blocks = append(blocks, context)
+	}
+
+	var payload strings.Builder
+	encoder := json.NewEncoder(&payload)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(struct {
+		Text   string       `json:"text"`
+		Blocks []slackBlock `json:"blocks"`
+	}{Text: text, Blocks: blocks})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode slack message: %w", err)
+	}
+	return strings.TrimSuffix(payload.String(), "\n"), nil
+}
+
+// slackEscaper escapes the control characters of Slack mrkdwn
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
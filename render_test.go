@@ -0,0 +1,89 @@
+package filebrowser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShareRenderers(t *testing.T) {
+	expires := time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+	share := &ShareResult{
+		ViewUrl:           "https://files.example.com/share/abc",
+		DownloadUrl:       "https://files.example.com/api/public/dl/abc",
+		RemotePath:        "reports/q1_<final>.pdf",
+		Expires:           expires,
+		PasswordProtected: true,
+	}
+
+	tests := []struct {
+		name     string
+		renderer ShareRenderer
+		want     []string
+	}{
+		{name: "Text", renderer: TextRenderer{}, want: []string{
+			"q1_<final>.pdf\nView: https://files.example.com/share/abc\n",
+			"Expires 2026-01-02 15:04 UTC",
+			"Password protected",
+		}},
+		{name: "Markdown", renderer: MarkdownRenderer{}, want: []string{
+			`**q1\_<final>.pdf**`,
+			"[View](https://files.example.com/share/abc) | [Download](https://files.example.com/api/public/dl/abc)",
+			"_Expires 2026-01-02 15:04 UTC_",
+		}},
+		{name: "HTML", renderer: HTMLRenderer{}, want: []string{
+			"<strong>q1_&lt;final&gt;.pdf</strong>",
+			`<a href="https://files.example.com/api/public/dl/abc">Download</a>`,
+			"<small>Password protected",
+		}},
+		{name: "Slack", renderer: SlackRenderer{}, want: []string{
+			`"text":"q1_<final>.pdf"`,
+			`*q1_&lt;final&gt;.pdf*`,
+			`"url":"https://files.example.com/share/abc"`,
+			`"type":"context"`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.renderer.Render(share)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Render() = %s\nwant it to contain %s", got, want)
+				}
+			}
+		})
+	}
+
+	payload, _ := SlackRenderer{}.Render(&ShareResult{ViewUrl: "v", DownloadUrl: "d"})
+	var message struct {
+		Blocks []map[string]any `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(payload), &message); err != nil || len(message.Blocks) != 2 {
+		t.Errorf("Slack payload without notes = %s, %v, want 2 blocks", payload, err)
+	}
+}
+
+func TestShareExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expires int64
+		unit    string
+		want    time.Time
+	}{
+		{expires: 0, unit: "days", want: time.Time{}},
+		{expires: 90, unit: "seconds", want: now.Add(90 * time.Second)},
+		{expires: 2, unit: "hours", want: now.Add(2 * time.Hour)},
+		{expires: 2, unit: "", want: now.Add(2 * time.Hour)},
+		{expires: 3, unit: "days", want: now.AddDate(0, 0, 3)},
+	}
+	for _, tt := range tests {
+		if got := shareExpiry(now, tt.expires, tt.unit); !got.Equal(tt.want) {
+			t.Errorf("shareExpiry(%d, %q) = %v, want %v", tt.expires, tt.unit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package filebrowser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eventials/go-tus"
+)
+
+// errUploadGone is returned when the server no longer knows an upload URL
+var errUploadGone = errors.New("upload no longer exists")
+
+// FileUploadStore is a tus.Store backed by a JSON file, mapping the fingerprints of
+// unfinished uploads to their upload URLs so they can be resumed by later runs. Failures
+// to persist the store only disable resumption, so they are not reported.
+type FileUploadStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileUploadStore creates an upload store persisted at the given path.
+// The file is created on the first upload.
+func NewFileUploadStore(path string) *FileUploadStore {
+	return &FileUploadStore{path: path}
+}
+
+// Get returns the upload URL recorded for a fingerprint
+func (s *FileUploadStore) Get(fingerprint string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uploads, err := s.load()
+	if err != nil {
+		return "", false
+	}
+	url, ok := uploads[fingerprint]
+	return url, ok
+}
+
+// Set records the upload URL of a fingerprint
+func (s *FileUploadStore) Set(fingerprint string, url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uploads, err := s.load()
+	if err != nil {
+		return
+	}
+	uploads[fingerprint] = url
+	s.store(uploads)
+}
+
+// Delete forgets a fingerprint
+func (s *FileUploadStore) Delete(fingerprint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uploads, err := s.load()
+	if err != nil {
+		return
+	}
+	if _, ok := uploads[fingerprint]; !ok {
+		return
+	}
+	delete(uploads, fingerprint)
+	s.store(uploads)
+}
+
+// Close implements tus.Store, the store holds no resources
+func (s *FileUploadStore) Close() {}
+
+// load reads all uploads from disk
+func (s *FileUploadStore) load() (map[string]string, error) {
+	uploads := map[string]string{}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return uploads, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &uploads); err != nil {
+		return nil, fmt.Errorf("failed to decode upload store: %w", err)
+	}
+	return uploads, nil
+}
+
+// store writes all uploads to disk, replacing the file atomically
+func (s *FileUploadStore) store(uploads map[string]string) error {
+	if err := writeJSONFileAtomic(s.path, uploads); err != nil {
+		return fmt.Errorf("failed to write upload store: %w", err)
+	}
+	return nil
+}
+
+// uploadFingerprint identifies an upload of a local file to a remote path. It changes
+// whenever the local file is modified, so stale uploads are never resumed.
+func uploadFingerprint(localPath string, info os.FileInfo, remotePath string) string {
+	if abs, err := filepath.Abs(localPath); err == nil {
+		localPath = abs
+	}
+	return fmt.Sprintf("%s|%d|%s|%s", localPath, info.Size(), info.ModTime().UTC().Format(time.RFC3339Nano), remotePath)
+}
+
+// resumeStoredUpload returns an uploader continuing the upload recorded in the client's
+// upload store for fingerprint, or nil if there is none the server still knows
+func (c *Client) resumeStoredUpload(tusClient *tus.Client, upload *tus.Upload, fingerprint string) (*tus.Uploader, error) {
+	uploadURL, ok := c.UploadStore.Get(fingerprint)
+	if !ok {
+		return nil, nil
+	}
+
+	offset, err := uploadOffset(tusClient, uploadURL)
+	if errors.Is(err, errUploadGone) {
+		c.UploadStore.Delete(fingerprint)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.log().Info("Resuming upload", "url", uploadURL, "offset", offset)
+	return tus.NewUploader(tusClient, uploadURL, upload, offset), nil
+}
+
+// ResumeUpload continues the unfinished upload of a local file at a known TUS upload URL,
+// e.g. one listed by the session store, from the offset the server received so far
+func (c *Client) ResumeUpload(localPath string, uploadURL string) (err error) {
+	var size int64
+	ctx, start := c.startOp(context.Background(), OpUpload, uploadURL)
+	retry := c.newRetrier(ctx)
+	defer func() { err = c.finishRetriedOp(OpUpload, uploadURL, size, start, retry, err) }()
+
+	if localPath == "" || uploadURL == "" {
+		return fmt.Errorf("local path and upload URL cannot be empty")
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat local file: %w", err)
+	}
+	size = info.Size()
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	config := c.newTusConfig(ctx)
+	tusClient, err := tus.NewClient(uploadURL, config)
+	if err != nil {
+		return fmt.Errorf("failed to create TUS client: %w", err)
+	}
+
+	offset, err := uploadOffset(tusClient, uploadURL)
+	if err != nil {
+		return err
+	}
+	if offset > size {
+		return fmt.Errorf("upload at %s is larger than the local file", uploadURL)
+	}
+
+	algo := discoverTus(tusClient, c.log()).checksumAlgorithm()
+	uploader := tus.NewUploader(tusClient, uploadURL, tus.NewUpload(file, size, nil, ""), offset)
+	if err := uploadChunks(uploader, config, file, size, algo, nil, retry); err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+
+	if c.Sessions != nil {
+		if err := c.Sessions.Delete(uploadURL); err != nil {
+			c.log().Warn("Failed to remove upload session", "url", uploadURL, "error", err)
+		}
+	}
+
+	c.log().Info("Resumed upload", "url", uploadURL, "bytes", size-offset)
+	return nil
+}
+
+// uploadOffset asks the server how many bytes of an upload it received
+func uploadOffset(tusClient *tus.Client, uploadURL string) (int64, error) {
+	request, err := http.NewRequest(http.MethodHead, uploadURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := tusClient.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("offset request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+	case http.StatusNotFound, http.StatusGone, http.StatusForbidden:
+		return 0, fmt.Errorf("%s: %w", uploadURL, errUploadGone)
+	default:
+		return 0, fmt.Errorf("offset request failed with %w", httpAPIError(resp))
+	}
+
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid upload offset %q: %w", resp.Header.Get("Upload-Offset"), err)
+	}
+	return offset, nil
+}
@@ -0,0 +1,93 @@
+package filebrowser
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUploadResume(t *testing.T) {
+	localPath, content := writeTestFile(t, 5*1024*1024)
+
+	tusServer := &tusTestServer{corruptFrom: 2 * 1024 * 1024}
+	server := httptest.NewServer(tusServer)
+	defer server.Close()
+
+	store := NewFileUploadStore(filepath.Join(t.TempDir(), "uploads.json"))
+	client := &Client{
+		URL:         server.URL,
+		ReqLogin:    ReqLogin{Username: "user", Password: "pass"},
+		UploadStore: store,
+	}
+	opts := UploadOptions{Checksum: "sha256", Resume: true}
+
+	// The interrupted upload is remembered after its first chunk
+	if _, err := client.UploadWithOptions(localPath, "dir/upload.bin", opts); err == nil {
+		t.Fatal("UploadWithOptions() should fail on checksum mismatch")
+	}
+	info, err := os.Stat(localPath)
+	if err != nil {
+		t.Fatalf("Failed to stat local file: %v", err)
+	}
+	fingerprint := uploadFingerprint(localPath, info, "dir/upload.bin")
+	if url, ok := store.Get(fingerprint); !ok || url != server.URL+"/api/tus/dir/upload.bin" {
+		t.Fatalf("Get() = %q, %v, want the upload URL", url, ok)
+	}
+
+	// A restarted upload would append the first chunk twice
+	tusServer.corruptFrom = -1
+	var progress int64
+	opts.Progress = func(done, total int64) { progress = done }
+	result, err := client.UploadWithOptions(localPath, "dir/upload.bin", opts)
+	if err != nil {
+		t.Fatalf("UploadWithOptions() error = %v", err)
+	}
+	if !bytes.Equal(tusServer.data.Bytes(), content) {
+		t.Error("uploaded content does not match local file")
+	}
+	if want := sha256Hex(content); result.Checksum != want {
+		t.Errorf("Checksum = %v, want %v", result.Checksum, want)
+	}
+	if progress != int64(len(content)) {
+		t.Errorf("progress = %d, want %d", progress, len(content))
+	}
+	if _, ok := store.Get(fingerprint); ok {
+		t.Error("completed upload should be removed from the store")
+	}
+}
+
+func TestResumeUpload(t *testing.T) {
+	localPath, content := writeTestFile(t, 5*1024*1024)
+
+	tusServer := &tusTestServer{corruptFrom: 4 * 1024 * 1024}
+	server := httptest.NewServer(tusServer)
+	defer server.Close()
+
+	sessions := NewFileSessionStore(filepath.Join(t.TempDir(), "sessions.json"))
+	client := &Client{
+		URL:      server.URL,
+		ReqLogin: ReqLogin{Username: "user", Password: "pass"},
+		Sessions: sessions,
+	}
+
+	if _, err := client.Upload(localPath, "dir/upload.bin"); err == nil {
+		t.Fatal("Upload() should fail on checksum mismatch")
+	}
+	list, err := sessions.List()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List() = %+v, %v, want one session", list, err)
+	}
+
+	tusServer.corruptFrom = -1
+	if err := client.ResumeUpload(localPath, list[0].URL); err != nil {
+		t.Fatalf("ResumeUpload() error = %v", err)
+	}
+	if !bytes.Equal(tusServer.data.Bytes(), content) {
+		t.Error("uploaded content does not match local file")
+	}
+	if list, _ := sessions.List(); len(list) != 0 {
+		t.Errorf("List() after resume = %+v, want empty", list)
+	}
+}
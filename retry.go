@@ -0,0 +1,227 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/eventials/go-tus"
+	"github.com/imroc/req/v3"
+)
+
+// Default backoff applied by RetryPolicy when its fields are zero
+const (
+	defaultRetryInitialBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff     = 10 * time.Second
+	defaultRetryMultiplier     = 2.0
+	defaultRetryJitter         = 0.2
+)
+
+// DefaultRetryableStatusCodes are retried when RetryPolicy.RetryableStatusCodes is nil.
+// They are the transient failures reported by reverse proxies in front of the server.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy retries requests failing with a transient status code, waiting with jittered
+// exponential backoff between attempts. Zero fields use defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one, 1 or less disables retries
+	MaxAttempts int
+	// InitialBackoff is the delay after the first failed attempt
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each failed attempt
+	Multiplier float64
+	// RetryableStatusCodes are the status codes retried, DefaultRetryableStatusCodes if nil
+	RetryableStatusCodes []int
+}
+
+// withDefaults returns the policy with zero fields replaced by defaults
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = defaultRetryMultiplier
+	}
+	if p.RetryableStatusCodes == nil {
+		p.RetryableStatusCodes = DefaultRetryableStatusCodes
+	}
+	return p
+}
+
+// retryable reports whether a response with the given status code is retried
+func (p RetryPolicy) retryable(statusCode int) bool {
+	return slices.Contains(p.RetryableStatusCodes, statusCode)
+}
+
+// backoff returns the delay after the given failed attempt, counted from 1
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay = time.Duration(float64(delay) * p.Multiplier)
+	}
+	return jitter(min(delay, p.MaxBackoff), defaultRetryJitter)
+}
+
+// AttemptInfo describes one HTTP attempt made by an operation
+type AttemptInfo struct {
+	Status      int           // Response status code, zero if no response was received or it is unknown
+	Duration    time.Duration // Wall time of the attempt
+	Err         error         // Error the attempt failed with, if any
+	RetryReason string        // Why the attempt was retried, empty if it was not
+}
+
+// attemptLog records the attempts of one operation. It is safe for concurrent use, as the
+// parts of a parallel upload share it; a nil log records nothing.
+type attemptLog struct {
+	mu       sync.Mutex
+	verbose  bool // Keep every attempt, otherwise only retries are counted
+	attempts []AttemptInfo
+	retries  int
+}
+
+// record adds an attempt to the log
+func (l *attemptLog) record(info AttemptInfo) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if info.RetryReason != "" {
+		l.retries++
+	}
+	if l.verbose {
+		l.attempts = append(l.attempts, info)
+	}
+}
+
+// list returns the recorded attempts with their errors scrubbed by redact, nil unless verbose
+func (l *attemptLog) list(redact func(error) error) []AttemptInfo {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.attempts) == 0 {
+		return nil
+	}
+	attempts := make([]AttemptInfo, len(l.attempts))
+	for i, info := range l.attempts {
+		info.Err = redact(info.Err)
+		attempts[i] = info
+	}
+	return attempts
+}
+
+// retryCount returns the number of retried attempts
+func (l *attemptLog) retryCount() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.retries
+}
+
+// retrier applies the retry policy of a client to the requests of one operation and
+// records their attempts. The zero value makes a single attempt and records nothing.
+type retrier struct {
+	ctx      context.Context
+	policy   RetryPolicy
+	log      Logger
+	attempts *attemptLog
+}
+
+// newRetrier creates a retrier for an operation running under ctx
+func (c *Client) newRetrier(ctx context.Context) retrier {
+	attempts := &attemptLog{verbose: c.Verbose}
+	if c.Retry == nil || c.Retry.MaxAttempts <= 1 {
+		return retrier{attempts: attempts}
+	}
+	return retrier{ctx: ctx, policy: c.Retry.withDefaults(), log: c.log(), attempts: attempts}
+}
+
+// send calls send until it returns an error, a status code that is not retryable, or the
+// attempts are exhausted, and returns the last response
+func (r retrier) send(send func() (*req.Response, error)) (*req.Response, error) {
+	for attempt := 1; ; attempt++ {
+		begin := time.Now()
+		resp, err := send()
+		info := AttemptInfo{Duration: time.Since(begin), Err: err}
+		if resp != nil && resp.Response != nil {
+			info.Status = resp.StatusCode
+		}
+
+		retry := err == nil && r.policy.retryable(info.Status) && attempt < r.policy.MaxAttempts
+		if retry {
+			info.RetryReason = fmt.Sprintf("status code %d", info.Status)
+		}
+		r.attempts.record(info)
+		if !retry {
+			return resp, err
+		}
+		if err := r.wait(attempt, info.Status); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tus calls fn until it succeeds, fails with an error other than a retryable TUS client
+// error, or the attempts are exhausted. Only the status codes of failures are known.
+func (r retrier) tus(fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		begin := time.Now()
+		err := fn()
+		info := AttemptInfo{Duration: time.Since(begin), Err: err}
+		var clientErr tus.ClientError
+		isClientErr := errors.As(err, &clientErr)
+		if isClientErr {
+			info.Status = clientErr.Code
+		}
+
+		retry := isClientErr && r.policy.retryable(clientErr.Code) && attempt < r.policy.MaxAttempts
+		if retry {
+			info.RetryReason = fmt.Sprintf("status code %d", clientErr.Code)
+		}
+		r.attempts.record(info)
+		if !retry {
+			return err
+		}
+		if err := r.wait(attempt, clientErr.Code); err != nil {
+			return err
+		}
+	}
+}
+
+// wait sleeps for the backoff of the given failed attempt, or until the context is done
+func (r retrier) wait(attempt int, statusCode int) error {
+	delay := r.policy.backoff(attempt)
+	r.log.Warn("Request failed, retrying", "status", statusCode, "delay", delay, "attempt", attempt+1, "max_attempts", r.policy.MaxAttempts)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("retry stopped: %w", r.ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
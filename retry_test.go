@@ -0,0 +1,135 @@
+package filebrowser
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// failFirst makes the first n requests of the given method below prefix fail with status
+func failFirst(server *memServer, method string, prefix string, n int, status int) *int {
+	var mu sync.Mutex
+	failed := 0
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fail := r.Method == method && strings.HasPrefix(r.URL.Path, prefix) && failed < n
+		if fail {
+			failed++
+		}
+		mu.Unlock()
+
+		if fail {
+			w.WriteHeader(status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+	return &failed
+}
+
+func TestRetryPolicy(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"docs/a.txt": []byte("a")})
+	failFirst(server, http.MethodPost, "/api/login", 2, http.StatusBadGateway)
+	failFirst(server, http.MethodGet, "/api/resources/", 2, http.StatusServiceUnavailable)
+	failFirst(server, http.MethodPost, "/api/share/", 2, http.StatusGatewayTimeout)
+	failFirst(server, http.MethodDelete, "/api/resources/", 2, http.StatusServiceUnavailable)
+	failFirst(server, http.MethodPost, "/api/tus/", 1, http.StatusServiceUnavailable)
+	failFirst(server, http.MethodPatch, "/api/tus/", 2, http.StatusBadGateway)
+
+	client := &Client{
+		URL:      server.URL,
+		ReqLogin: ReqLogin{Username: "user", Password: "pass"},
+		Retry:    &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}
+
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if resource, err := client.GetResource("docs/a.txt"); err != nil {
+		t.Fatalf("GetResource() = %+v, %v", resource, err)
+	}
+	if _, err := client.Share("docs/a.txt", 0, "", ""); err != nil {
+		t.Fatalf("Share() error = %v", err)
+	}
+	if err := client.DeleteResource("docs/a.txt"); err != nil {
+		t.Fatalf("DeleteResource() error = %v", err)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "b.txt")
+	if err := os.WriteFile(localPath, []byte("uploaded"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Upload(localPath, "docs/b.txt"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if content, _ := server.file("docs/b.txt"); string(content) != "uploaded" {
+		t.Errorf("uploaded content = %q", content)
+	}
+}
+
+func TestRetryPolicyGivesUp(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("a")})
+	failed := failFirst(server, http.MethodGet, "/api/resources/", 10, http.StatusServiceUnavailable)
+	failFirst(server, http.MethodDelete, "/api/resources/", 10, http.StatusInternalServerError)
+
+	client := &Client{
+		URL:      server.URL,
+		ReqLogin: ReqLogin{Username: "user", Password: "pass"},
+		Retry:    &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}
+
+	if _, err := client.GetResource("a.txt"); err == nil {
+		t.Error("GetResource() should fail once attempts are exhausted")
+	}
+	if *failed != 3 {
+		t.Errorf("GetResource() made %d attempts, want 3", *failed)
+	}
+
+	// Status codes not configured as retryable fail immediately
+	if err := client.DeleteResource("a.txt"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteResource() error = %v, want status code error", err)
+	}
+}
+
+func TestRetryAttempts(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"docs/a.txt": []byte("a")})
+	failFirst(server, http.MethodGet, "/api/resources/", 2, http.StatusServiceUnavailable)
+
+	var stats []OperationStats
+	client := &Client{
+		URL:      server.URL,
+		ReqLogin: ReqLogin{Username: "user", Password: "pass"},
+		Retry:    &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		Stats:    StatsCollectorFunc(func(s OperationStats) { stats = append(stats, s) }),
+	}
+
+	if _, err := client.GetResource("docs/a.txt"); err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	got := stats[len(stats)-1]
+	if got.Retries != 2 || got.Attempts != nil {
+		t.Errorf("non-verbose stats = %+v, want 2 retries and no attempts", got)
+	}
+
+	failFirst(server, http.MethodGet, "/api/resources/", 1, http.StatusBadGateway)
+	client.Verbose = true
+	if _, err := client.GetResource("docs/a.txt"); err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	got = stats[len(stats)-1]
+	if got.Retries != 1 || len(got.Attempts) != 2 {
+		t.Fatalf("verbose stats = %+v, want 1 retry and 2 attempts", got)
+	}
+	if a := got.Attempts[0]; a.Status != http.StatusBadGateway || a.RetryReason == "" {
+		t.Errorf("first attempt = %+v, want retried 502", a)
+	}
+	if a := got.Attempts[1]; a.Status != http.StatusOK || a.RetryReason != "" || a.Err != nil {
+		t.Errorf("second attempt = %+v, want successful 200", a)
+	}
+}
@@ -0,0 +1,234 @@
+package filebrowser
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memServer is a fake Filebrowser instance keeping its files in memory. It supports
+// login, TUS uploads, downloads, previews, resource listing, updates, deletion, renaming,
+// copying and chmod, and shares.
+type memServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	files  map[string]testFile
+	shares map[string]string      // share hash by path
+	modes  map[string]fs.FileMode // mode set with chmod by path
+
+	previews int // Number of previews rendered
+}
+
+// newMemServer starts a memServer holding the given files
+func newMemServer(t *testing.T, files map[string][]byte) *memServer {
+	t.Helper()
+
+	s := &memServer{files: map[string]testFile{}, shares: map[string]string{}, modes: map[string]fs.FileMode{}}
+	for name, content := range files {
+		s.files[name] = testFile{content: content, modified: time.Now()}
+	}
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// file returns the content of a file and whether it exists
+func (s *memServer) file(name string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[name]
+	return file.content, ok
+}
+
+// setFile creates or replaces a file
+func (s *memServer) setFile(name string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[name] = testFile{content: content, modified: time.Now()}
+}
+
+// mode returns the mode set for a resource with chmod, zero if none was
+func (s *memServer) mode(name string) fs.FileMode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.modes[name]
+}
+
+// exists reports whether any file exists at or below name
+func (s *memServer) exists(name string) bool {
+	for filePath := range s.files {
+		if filePath == name || strings.HasPrefix(filePath, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/api/login" {
+		w.Write([]byte("test-token"))
+		return
+	}
+
+	api, name, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/"), "/")
+	name = strings.Trim(name, "/")
+	switch api + " " + r.Method {
+	case "tus " + http.MethodOptions:
+		w.Header().Set("Tus-Extension", "creation")
+		w.WriteHeader(http.StatusNoContent)
+	case "tus " + http.MethodPost:
+		s.files[name] = testFile{modified: time.Now()}
+		w.Header().Set("Location", r.URL.EscapedPath())
+		w.WriteHeader(http.StatusCreated)
+	case "tus " + http.MethodPatch:
+		body, _ := io.ReadAll(r.Body)
+		file := s.files[name]
+		file.content = append(file.content, body...)
+		s.files[name] = file
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(file.content)))
+		w.WriteHeader(http.StatusNoContent)
+	case "raw " + http.MethodGet:
+		if algo := r.URL.Query().Get("algo"); algo != "" {
+			s.serveArchive(w, name, algo)
+			return
+		}
+		file, ok := s.files[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// Like Filebrowser, serve ranges of the file if requested
+		http.ServeContent(w, r, name, file.modified, bytes.NewReader(file.content))
+	case "preview " + http.MethodGet:
+		_, name, _ = strings.Cut(name, "/")
+		if _, ok := s.files[name]; !ok || testFileType(name) != "image" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.previews++
+		w.Write([]byte("preview"))
+	case "resources " + http.MethodGet:
+		resource, ok := testResource(s.files, name)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		resource.Mode = s.modes[name]
+		json.NewEncoder(w).Encode(resource)
+	case "resources " + http.MethodDelete:
+		for filePath := range s.files {
+			if filePath == name || strings.HasPrefix(filePath, name+"/") {
+				delete(s.files, filePath)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	case "resources " + http.MethodPut:
+		file, ok := s.files[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		file.content, _ = io.ReadAll(r.Body)
+		file.modified = time.Now()
+		s.files[name] = file
+		w.WriteHeader(http.StatusOK)
+	case "resources " + http.MethodPatch:
+		dst := strings.Trim(r.URL.Query().Get("destination"), "/")
+		action := r.URL.Query().Get("action")
+		if action == "chmod" {
+			if !s.exists(name) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			mode, err := strconv.ParseUint(r.URL.Query().Get("mode"), 8, 32)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.modes[name] = fs.FileMode(mode)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if action != "rename" && action != "copy" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !s.exists(name) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if s.exists(dst) && r.URL.Query().Get("override") != "true" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		for filePath, file := range s.files {
+			if rest, ok := strings.CutPrefix(filePath, name); ok && (rest == "" || rest[0] == '/') {
+				if action == "rename" {
+					delete(s.files, filePath)
+				}
+				s.files[dst+rest] = file
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	case "share " + http.MethodGet:
+		links := []respShareLink{}
+		if hash, ok := s.shares[name]; ok {
+			links = append(links, respShareLink{Hash: hash, Path: "/" + name})
+		}
+		json.NewEncoder(w).Encode(links)
+	case "share " + http.MethodPost:
+		if !s.exists(name) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		hash := fmt.Sprintf("hash-%d", len(s.shares)+1)
+		s.shares[name] = hash
+		json.NewEncoder(w).Encode(RespShare{Hash: hash, Path: "/" + name})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// serveArchive writes a zip archive of the files below the directory name
+func (s *memServer) serveArchive(w http.ResponseWriter, name string, algo string) {
+	if algo != "zip" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if name != "" && !s.exists(name) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	archive := zip.NewWriter(w)
+	for filePath, file := range s.files {
+		rest, ok := strings.CutPrefix(filePath, name+"/")
+		if name == "" {
+			rest, ok = filePath, true
+		}
+		if !ok {
+			continue
+		}
+		entry, _ := archive.Create(rest)
+		entry.Write(file.content)
+	}
+	archive.Close()
+}
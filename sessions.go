@@ -0,0 +1,217 @@
+package filebrowser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/eventials/go-tus"
+)
+
+// UploadSession records a TUS upload created on the server and not yet completed
+type UploadSession struct {
+	URL        string    `json:"url"`
+	RemotePath string    `json:"remote_path"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// UploadSessionStore persists in-flight upload sessions, so that sessions left behind
+// by crashed runs can be found and terminated later.
+// Implementations must be safe for concurrent use.
+type UploadSessionStore interface {
+	Save(session UploadSession) error
+	Delete(url string) error
+	List() ([]UploadSession, error)
+}
+
+// FileSessionStore is an UploadSessionStore backed by a JSON file
+type FileSessionStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSessionStore creates a session store persisted at the given path.
+// The file is created on the first save.
+func NewFileSessionStore(path string) *FileSessionStore {
+	return &FileSessionStore{path: path}
+}
+
+// Save adds or replaces a session
+func (s *FileSessionStore) Save(session UploadSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.load()
+	if err != nil {
+		return err
+	}
+	sessions[session.URL] = session
+	return s.store(sessions)
+}
+
+// Delete removes a session, it is not an error if the session is unknown
+func (s *FileSessionStore) Delete(url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := sessions[url]; !ok {
+		return nil
+	}
+	delete(sessions, url)
+	return s.store(sessions)
+}
+
+// List returns all sessions ordered by creation time
+func (s *FileSessionStore) List() ([]UploadSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]UploadSession, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, session)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+// load reads all sessions from disk
+func (s *FileSessionStore) load() (map[string]UploadSession, error) {
+	sessions := map[string]UploadSession{}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return sessions, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode session store: %w", err)
+	}
+	return sessions, nil
+}
+
+// store writes all sessions to disk, replacing the file atomically
+func (s *FileSessionStore) store(sessions map[string]UploadSession) error {
+	if err := writeJSONFileAtomic(s.path, sessions); err != nil {
+		return fmt.Errorf("failed to write session store: %w", err)
+	}
+	return nil
+}
+
+// sessionTracker records a created upload URL and returns a function forgetting it again
+type sessionTracker func(uploadURL string) (untrack func())
+
+// trackSession returns a tracker recording sessions for remotePath in the client's
+// session store. Store failures are logged rather than failing the upload.
+func (c *Client) trackSession(remotePath string) sessionTracker {
+	return func(uploadURL string) func() {
+		if c.Sessions == nil {
+			return func() {}
+		}
+
+		session := UploadSession{URL: uploadURL, RemotePath: remotePath, CreatedAt: time.Now()}
+		if err := c.Sessions.Save(session); err != nil {
+			c.log().Warn("Failed to record upload session", "url", uploadURL, "error", err)
+		}
+		return func() {
+			if err := c.Sessions.Delete(uploadURL); err != nil {
+				c.log().Warn("Failed to remove upload session", "url", uploadURL, "error", err)
+			}
+		}
+	}
+}
+
+// AbortUpload terminates an unfinished TUS upload, releasing the partial data held by the
+// server. It requires the TUS termination extension; uploads the server no longer knows
+// about are treated as already aborted.
+func (c *Client) AbortUpload(uploadURL string) (err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if uploadURL == "" {
+		return fmt.Errorf("upload URL cannot be empty")
+	}
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	tusClient, err := tus.NewClient(uploadURL, c.newTusConfig(context.Background()))
+	if err != nil {
+		return fmt.Errorf("failed to create TUS client: %w", err)
+	}
+
+	request, err := http.NewRequest(http.MethodDelete, uploadURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := tusClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("abort request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK, http.StatusNotFound, http.StatusGone:
+	default:
+		return fmt.Errorf("abort request failed with %w", httpAPIError(resp))
+	}
+
+	if c.Sessions != nil {
+		if err := c.Sessions.Delete(uploadURL); err != nil {
+			return fmt.Errorf("failed to remove upload session: %w", err)
+		}
+	}
+
+	c.log().Info("Aborted upload", "url", uploadURL)
+	return nil
+}
+
+// AbortStaleUploads terminates every session in the client's session store created more
+// than olderThan ago, e.g. sessions left behind by crashed runs. It returns the number of
+// aborted sessions; sessions that fail to abort are kept for the next run.
+func (c *Client) AbortStaleUploads(olderThan time.Duration) (_ int, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if c.Sessions == nil {
+		return 0, fmt.Errorf("client has no session store")
+	}
+
+	sessions, err := c.Sessions.List()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list upload sessions: %w", err)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	aborted := 0
+	var errs []error
+	for _, session := range sessions {
+		if session.CreatedAt.After(cutoff) {
+			continue
+		}
+		if err := c.AbortUpload(session.URL); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", session.RemotePath, err))
+			continue
+		}
+		aborted++
+	}
+
+	return aborted, errors.Join(errs...)
+}
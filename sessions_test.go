@@ -0,0 +1,54 @@
+package filebrowser
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAbortStaleUploads(t *testing.T) {
+	localPath, _ := writeTestFile(t, 3*1024*1024)
+
+	tusServer := &tusTestServer{corruptFrom: 2 * 1024 * 1024}
+	server := httptest.NewServer(tusServer)
+	defer server.Close()
+
+	store := NewFileSessionStore(filepath.Join(t.TempDir(), "sessions.json"))
+	client := &Client{
+		URL:      server.URL,
+		ReqLogin: ReqLogin{Username: "user", Password: "pass"},
+		Sessions: store,
+	}
+
+	// A failed upload leaves its session behind
+	if _, err := client.Upload(localPath, "dir/upload.bin"); err == nil {
+		t.Fatal("Upload() should fail on checksum mismatch")
+	}
+	sessions, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].RemotePath != "dir/upload.bin" {
+		t.Fatalf("List() = %+v, want one session for dir/upload.bin", sessions)
+	}
+
+	// Sessions younger than the threshold are kept
+	aborted, err := client.AbortStaleUploads(time.Hour)
+	if err != nil || aborted != 0 {
+		t.Fatalf("AbortStaleUploads(1h) = %d, %v, want 0, nil", aborted, err)
+	}
+
+	aborted, err = client.AbortStaleUploads(0)
+	if err != nil || aborted != 1 {
+		t.Fatalf("AbortStaleUploads(0) = %d, %v, want 1, nil", aborted, err)
+	}
+	if len(tusServer.deleted) != 1 || tusServer.deleted[0] != "/api/tus/dir/upload.bin" {
+		t.Errorf("server received DELETE for %v, want /api/tus/dir/upload.bin", tusServer.deleted)
+	}
+
+	sessions, _ = store.List()
+	if len(sessions) != 0 {
+		t.Errorf("List() after abort = %+v, want empty", sessions)
+	}
+}
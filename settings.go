@@ -0,0 +1,175 @@
+package filebrowser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Settings are the global settings of a Filebrowser server, managed by admins
+type Settings struct {
+	Signup           bool                `json:"signup"`
+	CreateUserDir    bool                `json:"createUserDir"`
+	UserHomeBasePath string              `json:"userHomeBasePath"`
+	Defaults         UserDefaults        `json:"defaults"`
+	AuthMethod       string              `json:"authMethod"`
+	Rules            []Rule              `json:"rules"`
+	Branding         Branding            `json:"branding"`
+	Tus              TusSettings         `json:"tus"`
+	Shell            []string            `json:"shell"`
+	Commands         map[string][]string `json:"commands"` // Hook commands by event, e.g. "after_upload"
+
+	// raw holds the settings as received, so fields unknown to this package survive an update
+	raw map[string]json.RawMessage
+}
+
+// UserDefaults are the settings new users are created with
+type UserDefaults struct {
+	Scope        string      `json:"scope"`
+	Locale       string      `json:"locale"`
+	ViewMode     string      `json:"viewMode"`
+	SingleClick  bool        `json:"singleClick"`
+	Sorting      Sorting     `json:"sorting"`
+	Perm         Permissions `json:"perm"`
+	Commands     []string    `json:"commands"`
+	HideDotfiles bool        `json:"hideDotfiles"`
+	DateFormat   bool        `json:"dateFormat"`
+}
+
+// Sorting is the order files are listed in
+type Sorting struct {
+	By  string `json:"by"`
+	Asc bool   `json:"asc"`
+}
+
+// Permissions are the actions a user may perform
+type Permissions struct {
+	Admin    bool `json:"admin"`
+	Execute  bool `json:"execute"`
+	Create   bool `json:"create"`
+	Rename   bool `json:"rename"`
+	Modify   bool `json:"modify"`
+	Delete   bool `json:"delete"`
+	Share    bool `json:"share"`
+	Download bool `json:"download"`
+}
+
+// Rule allows or denies access to the paths matching Path, or Regexp if Regex is set
+type Rule struct {
+	Regex  bool        `json:"regex"`
+	Allow  bool        `json:"allow"`
+	Path   string      `json:"path"`
+	Regexp *RuleRegexp `json:"regexp,omitempty"`
+}
+
+// RuleRegexp is the regular expression of a Rule
+type RuleRegexp struct {
+	Raw string `json:"raw"`
+}
+
+// Branding customizes the appearance of the web interface
+type Branding struct {
+	Name                  string `json:"name"`
+	DisableExternal       bool   `json:"disableExternal"`
+	DisableUsedPercentage bool   `json:"disableUsedPercentage"`
+	Files                 string `json:"files"` // Directory with custom CSS and images
+	Theme                 string `json:"theme"`
+	Color                 string `json:"color"`
+}
+
+// TusSettings configure the chunked uploads of the web interface
+type TusSettings struct {
+	ChunkSize  uint64 `json:"chunkSize"`
+	RetryCount uint16 `json:"retryCount"`
+}
+
+// settingsFields has the fields of Settings without its JSON methods
+type settingsFields Settings
+
+// UnmarshalJSON implements json.Unmarshaler, keeping the fields unknown to this package
+func (s *Settings) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, (*settingsFields)(s)); err != nil {
+		return err
+	}
+	s.raw = raw
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, adding back the fields unknown to this package
+func (s Settings) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(settingsFields(s))
+	if err != nil || len(s.raw) == 0 {
+		return data, err
+	}
+
+	var known map[string]json.RawMessage
+	if err := json.Unmarshal(data, &known); err != nil {
+		return nil, err
+	}
+	merged := make(map[string]json.RawMessage, len(s.raw))
+	for key, value := range s.raw {
+		merged[key] = value
+	}
+	for key, value := range known {
+		merged[key] = value
+	}
+	return json.Marshal(merged)
+}
+
+// GetSettings retrieves the global settings of the server, which requires an admin user
+func (c *Client) GetSettings() (_ *Settings, err error) {
+	ctx, start := c.startOp(context.Background(), OpGetSettings, "")
+	defer func() { err = c.finishOp(OpGetSettings, "", 0, start, err) }()
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	// Make settings request
+	var result Settings
+	resp, err := c.newRequest(ctx).
+		SetSuccessResult(&result).
+		Get(c.URL + "/api/settings")
+	if err != nil {
+		return nil, fmt.Errorf("settings request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("settings request failed with %w", newAPIError(resp))
+	}
+
+	return &result, nil
+}
+
+// UpdateSettings replaces the global settings of the server, which requires an admin user.
+// Settings should be obtained with GetSettings and modified, as zero fields are sent too.
+func (c *Client) UpdateSettings(settings *Settings) (err error) {
+	ctx, start := c.startOp(context.Background(), OpUpdateSettings, "")
+	defer func() { err = c.finishOp(OpUpdateSettings, "", 0, start, err) }()
+
+	if settings == nil {
+		return fmt.Errorf("settings cannot be nil")
+	}
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	// Make update request
+	resp, err := c.newRequest(ctx).
+		SetBody(settings).
+		Put(c.URL + "/api/settings")
+	if err != nil {
+		return fmt.Errorf("settings update request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("settings update request failed with %w", newAPIError(resp))
+	}
+
+	c.log().Info("Updated settings")
+	return nil
+}
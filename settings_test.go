@@ -0,0 +1,75 @@
+package filebrowser
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	stored := []byte(`{"signup":false,"userHomeBasePath":"/users","minimumPasswordLength":12,
+		"defaults":{"scope":".","perm":{"admin":false,"delete":true,"download":true}},
+		"branding":{"name":"Files"},"tus":{"chunkSize":10485760,"retryCount":5},
+		"rules":[{"regex":true,"allow":false,"path":"","regexp":{"raw":"\\.secret$"}}]}`)
+
+	admin := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/login":
+			w.Write([]byte("test-token"))
+		case r.URL.Path != "/api/settings":
+			w.WriteHeader(http.StatusNotFound)
+		case !admin:
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == http.MethodGet:
+			w.Write(stored)
+		case r.Method == http.MethodPut:
+			stored, _ = io.ReadAll(r.Body)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "admin", Password: "pass"}}
+	settings, err := client.GetSettings()
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if settings.Branding.Name != "Files" || settings.Tus.ChunkSize != 10485760 || !settings.Defaults.Perm.Delete ||
+		len(settings.Rules) != 1 || settings.Rules[0].Regexp.Raw != `\.secret$` {
+		t.Errorf("GetSettings() = %+v", settings)
+	}
+
+	settings.Signup = true
+	settings.Branding.Name = "Company Files"
+	settings.Defaults.Perm.Delete = false
+	if err := client.UpdateSettings(settings); err != nil {
+		t.Fatalf("UpdateSettings() error = %v", err)
+	}
+
+	var sent map[string]any
+	if err := json.Unmarshal(stored, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent["signup"] != true || sent["branding"].(map[string]any)["name"] != "Company Files" {
+		t.Errorf("UpdateSettings() sent %s", stored)
+	}
+	if sent["defaults"].(map[string]any)["perm"].(map[string]any)["delete"] != false {
+		t.Errorf("UpdateSettings() sent default permissions %v", sent["defaults"])
+	}
+	// Fields unknown to the package are not reset
+	if sent["minimumPasswordLength"] != float64(12) {
+		t.Errorf("UpdateSettings() dropped unknown field, sent %s", stored)
+	}
+
+	admin = false
+	if _, err := client.GetSettings(); err == nil {
+		t.Error("GetSettings() as non-admin succeeded, want error")
+	}
+	var apiErr *APIError
+	if err := client.UpdateSettings(settings); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("UpdateSettings() as non-admin error = %v, want APIError 403", err)
+	}
+}
@@ -0,0 +1,331 @@
+package filebrowser
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultShareManyConcurrency is the number of concurrent shares created by ShareMany when
+// no concurrency is given
+const defaultShareManyConcurrency = 4
+
+// shareCSVHeader is the header row of the CSV written by ShareCSV
+var shareCSVHeader = []string{"path", "expires", "hash", "view_url", "download_url", "error"}
+
+// ShareItem is a file shared by ShareMany
+type ShareItem struct {
+	RemotePath string
+	Params     ShareParams
+}
+
+// ShareItemResult is the outcome of one ShareItem
+type ShareItemResult struct {
+	Item   ShareItem
+	Hash   string       // Set if the share was created
+	Result *ShareResult // Set if the share was created
+	Err    error
+}
+
+// ShareLink is an existing share of a file
+type ShareLink struct {
+	Hash              string
+	Path              string
+	Expires           time.Time // Zero if the share never expires
+	PasswordProtected bool
+}
+
+// respShareLink is a share as listed by the server
+type respShareLink struct {
+	Hash         string `json:"hash"`
+	Path         string `json:"path"`
+	Expire       int64  `json:"expire"` // Unix time, zero if the share never expires
+	PasswordHash string `json:"password_hash"`
+}
+
+// newShareResult describes the share with the given hash created at now
+func newShareResult(url string, remotePath string, hash string, share ShareParams, now time.Time) *ShareResult {
+	return &ShareResult{
+		ViewUrl:           fmt.Sprintf("%s/share/%s", url, hash),
+		DownloadUrl:       fmt.Sprintf("%s/api/public/dl/%s", url, hash),
+		Hash:              hash,
+		RemotePath:        remotePath,
+		Expires:           shareExpiry(now, share.Expires, share.Unit),
+		PasswordProtected: share.Expires > 0 && share.Password != "",
+		CreatedAt:         now,
+	}
+}
+
+// reusedShareResult describes an existing share of remotePath
+func reusedShareResult(url string, remotePath string, link ShareLink) *ShareResult {
+	return &ShareResult{
+		ViewUrl:           fmt.Sprintf("%s/share/%s", url, link.Hash),
+		DownloadUrl:       fmt.Sprintf("%s/api/public/dl/%s", url, link.Hash),
+		Hash:              link.Hash,
+		RemotePath:        remotePath,
+		Expires:           link.Expires,
+		PasswordProtected: link.PasswordProtected,
+		Reused:            true,
+	}
+}
+
+// ListShares lists the shares of a remote file created by the user, expired ones included
+func (c *Client) ListShares(remotePath string) (_ []ShareLink, err error) {
+	ctx, start := c.startOp(context.Background(), OpListShares, remotePath)
+	defer func() { err = c.finishOp(OpListShares, remotePath, 0, start, err) }()
+
+	if remotePath == "" {
+		return nil, fmt.Errorf("remote path cannot be empty")
+	}
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	// Make share list request
+	url, err := c.apiURL("share", remotePath)
+	if err != nil {
+		return nil, err
+	}
+	var result []respShareLink
+	resp, err := c.newRequest(ctx).
+		SetSuccessResult(&result).
+		Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("share list request failed: %w", err)
+	}
+
+	if err := checkDenied(resp, remotePath); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("share list request failed with %w", newAPIError(resp))
+	}
+
+	links := make([]ShareLink, 0, len(result))
+	for _, link := range result {
+		shareLink := ShareLink{Hash: link.Hash, Path: link.Path, PasswordProtected: link.PasswordHash != ""}
+		if link.Expire > 0 {
+			shareLink.Expires = time.Unix(link.Expire, 0)
+		}
+		links = append(links, shareLink)
+	}
+	return links, nil
+}
+
+// reusableShare returns an existing share of remotePath that can stand in for a new share
+// with the given parameters created at now, or nil if there is none. Shares with a
+// password are never reused, as their password is unknown.
+func reusableShare(sharer Sharer, remotePath string, share ShareParams, now time.Time) (*ShareLink, error) {
+	if share.Expires > 0 && share.Password != "" {
+		return nil, nil
+	}
+	links, err := sharer.ListShares(remotePath)
+	if err != nil {
+		return nil, err
+	}
+
+	expires := shareExpiry(now, share.Expires, share.Unit)
+	for _, link := range links {
+		if link.PasswordProtected {
+			continue
+		}
+		// A share must stay valid at least as long as the requested one
+		if expires.IsZero() && link.Expires.IsZero() ||
+			!expires.IsZero() && !link.Expires.IsZero() && !link.Expires.Before(expires) {
+			return &link, nil
+		}
+	}
+	return nil, nil
+}
+
+// ShareMany shares a batch of files with the given number of concurrent requests, 4 if
+// zero, like ShareIfExists. The results are returned in the order of items. If some
+// shares fail, the error joins the errors of every failed item.
+func (c *Client) ShareMany(items []ShareItem, concurrency int, mode BatchMode) ([]ShareItemResult, error) {
+	if concurrency <= 0 {
+		concurrency = defaultShareManyConcurrency
+	}
+	if err := c.ensureAuthenticated(); err != nil {
+		return nil, c.redactError(fmt.Errorf("authentication failed: %w", err))
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		failed  bool
+		results = make([]ShareItemResult, len(items))
+		slots   = make(chan struct{}, concurrency)
+	)
+	for i, item := range items {
+		results[i].Item = item
+
+		slots <- struct{}{}
+		mu.Lock()
+		abort := failed && mode == BatchFailFast
+		mu.Unlock()
+		if abort {
+			<-slots
+			results[i].Err = ErrBatchAborted
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			hash, err := c.ShareIfExists(item.RemotePath, item.Params)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[i].Hash, results[i].Err = hash, err
+			if err == nil {
+				results[i].Result = newShareResult(c.URL, item.RemotePath, hash, item.Params, time.Now())
+			}
+			failed = failed || err != nil
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Item.RemotePath, result.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// ShareCSV creates the shares requested by the CSV rows read from r and writes a CSV
+// report to w. Input rows hold a remote path, an optional expiry and an optional password;
+// a header row starting with "path" is skipped. Expiries are a number followed by s, m, h
+// or d, hours if no unit is given, and empty for shares that never expire. The report has
+// one row per request in input order with the hash and URLs of the share or the error,
+// never the password. Shares are created like ShareMany in best effort mode and the
+// returned error joins the errors of every failed row.
+func (c *Client) ShareCSV(r io.Reader, w io.Writer, concurrency int) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to read share requests: %w", err)
+	}
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "path") {
+		records = records[1:]
+	}
+
+	// Rows that cannot be parsed are reported without sending a request
+	var items []ShareItem
+	rowErrs := make([]error, len(records))
+	rowItems := make([]int, len(records))
+	for i, record := range records {
+		item, err := parseShareRecord(record)
+		if err != nil {
+			rowErrs[i] = fmt.Errorf("row %d: %w", i+1, err)
+			rowItems[i] = -1
+			continue
+		}
+		rowItems[i] = len(items)
+		items = append(items, item)
+	}
+
+	var results []ShareItemResult
+	if len(items) > 0 {
+		// Failed shares are reported per row, no results mean no share could be created
+		if results, err = c.ShareMany(items, concurrency, BatchBestEffort); results == nil {
+			return err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(shareCSVHeader); err != nil {
+		return fmt.Errorf("failed to write share report: %w", err)
+	}
+	var errs []error
+	for i, record := range records {
+		row := make([]string, len(shareCSVHeader))
+		row[0] = strings.TrimSpace(record[0])
+		if len(record) > 1 {
+			row[1] = strings.TrimSpace(record[1])
+		}
+
+		err := rowErrs[i]
+		if err == nil {
+			result := results[rowItems[i]]
+			if result.Err != nil {
+				err = fmt.Errorf("row %d: %s: %w", i+1, result.Item.RemotePath, result.Err)
+			} else {
+				row[2], row[3], row[4] = result.Hash, result.Result.ViewUrl, result.Result.DownloadUrl
+			}
+		}
+		if err != nil {
+			row[5] = c.redactError(err).Error()
+			errs = append(errs, err)
+		}
+
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write share report: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write share report: %w", err)
+	}
+
+	return c.redactError(errors.Join(errs...))
+}
+
+// parseShareRecord parses a share request row
+func parseShareRecord(record []string) (ShareItem, error) {
+	if len(record) > 3 {
+		return ShareItem{}, fmt.Errorf("expected at most 3 fields, got %d", len(record))
+	}
+	item := ShareItem{RemotePath: strings.TrimSpace(record[0])}
+	if item.RemotePath == "" {
+		return ShareItem{}, fmt.Errorf("remote path cannot be empty")
+	}
+	if len(record) > 1 {
+		expires, unit, err := parseShareExpiry(strings.TrimSpace(record[1]))
+		if err != nil {
+			return ShareItem{}, err
+		}
+		item.Params.Expires, item.Params.Unit = expires, unit
+	}
+	if len(record) > 2 {
+		item.Params.Password = record[2]
+		if item.Params.Password != "" && item.Params.Expires == 0 {
+			// Filebrowser ignores the password of shares that never expire
+			return ShareItem{}, fmt.Errorf("a password requires an expiry")
+		}
+	}
+	return item, nil
+}
+
+// parseShareExpiry parses an expiry such as "36h" or "7d" into a share expiration and unit
+func parseShareExpiry(s string) (int64, string, error) {
+	if s == "" {
+		return 0, "", nil
+	}
+
+	units := map[byte]string{'s': "seconds", 'm': "minutes", 'h': "hours", 'd': "days"}
+	number, unit := s, "hours"
+	if u, ok := units[s[len(s)-1]]; ok {
+		number, unit = s[:len(s)-1], u
+	}
+	expires, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || expires <= 0 {
+		return 0, "", fmt.Errorf("invalid expiry %q", s)
+	}
+	return expires, unit, nil
+}
@@ -0,0 +1,110 @@
+package filebrowser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShareCSV(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"a.txt": []byte("a"),
+		"b.txt": []byte("b"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	input := "path,expires,password\n" +
+		"a.txt,7d,secret\n" +
+		"b.txt\n" +
+		"missing.txt,1h\n" +
+		"a.txt,soon\n"
+	var output strings.Builder
+	err := client.ShareCSV(strings.NewReader(input), &output, 2)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("ShareCSV() error = %v, want ErrNotFound", err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(output.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 || strings.Join(rows[0], ",") != strings.Join(shareCSVHeader, ",") {
+		t.Fatalf("ShareCSV() wrote %q", rows)
+	}
+	for _, row := range rows[1:3] {
+		if row[2] == "" || row[3] != server.URL+"/share/"+row[2] || row[4] != server.URL+"/api/public/dl/"+row[2] || row[5] != "" {
+			t.Errorf("ShareCSV() row %q, want a share", row)
+		}
+	}
+	if rows[1][1] != "7d" {
+		t.Errorf("ShareCSV() row %q, want the expiry", rows[1])
+	}
+	if row := rows[3]; row[0] != "missing.txt" || row[2] != "" || row[5] == "" {
+		t.Errorf("ShareCSV() row %q, want an error for the missing file", row)
+	}
+	if row := rows[4]; row[2] != "" || !strings.Contains(row[5], "invalid expiry") {
+		t.Errorf("ShareCSV() row %q, want an expiry error", row)
+	}
+	if strings.Contains(output.String(), "secret") {
+		t.Error("ShareCSV() report contains the share password")
+	}
+	if len(server.shares) != 2 {
+		t.Errorf("created %d shares, want 2", len(server.shares))
+	}
+}
+
+func TestParseShareExpiry(t *testing.T) {
+	tests := []struct {
+		in      string
+		expires int64
+		unit    string
+	}{
+		{"", 0, ""},
+		{"24", 24, "hours"},
+		{"30m", 30, "minutes"},
+		{"7d", 7, "days"},
+		{"90s", 90, "seconds"},
+	}
+	for _, test := range tests {
+		expires, unit, err := parseShareExpiry(test.in)
+		if err != nil || expires != test.expires || unit != test.unit {
+			t.Errorf("parseShareExpiry(%q) = %d, %q, %v, want %d, %q", test.in, expires, unit, err, test.expires, test.unit)
+		}
+	}
+	for _, in := range []string{"d", "-1h", "1w", "0"} {
+		if _, _, err := parseShareExpiry(in); err == nil {
+			t.Errorf("parseShareExpiry(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestShareResult(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	result := newShareResult("https://files.example.com", "a.txt", "abc123", ShareParams{Expires: 2, Unit: "days", Password: "pw"}, now)
+	if result.Hash != "abc123" || result.ViewUrl != "https://files.example.com/share/abc123" {
+		t.Errorf("result = %+v, want hash abc123 and its view URL", result)
+	}
+	if !result.Expires.Equal(now.Add(48*time.Hour)) || !result.PasswordProtected {
+		t.Errorf("Expires = %v, PasswordProtected = %v, want %v and true", result.Expires, result.PasswordProtected, now.Add(48*time.Hour))
+	}
+
+	data, err := result.QRCodePNG()
+	if err != nil {
+		t.Fatalf("QRCodePNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("QRCodePNG() returned an invalid PNG: %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != qrCodeSize || bounds.Dy() != qrCodeSize {
+		t.Errorf("QR code size = %v, want %dx%d", bounds, qrCodeSize, qrCodeSize)
+	}
+
+	if _, err := (&ShareResult{}).QRCodePNG(); err == nil {
+		t.Error("QRCodePNG() of a result without view URL succeeded")
+	}
+}
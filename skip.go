@@ -0,0 +1,108 @@
+package filebrowser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// SkipStrategy decides whether an existing remote file is kept instead of uploading the
+// downloaded one
+type SkipStrategy int
+
+const (
+	// SkipBySize keeps an existing file of the expected size, ActionParams.FileSize. It is
+	// the default, but cannot tell apart different files of the same size.
+	SkipBySize SkipStrategy = iota
+	// SkipByHash keeps an existing file only if it has the SHA-256 checksum of the downloaded
+	// file. The remote checksum is read from the sidecar of the file if it has one (see
+	// UploadOptions.Sidecar), otherwise computed by the server or by downloading the file.
+	SkipByHash
+	// SkipAlways keeps any existing file
+	SkipAlways
+	// SkipNever always uploads, like ActionParams.Force
+	SkipNever
+)
+
+// String returns the name of the strategy
+func (s SkipStrategy) String() string {
+	switch s {
+	case SkipBySize:
+		return "size"
+	case SkipByHash:
+		return "hash"
+	case SkipAlways:
+		return "always"
+	case SkipNever:
+		return "never"
+	}
+	return fmt.Sprintf("SkipStrategy(%d)", int(s))
+}
+
+// uploadNeeded reports whether resource, the existing remote file or nil, is replaced by
+// the skip strategy of p without comparing content. With SkipByHash a file that is kept
+// by its size still needs its content compared.
+func (p ActionParams) uploadNeeded(resource *RespResource) bool {
+	switch {
+	case resource == nil || p.Force || p.Skip == SkipNever:
+		return true
+	case p.Skip == SkipAlways:
+		return false
+	}
+	return p.FileSize > 0 && resource.Size != p.FileSize
+}
+
+// sameContent reports whether the remote file has the size and SHA-256 checksum of the
+// local file
+func sameContent(api FileBrowserAPI, localPath string, remotePath string, resource *RespResource) (bool, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat local file: %w", err)
+	}
+	if info.Size() != resource.Size {
+		return false, nil
+	}
+
+	local, err := fileSHA256(localPath)
+	if err != nil {
+		return false, err
+	}
+	remote, err := storedSHA256(api, remotePath, resource.Size)
+	if err != nil {
+		return false, fmt.Errorf("failed to get remote checksum: %w", err)
+	}
+	return local == remote, nil
+}
+
+// storedSHA256 returns the hex encoded SHA-256 checksum of a remote file of the given
+// size, from its sidecar if it records one for that size and otherwise like sha256Of
+func storedSHA256(api FileBrowserAPI, remotePath string, size int64) (string, error) {
+	var data bytes.Buffer
+	if err := readSidecar(api, remotePath+SidecarSuffix, &data); err == nil {
+		var sidecar Sidecar
+		if json.Unmarshal(data.Bytes(), &sidecar) == nil &&
+			sidecar.ChecksumAlgorithm == "sha256" && sidecar.Checksum != "" && sidecar.Size == size {
+			return sidecar.Checksum, nil
+		}
+	}
+	return sha256Of(api, remotePath)
+}
+
+// readSidecar downloads the sidecar at sidecarPath to w. A *Client reads it without
+// reporting a download, as most files have no sidecar.
+func readSidecar(api FileBrowserAPI, sidecarPath string, w io.Writer) error {
+	c, ok := api.(*Client)
+	if !ok {
+		return api.Download(sidecarPath, w)
+	}
+	body, _, err := c.openRaw(context.Background(), sidecarPath)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(w, body)
+	return err
+}
@@ -0,0 +1,54 @@
+package filebrowser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSkipStrategy(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("payload of " + r.URL.Path))
+	}))
+	defer origin.Close()
+
+	// The sidecar of recorded.txt claims the checksum of the payload
+	sum := sha256.Sum256([]byte("payload of /recorded.txt"))
+	sidecar, _ := json.Marshal(Sidecar{Size: 24, ChecksumAlgorithm: "sha256", Checksum: hex.EncodeToString(sum[:])})
+	server := newMemServer(t, map[string][]byte{
+		"changed.txt":            []byte("PAYLOAD OF /CHANGED.TXT"),
+		"recorded.txt":           []byte("PAYLOAD OF /RECORDED.TXT"),
+		"recorded.txt.meta.json": sidecar,
+		"short.txt":              []byte("short"),
+		"forced.txt":             []byte("PAYLOAD OF /FORCED.TXT"),
+	})
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	remotePathFn := func(name string) string { return name }
+
+	tests := []struct {
+		name string
+		skip SkipStrategy
+		size int64
+		want string
+	}{
+		{"changed.txt", SkipBySize, 23, "PAYLOAD OF /CHANGED.TXT"},
+		{"changed.txt", SkipByHash, 23, "payload of /changed.txt"},
+		{"recorded.txt", SkipByHash, 0, "PAYLOAD OF /RECORDED.TXT"},
+		{"short.txt", SkipAlways, 21, "short"},
+		{"forced.txt", SkipNever, 22, "payload of /forced.txt"},
+	}
+	for _, tt := range tests {
+		params := ActionParams{Skip: tt.skip, FileSize: tt.size, URLPolicy: localOrigin}
+		if _, err := SaveAndShare(auth, origin.URL+"/"+tt.name, remotePathFn, params); err != nil {
+			t.Fatalf("SaveAndShare(%s, %v) error = %v", tt.name, tt.skip, err)
+		}
+		if content, _ := server.file(tt.name); string(content) != tt.want {
+			t.Errorf("%s with %v = %q, want %q", tt.name, tt.skip, content, tt.want)
+		}
+	}
+}
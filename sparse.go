@@ -0,0 +1,305 @@
+package filebrowser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// SparseManifestSuffix is appended to the remote path of a file uploaded with
+// SparseExtents to name its manifest
+const SparseManifestSuffix = ".sparse.json"
+
+// sparseBlockSize is the granularity of zero runs found by scanning files on systems that
+// cannot report holes
+const sparseBlockSize = 64 << 10
+
+// SparseMode decides how UploadSparse transfers the holes of a sparse file, e.g. a VM
+// disk image
+type SparseMode int
+
+const (
+	// SparseGzip uploads the file gzip-compressed, so holes take almost no space. The
+	// remote file is the gzip stream and should be named accordingly, e.g. disk.img.gz.
+	SparseGzip SparseMode = iota
+	// SparseExtents uploads only the data of the file, its extents back to back, together
+	// with a SparseManifest at the remote path with SparseManifestSuffix appended, from
+	// which DownloadSparse restores the file
+	SparseExtents
+)
+
+// SparseExtent is a region of a sparse file holding data
+type SparseExtent struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+// SparseManifest describes the data of a sparse file
+type SparseManifest struct {
+	Size    int64          `json:"size"`    // Size of the file including its holes
+	Extents []SparseExtent `json:"extents"` // Data regions in order
+}
+
+// DataSize returns the number of bytes of the file that are not holes
+func (m *SparseManifest) DataSize() int64 {
+	var size int64
+	for _, extent := range m.Extents {
+		size += extent.Length
+	}
+	return size
+}
+
+// UploadSparse uploads a local file without reading or sending its holes in full, as
+// decided by mode. Holes are found with the file system where supported, otherwise by
+// scanning the file for runs of zeros. It returns the manifest of the file.
+func (c *Client) UploadSparse(localPath string, remotePath string, mode SparseMode) (_ *SparseManifest, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if localPath == "" {
+		return nil, fmt.Errorf("local path cannot be empty")
+	}
+	if remotePath == "" {
+		return nil, fmt.Errorf("remote path cannot be empty")
+	}
+	if mode != SparseGzip && mode != SparseExtents {
+		return nil, fmt.Errorf("unsupported sparse mode: %d", mode)
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer file.Close()
+
+	manifest, err := sparseManifest(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the data of %s: %w", localPath, err)
+	}
+	c.log().Debug("Found sparse file data", "path", localPath, "size", manifest.Size, "data", manifest.DataSize())
+
+	if mode == SparseGzip {
+		pr, pw := io.Pipe()
+		go func() {
+			zw := gzip.NewWriter(pw)
+			_, err := io.Copy(zw, newSparseReader(file, manifest))
+			if closeErr := zw.Close(); err == nil {
+				err = closeErr
+			}
+			pw.CloseWithError(err)
+		}()
+		err := c.UploadStream(pr, remotePath)
+		pr.CloseWithError(io.ErrClosedPipe)
+		if err != nil {
+			return nil, err
+		}
+		return manifest, nil
+	}
+
+	data := make([]io.Reader, len(manifest.Extents))
+	for i, extent := range manifest.Extents {
+		data[i] = io.NewSectionReader(file, extent.Offset, extent.Length)
+	}
+	if err := c.UploadReader(io.MultiReader(data...), manifest.DataSize(), remotePath); err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sparse manifest: %w", err)
+	}
+	if err := c.UploadStream(bytes.NewReader(encoded), remotePath+SparseManifestSuffix); err != nil {
+		return nil, fmt.Errorf("failed to upload sparse manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// DownloadSparse restores a file uploaded with SparseExtents to localPath, leaving its
+// holes unwritten so they stay holes on file systems supporting them
+func (c *Client) DownloadSparse(remotePath string, localPath string) (err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if localPath == "" {
+		return fmt.Errorf("local path cannot be empty")
+	}
+
+	var encoded bytes.Buffer
+	if err := c.Download(remotePath+SparseManifestSuffix, &encoded); err != nil {
+		return fmt.Errorf("failed to download sparse manifest: %w", err)
+	}
+	var manifest SparseManifest
+	if err := json.Unmarshal(encoded.Bytes(), &manifest); err != nil {
+		return fmt.Errorf("invalid sparse manifest: %w", err)
+	}
+	if err := manifest.validate(); err != nil {
+		return fmt.Errorf("invalid sparse manifest: %w", err)
+	}
+
+	body, size, err := c.openRaw(context.Background(), remotePath)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if size >= 0 && size != manifest.DataSize() {
+		return fmt.Errorf("%s has %d bytes, its manifest %d", remotePath, size, manifest.DataSize())
+	}
+
+	if err := EnsureFolderForFile(localPath); err != nil {
+		return err
+	}
+	out, err := os.CreateTemp(filepath.Dir(localPath), filepath.Base(localPath)+".*"+partSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer os.Remove(out.Name())
+
+	err = out.Truncate(manifest.Size)
+	for _, extent := range manifest.Extents {
+		if err != nil {
+			break
+		}
+		_, err = io.CopyN(io.NewOffsetWriter(out, extent.Offset), body, extent.Length)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", remotePath, err)
+	}
+	return os.Rename(out.Name(), localPath)
+}
+
+// validate checks that the extents are ordered, do not overlap and lie within the file
+func (m *SparseManifest) validate() error {
+	var end int64
+	for _, extent := range m.Extents {
+		if extent.Offset < end || extent.Length <= 0 || extent.Offset+extent.Length > m.Size {
+			return fmt.Errorf("extent %d+%d out of order or beyond %d bytes", extent.Offset, extent.Length, m.Size)
+		}
+		end = extent.Offset + extent.Length
+	}
+	return nil
+}
+
+// sparseManifest finds the data of file, asking the file system for its holes and
+// scanning it for zeros if that is not supported
+func sparseManifest(file *os.File) (*SparseManifest, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	manifest := &SparseManifest{Size: info.Size(), Extents: []SparseExtent{}}
+	if !seekHoles {
+		return scanSparse(file, manifest)
+	}
+
+	for offset := int64(0); offset < manifest.Size; {
+		start, err := file.Seek(offset, seekData)
+		if errors.Is(err, syscall.ENXIO) {
+			break // Only a hole is left
+		}
+		if err != nil {
+			if offset == 0 {
+				return scanSparse(file, manifest)
+			}
+			return nil, err
+		}
+		end, err := file.Seek(start, seekHole)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Extents = append(manifest.Extents, SparseExtent{Offset: start, Length: end - start})
+		offset = end
+	}
+	return manifest, nil
+}
+
+// scanSparse fills the extents of manifest with the blocks of file that are not all zeros
+func scanSparse(file *os.File, manifest *SparseManifest) (*SparseManifest, error) {
+	block := make([]byte, sparseBlockSize)
+	for offset := int64(0); offset < manifest.Size; offset += sparseBlockSize {
+		n, err := file.ReadAt(block, offset)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if isZero(block[:n]) {
+			continue
+		}
+		last := len(manifest.Extents) - 1
+		if last >= 0 && manifest.Extents[last].Offset+manifest.Extents[last].Length == offset {
+			manifest.Extents[last].Length += int64(n)
+		} else {
+			manifest.Extents = append(manifest.Extents, SparseExtent{Offset: offset, Length: int64(n)})
+		}
+	}
+	return manifest, nil
+}
+
+// isZero reports whether b holds only zeros
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sparseReader reads a file described by a manifest, producing the zeros of its holes
+// without reading them
+type sparseReader struct {
+	file     *os.File
+	manifest *SparseManifest
+	offset   int64
+	extent   int // Index of the next extent at or after offset
+}
+
+// newSparseReader returns a reader of the whole content of file
+func newSparseReader(file *os.File, manifest *SparseManifest) *sparseReader {
+	return &sparseReader{file: file, manifest: manifest}
+}
+
+// Read implements io.Reader
+func (r *sparseReader) Read(b []byte) (int, error) {
+	if r.offset >= r.manifest.Size {
+		return 0, io.EOF
+	}
+	end := r.manifest.Size
+	inData := false
+	if r.extent < len(r.manifest.Extents) {
+		extent := r.manifest.Extents[r.extent]
+		if r.offset >= extent.Offset {
+			end, inData = extent.Offset+extent.Length, true
+		} else {
+			end = extent.Offset
+		}
+	}
+	if remaining := end - r.offset; int64(len(b)) > remaining {
+		b = b[:remaining]
+	}
+
+	var n int
+	var err error
+	if inData {
+		n, err = r.file.ReadAt(b, r.offset)
+		if err == io.EOF {
+			// The file shrank since its manifest was made unless the data ends with it
+			if err = nil; n < len(b) {
+				err = io.ErrUnexpectedEOF
+			}
+		}
+	} else {
+		clear(b)
+		n = len(b)
+	}
+	r.offset += int64(n)
+	if inData && r.offset == end {
+		r.extent++
+	}
+	return n, err
+}
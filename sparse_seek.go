@@ -0,0 +1,13 @@
+//go:build linux || darwin || freebsd
+
+package filebrowser
+
+import "golang.org/x/sys/unix"
+
+// seekData and seekHole are the whence values of lseek finding the data and holes of sparse
+// files. They differ between systems, e.g. they are swapped on darwin.
+const (
+	seekData  = unix.SEEK_DATA
+	seekHole  = unix.SEEK_HOLE
+	seekHoles = true
+)
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd
+
+package filebrowser
+
+// The file system cannot report holes, so sparse files are scanned for zeros
+const (
+	seekData  = 0
+	seekHole  = 0
+	seekHoles = false
+)
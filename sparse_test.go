@@ -0,0 +1,132 @@
+package filebrowser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSparseFile writes a file of size bytes holding data at the given offsets and holes
+// elsewhere
+func writeSparseFile(t *testing.T, size int64, data map[int64]string) (string, []byte) {
+	t.Helper()
+
+	localPath := filepath.Join(t.TempDir(), "disk.img")
+	file, err := os.Create(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := file.Truncate(size); err != nil {
+		t.Fatal(err)
+	}
+	content := make([]byte, size)
+	for offset, chunk := range data {
+		if _, err := file.WriteAt([]byte(chunk), offset); err != nil {
+			t.Fatal(err)
+		}
+		copy(content[offset:], chunk)
+	}
+	return localPath, content
+}
+
+func TestUploadSparse(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	localPath, content := writeSparseFile(t, 8<<20, map[int64]string{0: "boot", 4 << 20: "root", 8<<20 - 4: "tail"})
+
+	manifest, err := client.UploadSparse(localPath, "images/disk.img", SparseExtents)
+	if err != nil {
+		t.Fatalf("UploadSparse() error = %v", err)
+	}
+	if manifest.Size != 8<<20 || manifest.DataSize() >= 1<<20 {
+		t.Errorf("manifest = %+v, want the holes left out", manifest)
+	}
+	data, _ := server.file("images/disk.img")
+	if int64(len(data)) != manifest.DataSize() || !bytes.Contains(data, []byte("boot")) || !bytes.Contains(data, []byte("tail")) {
+		t.Errorf("uploaded %d bytes, want the %d bytes of data", len(data), manifest.DataSize())
+	}
+	var stored SparseManifest
+	encoded, _ := server.file("images/disk.img" + SparseManifestSuffix)
+	if err := json.Unmarshal(encoded, &stored); err != nil || stored.Size != manifest.Size {
+		t.Errorf("stored manifest = %s, %v", encoded, err)
+	}
+
+	restored := filepath.Join(t.TempDir(), "restored.img")
+	if err := client.DownloadSparse("images/disk.img", restored); err != nil {
+		t.Fatalf("DownloadSparse() error = %v", err)
+	}
+	if got, _ := os.ReadFile(restored); !bytes.Equal(got, content) {
+		t.Error("restored file differs from the original")
+	}
+
+	if _, err := client.UploadSparse(localPath, "images/disk.img.gz", SparseGzip); err != nil {
+		t.Fatalf("UploadSparse() gzip error = %v", err)
+	}
+	compressed, _ := server.file("images/disk.img.gz")
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := io.ReadAll(zr); err != nil || !bytes.Equal(got, content) {
+		t.Errorf("gzip upload decompresses to %d bytes, %v, want the original", len(got), err)
+	}
+	if len(compressed) >= 1<<20 {
+		t.Errorf("gzip upload is %d bytes", len(compressed))
+	}
+}
+
+func TestScanSparse(t *testing.T) {
+	localPath, _ := writeSparseFile(t, 4*sparseBlockSize+10, map[int64]string{
+		1: "a", sparseBlockSize + 5: "b", 3 * sparseBlockSize: "c", 4 * sparseBlockSize: "d",
+	})
+	file, err := os.Open(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	manifest, err := scanSparse(file, &SparseManifest{Size: 4*sparseBlockSize + 10})
+	if err != nil {
+		t.Fatalf("scanSparse() error = %v", err)
+	}
+	want := []SparseExtent{{0, 2 * sparseBlockSize}, {3 * sparseBlockSize, sparseBlockSize + 10}}
+	if len(manifest.Extents) != 2 || manifest.Extents[0] != want[0] || manifest.Extents[1] != want[1] {
+		t.Errorf("extents = %+v, want %+v", manifest.Extents, want)
+	}
+}
+
+func TestSparseManifestContent(t *testing.T) {
+	localPath, content := writeSparseFile(t, 8<<20, map[int64]string{0: "boot", 3<<20 + 5: "root", 8<<20 - 4: "tail"})
+	file, err := os.Open(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	manifest, err := sparseManifest(file)
+	if err != nil {
+		t.Fatalf("sparseManifest() error = %v", err)
+	}
+	if manifest.DataSize() >= manifest.Size/2 {
+		t.Errorf("manifest = %+v, want mostly holes", manifest)
+	}
+
+	// The extents must hold all of the data, so a file rebuilt from them alone matches
+	rebuilt := make([]byte, manifest.Size)
+	for _, extent := range manifest.Extents {
+		if _, err := file.ReadAt(rebuilt[extent.Offset:extent.Offset+extent.Length], extent.Offset); err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(rebuilt, content) {
+		t.Errorf("file rebuilt from extents %+v differs from the original", manifest.Extents)
+	}
+	if got, err := io.ReadAll(newSparseReader(file, manifest)); err != nil || !bytes.Equal(got, content) {
+		t.Errorf("sparse reader returned %d bytes, %v, want the original", len(got), err)
+	}
+}
@@ -0,0 +1,165 @@
+package filebrowser
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+)
+
+// defaultStagingDir is the remote directory uploads are staged in when none is given
+const defaultStagingDir = "staging"
+
+var (
+	// ErrApprovalPending is matched by errors.Is for every ApprovalPendingError
+	ErrApprovalPending = errors.New("approval pending")
+	// ErrApprovalRejected is returned by StageAndPublish when a staged upload is rejected
+	ErrApprovalRejected = errors.New("approval rejected")
+)
+
+// ApprovalDecision is the outcome of reviewing a staged upload
+type ApprovalDecision int
+
+const (
+	// ApprovalPending leaves the upload staged until it is published or discarded later
+	ApprovalPending ApprovalDecision = iota
+	// Approved publishes the upload
+	Approved
+	// Rejected discards the upload
+	Rejected
+)
+
+// ApprovalFunc reviews a staged upload. Reviews completed out of band return
+// ApprovalPending and later call Publish or Discard with the staged upload.
+type ApprovalFunc func(staged *StagedUpload) (ApprovalDecision, error)
+
+// StagedUpload is an upload waiting in the staging area. It can be persisted (e.g. as JSON)
+// to publish or discard it from another process.
+type StagedUpload struct {
+	Dir        string // Staging directory holding only this upload
+	StagedPath string // Remote path of the staged file
+	PublicPath string // Remote path the file is published to
+}
+
+// ApprovalPendingError is returned by StageAndPublish when the approval is still pending
+type ApprovalPendingError struct {
+	Staged *StagedUpload
+}
+
+// Error implements the error interface
+func (e *ApprovalPendingError) Error() string {
+	return fmt.Sprintf("approval pending for %s", e.Staged.PublicPath)
+}
+
+// Is reports whether target is ErrApprovalPending
+func (e *ApprovalPendingError) Is(target error) bool {
+	return target == ErrApprovalPending
+}
+
+// StageOptions contains optional parameters for StageAndPublish
+type StageOptions struct {
+	// StagingDir is the remote directory uploads are staged in, "staging" if empty
+	StagingDir string
+	// Approve reviews the staged upload; nil approves it immediately
+	Approve ApprovalFunc
+	// Share contains the parameters of the share created when publishing
+	Share ShareParams
+}
+
+// Stage uploads a local file to a fresh directory below stagingDir, where it waits for
+// review before being published to publicPath
+func (c *Client) Stage(localPath string, publicPath string, stagingDir string) (*StagedUpload, error) {
+	if publicPath == "" {
+		return nil, fmt.Errorf("public path cannot be empty")
+	}
+	if stagingDir == "" {
+		stagingDir = defaultStagingDir
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate staging ID: %w", err)
+	}
+	dir := path.Join(stagingDir, hex.EncodeToString(id))
+	staged := &StagedUpload{
+		Dir:        dir,
+		StagedPath: path.Join(dir, publicPath),
+		PublicPath: publicPath,
+	}
+
+	if _, err := c.Upload(localPath, staged.StagedPath); err != nil {
+		return nil, fmt.Errorf("failed to stage upload: %w", err)
+	}
+
+	return staged, nil
+}
+
+// Publish moves a staged upload to its public path and shares it, returning the share hash.
+// An existing file at the public path is never replaced.
+func (c *Client) Publish(staged *StagedUpload, share ShareParams) (string, error) {
+	if staged == nil {
+		return "", fmt.Errorf("staged upload cannot be nil")
+	}
+	share, err := share.resolve(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.Move(staged.StagedPath, staged.PublicPath, false); err != nil {
+		return "", fmt.Errorf("failed to publish staged upload: %w", err)
+	}
+	if err := c.DeleteResource(staged.Dir); err != nil {
+		c.log().Warn("Failed to clean up staging directory", "path", staged.Dir, "error", err)
+	}
+
+	return c.Share(staged.PublicPath, share.Expires, share.Password, share.Unit)
+}
+
+// Discard deletes a staged upload without publishing it
+func (c *Client) Discard(staged *StagedUpload) error {
+	if staged == nil {
+		return fmt.Errorf("staged upload cannot be nil")
+	}
+
+	if err := c.DeleteResource(staged.Dir); err != nil {
+		return fmt.Errorf("failed to discard staged upload: %w", err)
+	}
+	return nil
+}
+
+// StageAndPublish uploads a local file to the staging area, has it reviewed by
+// opts.Approve and then publishes and shares it, returning the share hash. A rejected
+// upload is discarded and fails with ErrApprovalRejected; a pending one stays staged and
+// fails with an *ApprovalPendingError holding what Publish or Discard need later.
+func (c *Client) StageAndPublish(localPath string, publicPath string, opts StageOptions) (string, error) {
+	staged, err := c.Stage(localPath, publicPath, opts.StagingDir)
+	if err != nil {
+		return "", err
+	}
+
+	decision := Approved
+	if opts.Approve != nil {
+		if decision, err = opts.Approve(staged); err != nil {
+			if discardErr := c.Discard(staged); discardErr != nil {
+				c.log().Warn("Failed to discard staged upload", "path", staged.StagedPath, "error", discardErr)
+			}
+			return "", fmt.Errorf("approval failed: %w", err)
+		}
+	}
+
+	switch decision {
+	case Approved:
+		return c.Publish(staged, opts.Share)
+	case Rejected:
+		if err := c.Discard(staged); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%s: %w", c.redact(publicPath), ErrApprovalRejected)
+	case ApprovalPending:
+		return "", &ApprovalPendingError{Staged: staged}
+	default:
+		return "", fmt.Errorf("unknown approval decision: %d", decision)
+	}
+}
@@ -0,0 +1,88 @@
+package filebrowser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStageAndPublish(t *testing.T) {
+	localPath, content := writeTestFile(t, 1024)
+
+	tests := []struct {
+		name      string
+		decision  ApprovalDecision
+		wantErr   error
+		published bool
+		staged    bool
+	}{
+		{name: "Approved", decision: Approved, published: true},
+		{name: "Rejected", decision: Rejected, wantErr: ErrApprovalRejected},
+		{name: "Pending", decision: ApprovalPending, wantErr: ErrApprovalPending, staged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMemServer(t, nil)
+			client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+			var reviewed *StagedUpload
+			hash, err := client.StageAndPublish(localPath, "public/report.bin", StageOptions{
+				Approve: func(staged *StagedUpload) (ApprovalDecision, error) {
+					reviewed = staged
+					if data, ok := server.file(staged.StagedPath); !ok || string(data) != string(content) {
+						t.Errorf("staged file %s missing during review", staged.StagedPath)
+					}
+					return tt.decision, nil
+				},
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StageAndPublish() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.HasPrefix(reviewed.StagedPath, "staging/") {
+				t.Errorf("StagedPath = %s, want below staging/", reviewed.StagedPath)
+			}
+
+			if _, ok := server.file("public/report.bin"); ok != tt.published {
+				t.Errorf("public file exists = %v, want %v", ok, tt.published)
+			}
+			if tt.published && hash == "" {
+				t.Error("StageAndPublish() returned empty share hash")
+			}
+			if _, ok := server.file(reviewed.StagedPath); ok != tt.staged {
+				t.Errorf("staged file exists = %v, want %v", ok, tt.staged)
+			}
+
+			var pending *ApprovalPendingError
+			if errors.As(err, &pending) {
+				if _, err := client.Publish(pending.Staged, ShareParams{}); err != nil {
+					t.Fatalf("Publish() error = %v", err)
+				}
+				if _, ok := server.file("public/report.bin"); !ok {
+					t.Error("public file missing after Publish()")
+				}
+			}
+		})
+	}
+}
+
+func TestPublishDoesNotOverwrite(t *testing.T) {
+	localPath, _ := writeTestFile(t, 16)
+	server := newMemServer(t, map[string][]byte{"public/report.bin": []byte("original")})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	staged, err := client.Stage(localPath, "public/report.bin", "review")
+	if err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if !strings.HasPrefix(staged.Dir, "review/") {
+		t.Errorf("Dir = %s, want below review/", staged.Dir)
+	}
+
+	if _, err := client.Publish(staged, ShareParams{}); err == nil {
+		t.Error("Publish() over an existing file should fail")
+	}
+	if data, _ := server.file("public/report.bin"); string(data) != "original" {
+		t.Errorf("public file = %q, want original content", data)
+	}
+}
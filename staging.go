@@ -0,0 +1,89 @@
+package filebrowser
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// stagingPrefix names the directory below the system temp dir holding the staging
+// directories of all processes
+const stagingPrefix = "filebrowser-sdk"
+
+var (
+	stagingOnce sync.Once
+	stagingName string // Name of the staging directory of this process
+)
+
+// StagingDir returns the directory downloads and spooled uploads of this process are
+// staged in, below the system temp dir or Defaults.StagingDir. Every process stages in its own directory, so
+// the leftovers of crashed runs can be told apart and removed with CleanupStaleStaging.
+func StagingDir() string {
+	stagingOnce.Do(func() {
+		stagingName = fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	})
+	root := CurrentDefaults().StagingDir
+	if root == "" {
+		root = os.TempDir()
+	}
+	return filepath.Join(root, stagingPrefix, stagingName)
+}
+
+// CleanupStaleStaging removes the staging directories of other processes in which nothing
+// changed for olderThan, e.g. at startup to reclaim the files of crashed runs. It returns
+// the number of directories removed. Pick olderThan longer than any transfer may stall,
+// as running processes are only recognized by their recent changes.
+func CleanupStaleStaging(olderThan time.Duration) (int, error) {
+	root := filepath.Dir(StagingDir())
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to list staging directories: %w", err)
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-olderThan)
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == stagingName {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if lastChange(dir).After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return removed, fmt.Errorf("failed to remove stale staging directory: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// lastChange returns the latest modification time of dir and the files below it
+func lastChange(dir string) time.Time {
+	var latest time.Time
+	filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest
+}
+
+// createStagingFile creates a temporary file in the staging directory, see os.CreateTemp
+func createStagingFile(pattern string) (*os.File, error) {
+	dir := StagingDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	return os.CreateTemp(dir, pattern)
+}
@@ -0,0 +1,55 @@
+package filebrowser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCleanupStaleStaging(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	root := filepath.Dir(StagingDir())
+	old := time.Now().Add(-48 * time.Hour)
+
+	// stageFiles creates a staging directory holding a file, last changed at modified
+	stageFiles := func(dir string, modified time.Time) {
+		t.Helper()
+		file := filepath.Join(dir, "files", "a.txt.123.part")
+		if err := EnsureFolderForFile(file); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("partial"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range []string{file, filepath.Dir(file), dir} {
+			if err := os.Chtimes(path, modified, modified); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	crashed := filepath.Join(root, "1-1")
+	stageFiles(crashed, old)
+	running := filepath.Join(root, "2-2")
+	stageFiles(running, old)
+	if err := os.WriteFile(filepath.Join(running, "files", "b.txt"), []byte("fresh"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stageFiles(StagingDir(), old)
+
+	removed, err := CleanupStaleStaging(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("CleanupStaleStaging() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("CleanupStaleStaging() removed %d directories, want 1", removed)
+	}
+	if _, err := os.Stat(crashed); !os.IsNotExist(err) {
+		t.Errorf("stale staging directory still exists: %v", err)
+	}
+	for _, dir := range []string{running, StagingDir()} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("staging directory %s was removed: %v", dir, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package filebrowser
+
+import (
+	"context"
+	"time"
+)
+
+// Operation identifies a client operation
+type Operation string
+
+// Operations reported to a StatsCollector
+const (
+	OpLogin          Operation = "login"
+	OpUpload         Operation = "upload"
+	OpDownload       Operation = "download"
+	OpShare          Operation = "share"
+	OpGetResource    Operation = "get_resource"
+	OpGetChecksum    Operation = "get_checksum"
+	OpDeleteResource Operation = "delete_resource"
+	OpMoveResource   Operation = "move_resource"
+	OpCopyResource   Operation = "copy_resource"
+	OpGetPreview     Operation = "get_preview"
+	OpGetSettings    Operation = "get_settings"
+	OpUpdateSettings Operation = "update_settings"
+	OpMkdir          Operation = "mkdir"
+	OpListShares     Operation = "list_shares"
+	OpAppend         Operation = "append"
+	OpChmod          Operation = "chmod"
+	OpUpdateResource Operation = "update_resource"
+)
+
+// OperationStats contains statistics about a completed operation
+type OperationStats struct {
+	Operation Operation
+	Path      string
+	Bytes     int64         // Bytes transferred, zero for metadata operations
+	Duration  time.Duration // Wall time of the whole operation
+	Retries   int           // Number of retried attempts
+	Err       error         // Error the operation finished with, if any
+
+	// Attempts lists the HTTP attempts of operations that retry, if Client.Verbose is set
+	Attempts []AttemptInfo
+}
+
+// Throughput returns the average throughput of the operation in bytes per second
+func (s OperationStats) Throughput() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Duration.Seconds()
+}
+
+// StatsCollector receives statistics for every completed operation, successful or not.
+// Implementations must be safe for concurrent use.
+type StatsCollector interface {
+	CollectStats(stats OperationStats)
+}
+
+// StatsCollectorFunc adapts an ordinary function to the StatsCollector interface
+type StatsCollectorFunc func(stats OperationStats)
+
+// CollectStats calls f(stats)
+func (f StatsCollectorFunc) CollectStats(stats OperationStats) {
+	f(stats)
+}
+
+// OperationObserver is a StatsCollector also told when operations start, e.g. to trace
+// them as spans in the context of the caller. The requests of the operation are sent with
+// the returned context, and finish receives its statistics instead of CollectStats.
+type OperationObserver interface {
+	StatsCollector
+	StartOperation(ctx context.Context, op Operation, path string) (_ context.Context, finish func(OperationStats))
+}
+
+// MultiStats returns a collector passing the statistics of every operation to each of the
+// collectors, e.g. to keep a custom collector next to Telemetry. Nil collectors are
+// skipped.
+func MultiStats(collectors ...StatsCollector) StatsCollector {
+	var multi multiStats
+	for _, collector := range collectors {
+		if collector != nil {
+			multi = append(multi, collector)
+		}
+	}
+	return multi
+}
+
+// multiStats is the collector returned by MultiStats
+type multiStats []StatsCollector
+
+// CollectStats implements StatsCollector
+func (m multiStats) CollectStats(stats OperationStats) {
+	for _, collector := range m {
+		collector.CollectStats(stats)
+	}
+}
+
+// StartOperation implements OperationObserver, starting the operation with every observer
+// in turn so later ones see the context of earlier ones
+func (m multiStats) StartOperation(ctx context.Context, op Operation, path string) (context.Context, func(OperationStats)) {
+	finishes := make([]func(OperationStats), len(m))
+	for i, collector := range m {
+		finishes[i] = collector.CollectStats
+		if observer, ok := collector.(OperationObserver); ok {
+			ctx, finishes[i] = observer.StartOperation(ctx, op, path)
+		}
+	}
+	return ctx, func(stats OperationStats) {
+		for _, finish := range finishes {
+			finish(stats)
+		}
+	}
+}
+
+// opStart marks the start of an operation reported to a StatsCollector
+type opStart struct {
+	at     time.Time
+	finish func(OperationStats) // Nil without a collector
+}
+
+// startStats starts an operation on path reported to collector, returning the context its
+// requests are sent with
+func startStats(ctx context.Context, collector StatsCollector, op Operation, path string) (context.Context, opStart) {
+	start := opStart{at: time.Now()}
+	switch collector := collector.(type) {
+	case nil:
+	case OperationObserver:
+		ctx, start.finish = collector.StartOperation(ctx, op, path)
+	default:
+		start.finish = collector.CollectStats
+	}
+	return ctx, start
+}
+
+// reportStats hands the statistics of an operation to its collector, if any
+func reportStats(stats OperationStats, start opStart) {
+	if start.finish == nil {
+		return
+	}
+	stats.Duration = time.Since(start.at)
+	start.finish(stats)
+}
@@ -0,0 +1,97 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// SaveAndShareStreaming is like SaveAndShare, but pipes the download of the external URL
+// into the upload as it arrives instead of writing it to a temporary file first, for hosts
+// with small disks or huge files. Only one upload chunk is held in memory at a time. It
+// falls back to a temporary file for sources not reporting their size, in strict mode and
+// with SkipByHash, which read the file twice.
+func SaveAndShareStreaming(auth FilebrowserAuth, externalURL string, remotePathFn func(string) string, actionParams ActionParams) (*ShareResult, error) {
+	return (&Pipeline{Download: StreamStage}).Run(auth, externalURL, remotePathFn, actionParams)
+}
+
+// StreamStage is a Pipeline download stage opening the external URL for UploadStage to
+// stream from, see SaveAndShareStreaming. It runs DownloadStage instead for sources not
+// reporting their size, in strict mode and with SkipByHash. Transforms need a downloaded file and cannot
+// follow it.
+func StreamStage(state *PipelineState) error {
+	if state.Params.Strict || state.Params.Skip == SkipByHash {
+		return DownloadStage(state)
+	}
+
+	opts := state.downloadOptions()
+	body, size, err := openSource(state.Context, state.ExternalURL, opts)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	if size < 0 {
+		body.Close()
+		packageLog(opts.Logger).Info("Source reports no size, downloading to a temporary file", "url", state.ExternalURL)
+		return DownloadStage(state)
+	}
+
+	state.stream, state.streamSize = body, size
+	if opts.Progress != nil {
+		state.stream = readCloser{NewProgressReader(body, size, opts.Progress), body}
+	}
+	if state.Params.FileSize == 0 {
+		state.Params.FileSize = size
+	}
+	state.RemotePath = state.RemotePathFn(filepath.Base(urlFileName(state.ExternalURL)))
+	return nil
+}
+
+// uploadStream uploads the stream opened by StreamStage to remotePath
+func uploadStream(state *PipelineState, remotePath string) error {
+	start := time.Now()
+	err := state.API.UploadReader(state.stream, state.streamSize, remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	state.Upload = &UploadResult{RemotePath: remotePath, Bytes: state.streamSize, Duration: time.Since(start)}
+	return nil
+}
+
+// openSource starts downloading fileURL as allowed by opts and returns its body, which
+// the caller must close, together with its size or -1 if unknown
+func openSource(ctx context.Context, fileURL string, opts DownloadOptions) (io.ReadCloser, int64, error) {
+	opts = opts.withDefaults()
+	client, err := downloadClient(fileURL, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	watch := watchIdle(ctx, timeoutOrDefault(opts.IdleTimeout, defaultIdleTimeout))
+	r := traceTimeouts(client.R().SetContext(watch.ctx)).DisableAutoReadResponse()
+	opts.Auth.apply(r)
+	resp, err := r.Get(fileURL)
+	if err != nil {
+		watch.stop()
+		return nil, 0, fmt.Errorf("download request failed: %w", watch.err(err))
+	}
+	body := watch.reader(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		body.Close()
+		return nil, 0, fmt.Errorf("download failed with status code: %d", resp.StatusCode)
+	}
+	if opts.MaxBytes > 0 && resp.ContentLength > opts.MaxBytes {
+		body.Close()
+		return nil, 0, &DownloadTooLargeError{URL: fileURL, Limit: opts.MaxBytes, Size: resp.ContentLength}
+	}
+	return body, resp.ContentLength, nil
+}
+
+// readCloser reads from a wrapper of a body and closes the body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
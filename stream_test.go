@@ -0,0 +1,67 @@
+package filebrowser
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestSaveAndShareStreaming(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	content := bytes.Repeat([]byte("stream "), 64*1024)
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/unsized.bin" {
+			// Flushing before writing makes the response chunked, without a size
+			w.(http.Flusher).Flush()
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		}
+		w.Write(content)
+	}))
+	defer origin.Close()
+
+	server := newMemServer(t, nil)
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	remotePathFn := func(name string) string { return "in/" + name }
+
+	var progress int64
+	params := ActionParams{URLPolicy: localOrigin}
+	pipeline := &Pipeline{Download: StreamStage, Progress: func(stage string, done int64, total int64) { progress = done }}
+	result, err := pipeline.Run(auth, origin.URL+"/sized.bin", remotePathFn, params)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if uploaded, _ := server.file("in/sized.bin"); !bytes.Equal(uploaded, content) || result.RemotePath != "in/sized.bin" {
+		t.Errorf("uploaded %d bytes to %s, want the streamed file", len(uploaded), result.RemotePath)
+	}
+	if progress != int64(len(content)) {
+		t.Errorf("progress = %d, want %d", progress, len(content))
+	}
+	if entries, _ := os.ReadDir(StagingDir()); len(entries) > 0 {
+		t.Errorf("staging directory holds %d entries, want none for a streamed file", len(entries))
+	}
+
+	// Sources without a size are downloaded to a temporary file
+	if _, err := SaveAndShareStreaming(auth, origin.URL+"/unsized.bin", remotePathFn, params); err != nil {
+		t.Fatalf("SaveAndShareStreaming() of unsized source error = %v", err)
+	}
+	if uploaded, _ := server.file("in/unsized.bin"); !bytes.Equal(uploaded, content) {
+		t.Errorf("uploaded %d bytes of unsized source, want %d", len(uploaded), len(content))
+	}
+	if _, err := os.Stat(LocalPathForDownload(origin.URL + "/unsized.bin")); err != nil {
+		t.Errorf("unsized source was not downloaded to a file: %v", err)
+	}
+
+	// An existing file of the same size is kept
+	server.setFile("in/sized.bin", bytes.Repeat([]byte("x"), len(content)))
+	if _, err := SaveAndShareStreaming(auth, origin.URL+"/sized.bin", remotePathFn, params); err != nil {
+		t.Fatalf("SaveAndShareStreaming() of existing file error = %v", err)
+	}
+	if uploaded, _ := server.file("in/sized.bin"); bytes.Equal(uploaded, content) {
+		t.Error("existing file of the same size was replaced")
+	}
+}
@@ -0,0 +1,166 @@
+package filebrowser
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+)
+
+// Reasons reported in SyncEntry.Reason
+const (
+	SyncReasonMissing   = "missing"   // file does not exist remotely
+	SyncReasonSize      = "size"      // remote size differs from local size
+	SyncReasonChecksum  = "checksum"  // sizes match but checksums differ
+	SyncReasonIdentical = "identical" // remote file matches local file
+	SyncReasonLocked    = "locked"    // remote file has a lock sentinel
+)
+
+// SyncEntry describes how a single local file relates to its remote counterpart
+type SyncEntry struct {
+	LocalPath  string
+	RemotePath string
+	Size       int64
+	RemoteSize int64  // size of the remote file, -1 if it is missing and 0 if locked
+	Transfer   bool   // true if the file would be uploaded
+	Reason     string // one of the SyncReason constants
+}
+
+// SyncReport summarizes what a sync between a local and a remote directory would do
+type SyncReport struct {
+	Entries       []SyncEntry
+	TransferFiles int
+	TransferBytes int64
+	SkipFiles     int
+	SkipBytes     int64
+}
+
+// add records an entry and updates the totals
+func (r *SyncReport) add(entry SyncEntry) {
+	r.Entries = append(r.Entries, entry)
+	if entry.Transfer {
+		r.TransferFiles++
+		r.TransferBytes += entry.Size
+	} else {
+		r.SkipFiles++
+		r.SkipBytes += entry.Size
+	}
+}
+
+// SyncOptions contains optional parameters for AnalyzeSyncWithOptions
+type SyncOptions struct {
+	// CompareChecksum additionally compares files with matching sizes by their SHA-256 checksum
+	CompareChecksum bool
+	// SkipLocked skips files with a lock sentinel (see LockSuffix), as they are being edited
+	SkipLocked bool
+	// Filter, if set, limits the local files compared; others are left out of the report.
+	// Their paths are relative to the local directory.
+	Filter Filter
+}
+
+// AnalyzeSync compares a local directory with a remote directory without transferring anything.
+// Files missing remotely or differing in size are reported as transfers. When compareChecksum
+// is set, files with matching sizes are additionally compared by their SHA-256 checksum.
+func (c *Client) AnalyzeSync(localDir string, remoteDir string, compareChecksum bool) (*SyncReport, error) {
+	return c.AnalyzeSyncWithOptions(localDir, remoteDir, SyncOptions{CompareChecksum: compareChecksum})
+}
+
+// AnalyzeSyncWithOptions compares directories like AnalyzeSync, applying the given options
+func (c *Client) AnalyzeSyncWithOptions(localDir string, remoteDir string, opts SyncOptions) (_ *SyncReport, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if localDir == "" {
+		return nil, fmt.Errorf("local directory cannot be empty")
+	}
+	if remoteDir == "" {
+		return nil, fmt.Errorf("remote directory cannot be empty")
+	}
+
+	report := &SyncReport{}
+	err = filepath.WalkDir(localDir, func(localPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", localPath, err)
+		}
+
+		rel, err := filepath.Rel(localDir, localPath)
+		if err != nil {
+			return err
+		}
+		if !matchFilter(opts.Filter, FilterEntry{Path: filepath.ToSlash(rel), Size: info.Size(), Modified: info.ModTime()}) {
+			return nil
+		}
+
+		entry := SyncEntry{
+			LocalPath:  localPath,
+			RemotePath: path.Join(remoteDir, filepath.ToSlash(rel)),
+			Size:       info.Size(),
+		}
+		if err := c.classifySyncEntry(&entry, opts); err != nil {
+			return err
+		}
+
+		report.add(entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze sync: %w", err)
+	}
+
+	return report, nil
+}
+
+// classifySyncEntry decides whether a local file needs to be transferred
+func (c *Client) classifySyncEntry(entry *SyncEntry, opts SyncOptions) error {
+	if opts.SkipLocked {
+		locked, err := c.IsLocked(entry.RemotePath)
+		if err != nil {
+			return err
+		}
+		if locked {
+			entry.Reason = SyncReasonLocked
+			return nil
+		}
+	}
+
+	resource, err := c.lookupResource(entry.RemotePath)
+	if err != nil {
+		return fmt.Errorf("failed to get resource info for %s: %w", entry.RemotePath, err)
+	}
+
+	entry.RemoteSize = -1
+	if resource != nil {
+		entry.RemoteSize = resource.Size
+	}
+	switch {
+	case resource == nil:
+		entry.Transfer, entry.Reason = true, SyncReasonMissing
+	case resource.Size != entry.Size:
+		entry.Transfer, entry.Reason = true, SyncReasonSize
+	case opts.CompareChecksum:
+		local, err := fileSHA256(entry.LocalPath)
+		if err != nil {
+			return err
+		}
+		remote, err := c.GetChecksum(entry.RemotePath, "sha256")
+		if err != nil {
+			return err
+		}
+		if local != remote {
+			entry.Transfer, entry.Reason = true, SyncReasonChecksum
+		} else {
+			entry.Reason = SyncReasonIdentical
+		}
+	default:
+		entry.Reason = SyncReasonIdentical
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package filebrowser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnalyzeSync(t *testing.T) {
+	localDir := t.TempDir()
+	localFiles := map[string]string{
+		"same.txt":       "identical",
+		"changed.txt":    "local-data",
+		"new.txt":        "brand new",
+		"sub/nested.txt": "nested",
+	}
+	for name, content := range localFiles {
+		localPath := filepath.Join(localDir, filepath.FromSlash(name))
+		if err := EnsureFolderForFile(localPath); err != nil {
+			t.Fatalf("EnsureFolderForFile() error = %v", err)
+		}
+		if err := os.WriteFile(localPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write local file: %v", err)
+		}
+	}
+
+	server := newResourceServer(t, map[string][]byte{
+		"remote/same.txt":       []byte("identical"),
+		"remote/changed.txt":    []byte("remote-dat"),
+		"remote/sub/nested.txt": []byte("nested!"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	tests := []struct {
+		name            string
+		compareChecksum bool
+		wantReasons     map[string]string
+		wantTransfer    int64
+	}{
+		{
+			name:            "Size only",
+			compareChecksum: false,
+			wantReasons: map[string]string{
+				"remote/same.txt":       SyncReasonIdentical,
+				"remote/changed.txt":    SyncReasonIdentical,
+				"remote/new.txt":        SyncReasonMissing,
+				"remote/sub/nested.txt": SyncReasonSize,
+			},
+			wantTransfer: int64(len("brand new") + len("nested")),
+		},
+		{
+			name:            "With checksum",
+			compareChecksum: true,
+			wantReasons: map[string]string{
+				"remote/same.txt":       SyncReasonIdentical,
+				"remote/changed.txt":    SyncReasonChecksum,
+				"remote/new.txt":        SyncReasonMissing,
+				"remote/sub/nested.txt": SyncReasonSize,
+			},
+			wantTransfer: int64(len("local-data") + len("brand new") + len("nested")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := client.AnalyzeSync(localDir, "remote", tt.compareChecksum)
+			if err != nil {
+				t.Fatalf("AnalyzeSync() error = %v", err)
+			}
+
+			if len(report.Entries) != len(tt.wantReasons) {
+				t.Fatalf("AnalyzeSync() returned %d entries, want %d", len(report.Entries), len(tt.wantReasons))
+			}
+			for _, entry := range report.Entries {
+				if want := tt.wantReasons[entry.RemotePath]; entry.Reason != want {
+					t.Errorf("entry %s reason = %v, want %v", entry.RemotePath, entry.Reason, want)
+				}
+			}
+			if report.TransferBytes != tt.wantTransfer {
+				t.Errorf("TransferBytes = %v, want %v", report.TransferBytes, tt.wantTransfer)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultTailInterval is how often Tail polls if no interval is given
+const defaultTailInterval = time.Second
+
+// Tail follows a remote file growing on the server, e.g. a job log written by another
+// system, like tail -f. The bytes appended after the call are sent on the returned
+// channel, checking for new ones every pollInterval (1s if not positive). The file does
+// not need to exist yet, and a file truncated in between is followed from its start.
+// The channel is closed once ctx is done, stop is called or polling fails; stop returns
+// the error that ended polling, if any.
+func (c *Client) Tail(ctx context.Context, remotePath string, pollInterval time.Duration) (<-chan []byte, func() error) {
+	if pollInterval <= 0 {
+		pollInterval = defaultTailInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	chunks := make(chan []byte)
+	done := make(chan struct{})
+	var err error
+	go func() {
+		defer close(done)
+		defer close(chunks)
+		err = c.follow(ctx, remotePath, pollInterval, chunks)
+	}()
+
+	stop := func() error {
+		cancel()
+		<-done
+		return err
+	}
+	return chunks, stop
+}
+
+// follow polls the size of a remote file and sends the bytes appended to it on chunks
+// until ctx is done
+func (c *Client) follow(ctx context.Context, remotePath string, pollInterval time.Duration, chunks chan<- []byte) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	offset := int64(-1) // Unknown until the first poll
+	for {
+		resource, err := c.lookupResourceContext(ctx, remotePath)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to follow %s: %w", remotePath, err)
+		}
+
+		var size int64
+		if resource != nil {
+			size = resource.Size
+		}
+		switch {
+		case offset < 0:
+			offset = size // Start at the current end
+		case size < offset:
+			offset = 0 // Truncated, follow from the start
+		}
+
+		if size > offset {
+			data, err := c.readFrom(ctx, remotePath, offset)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to follow %s: %w", remotePath, err)
+			}
+			if len(data) > 0 {
+				select {
+				case chunks <- data:
+				case <-ctx.Done():
+					return nil
+				}
+				offset += int64(len(data))
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// readFrom reads a remote file from offset to its end with a ranged request
+func (c *Client) readFrom(ctx context.Context, remotePath string, offset int64) (data []byte, err error) {
+	ctx, start := c.startOp(ctx, OpDownload, remotePath)
+	defer func() { err = c.finishOp(OpDownload, remotePath, int64(len(data)), start, err) }()
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	url, err := c.apiURL("raw", remotePath)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.newRequest(ctx).
+		SetHeader("Range", fmt.Sprintf("bytes=%d-", offset)).
+		Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("download request failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp.Bytes(), nil
+	case http.StatusOK:
+		// The server ignored the range
+		if body := resp.Bytes(); int64(len(body)) > offset {
+			return body[offset:], nil
+		}
+		return nil, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Truncated since its size was checked, the next poll notices
+		return nil, nil
+	}
+	if err := checkDenied(resp, remotePath); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("download request failed with %w", newAPIError(resp))
+}
@@ -0,0 +1,76 @@
+package filebrowser
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTail(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"jobs/run.log": []byte("before\n")})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	// Signal once the size was looked up for the first time
+	polled := make(chan struct{}, 1)
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if strings.HasPrefix(r.URL.Path, "/api/resources/") {
+			select {
+			case polled <- struct{}{}:
+			default:
+			}
+		}
+	})
+
+	chunks, stop := client.Tail(context.Background(), "jobs/run.log", 5*time.Millisecond)
+	<-polled
+
+	receive := func(want string) {
+		t.Helper()
+		var got string
+		timeout := time.After(2 * time.Second)
+		for got != want {
+			select {
+			case chunk, ok := <-chunks:
+				if !ok {
+					t.Fatalf("channel closed after %q, want %q", got, want)
+				}
+				got += string(chunk)
+			case <-timeout:
+				t.Fatalf("received %q, want %q", got, want)
+			}
+		}
+	}
+
+	server.setFile("jobs/run.log", []byte("before\nstep 1\n"))
+	receive("step 1\n")
+	server.setFile("jobs/run.log", []byte("before\nstep 1\nstep 2\ndone\n"))
+	receive("step 2\ndone\n")
+	server.setFile("jobs/run.log", []byte("rotated\n"))
+	receive("rotated\n")
+
+	if err := stop(); err != nil {
+		t.Errorf("stop() error = %v", err)
+	}
+	if _, ok := <-chunks; ok {
+		t.Error("channel still open after stop()")
+	}
+}
+
+func TestTailError(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+
+	chunks, stop := client.Tail(context.Background(), "run.log", time.Millisecond)
+	for range chunks {
+	}
+	if err := stop(); err == nil {
+		t.Error("stop() error = nil, want the polling error")
+	}
+}
@@ -0,0 +1,143 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName names the tracer and meter of the SDK
+const instrumentationName = "github.com/kiuber/filebrowser-sdk/v2"
+
+// Telemetry is a StatsCollector recording every operation as an OpenTelemetry span and in
+// metrics:
+//
+//   - filebrowser.client.operations: operations by operation and status
+//   - filebrowser.client.operation.duration: durations in seconds by operation and status
+//   - filebrowser.client.transferred: bytes uploaded and downloaded by operation
+//   - filebrowser.client.retries: retried attempts by operation
+//
+// Client operations are traced as spans started with the operation in the context of the
+// caller, so they are children of its span and parents of spans of its HTTP requests, e.g.
+// by otelhttp middleware. Spans carry the operation, path, bytes, retries and status.
+type Telemetry struct {
+	tracer      trace.Tracer
+	operations  metric.Int64Counter
+	duration    metric.Float64Histogram
+	transferred metric.Int64Counter
+	retries     metric.Int64Counter
+}
+
+// NewTelemetry creates a Telemetry collector recording spans with tp and metrics with
+// mp. Either may be nil to record no spans or no metrics.
+func NewTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) (*Telemetry, error) {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	if mp == nil {
+		mp = metricnoop.NewMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName)
+
+	t := &Telemetry{tracer: tp.Tracer(instrumentationName)}
+	var err error
+	if t.operations, err = meter.Int64Counter("filebrowser.client.operations",
+		metric.WithDescription("Operations of the Filebrowser client"), metric.WithUnit("{operation}")); err != nil {
+		return nil, fmt.Errorf("failed to create operations counter: %w", err)
+	}
+	if t.duration, err = meter.Float64Histogram("filebrowser.client.operation.duration",
+		metric.WithDescription("Duration of the operations of the Filebrowser client"), metric.WithUnit("s")); err != nil {
+		return nil, fmt.Errorf("failed to create duration histogram: %w", err)
+	}
+	if t.transferred, err = meter.Int64Counter("filebrowser.client.transferred",
+		metric.WithDescription("Bytes uploaded and downloaded by the Filebrowser client"), metric.WithUnit("By")); err != nil {
+		return nil, fmt.Errorf("failed to create transferred bytes counter: %w", err)
+	}
+	if t.retries, err = meter.Int64Counter("filebrowser.client.retries",
+		metric.WithDescription("Retried attempts of the operations of the Filebrowser client"), metric.WithUnit("{retry}")); err != nil {
+		return nil, fmt.Errorf("failed to create retries counter: %w", err)
+	}
+	return t, nil
+}
+
+// StartOperation implements OperationObserver, starting the span of the operation
+func (t *Telemetry) StartOperation(ctx context.Context, op Operation, path string) (context.Context, func(OperationStats)) {
+	ctx, span := t.startSpan(ctx, op, path)
+	return ctx, func(stats OperationStats) {
+		endSpan(span, stats)
+		t.record(ctx, stats)
+	}
+}
+
+// CollectStats implements StatsCollector for operations reported without StartOperation.
+// Their spans have no parent and are timed from the duration of the operation.
+func (t *Telemetry) CollectStats(stats OperationStats) {
+	ctx := context.Background()
+	end := time.Now()
+	_, span := t.startSpan(ctx, stats.Operation, stats.Path, trace.WithTimestamp(end.Add(-stats.Duration)))
+	endSpan(span, stats, trace.WithTimestamp(end))
+	t.record(ctx, stats)
+}
+
+// startSpan starts the span of an operation on path
+func (t *Telemetry) startSpan(ctx context.Context, op Operation, path string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	opts = append(opts,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("filebrowser.operation", string(op)),
+			attribute.String("filebrowser.path", path),
+		))
+	return t.tracer.Start(ctx, "filebrowser."+string(op), opts...)
+}
+
+// endSpan ends the span of an operation with its statistics
+func endSpan(span trace.Span, stats OperationStats, opts ...trace.SpanEndOption) {
+	span.SetAttributes(
+		attribute.Int64("filebrowser.bytes", stats.Bytes),
+		attribute.Int("filebrowser.retries", stats.Retries),
+	)
+	if stats.Err != nil {
+		span.RecordError(stats.Err)
+		span.SetStatus(codes.Error, stats.Err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End(opts...)
+}
+
+// record adds the statistics of an operation to the metrics
+func (t *Telemetry) record(ctx context.Context, stats OperationStats) {
+	status := "ok"
+	if stats.Err != nil {
+		status = "error"
+	}
+	operation := attribute.String("operation", string(stats.Operation))
+	withStatus := metric.WithAttributes(operation, attribute.String("status", status))
+	t.operations.Add(ctx, 1, withStatus)
+	t.duration.Record(ctx, stats.Duration.Seconds(), withStatus)
+	if stats.Bytes > 0 {
+		t.transferred.Add(ctx, stats.Bytes, metric.WithAttributes(operation))
+	}
+	if stats.Retries > 0 {
+		t.retries.Add(ctx, int64(stats.Retries), metric.WithAttributes(operation))
+	}
+}
+
+// WithTracerProvider records every operation of the client as a span, see Telemetry. It
+// sets Client.Stats, like WithMeterProvider, keeping a collector of WithStats.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *clientOptions) { o.tracerProvider = tp }
+}
+
+// WithMeterProvider records the operations of the client in metrics, see Telemetry. It
+// sets Client.Stats, like WithTracerProvider, keeping a collector of WithStats.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(o *clientOptions) { o.meterProvider = mp }
+}
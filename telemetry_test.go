@@ -0,0 +1,129 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTelemetry(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"docs/a.txt": []byte("hello")})
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	client, err := NewClient(server.URL, "user", "pass",
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetResource("docs/a.txt"); err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if _, err := client.GetResource("docs/missing.txt"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetResource() of a missing file error = %v", err)
+	}
+
+	var found []string
+	for _, span := range spans.Ended() {
+		if span.Name() != "filebrowser."+string(OpGetResource) {
+			continue
+		}
+		var path string
+		for _, attr := range span.Attributes() {
+			if attr.Key == "filebrowser.path" {
+				path = attr.Value.AsString()
+			}
+		}
+		found = append(found, path+" "+span.Status().Code.String())
+		if span.EndTime().Before(span.StartTime()) {
+			t.Errorf("span %s ends before it starts", span.Name())
+		}
+	}
+	if len(found) != 2 || found[0] != "docs/a.txt "+codes.Ok.String() || found[1] != "docs/missing.txt "+codes.Error.String() {
+		t.Errorf("get_resource spans = %v", found)
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &metrics); err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int64{}
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != "filebrowser.client.operations" {
+				continue
+			}
+			for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				operation, _ := point.Attributes.Value("operation")
+				status, _ := point.Attributes.Value("status")
+				counts[operation.AsString()+" "+status.AsString()] += point.Value
+			}
+		}
+	}
+	if counts["get_resource ok"] != 1 || counts["get_resource error"] != 1 || counts["login ok"] != 1 {
+		t.Errorf("operation counts = %v", counts)
+	}
+}
+
+func TestTelemetryContext(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"docs/a.txt": []byte("hello")})
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+
+	// Requests are sent within the span of their operation
+	var requestSpans []trace.SpanID
+	record := func(next Handler) Handler {
+		return func(r *http.Request) (*http.Response, error) {
+			requestSpans = append(requestSpans, trace.SpanContextFromContext(r.Context()).SpanID())
+			return next(r)
+		}
+	}
+	var collected []Operation
+	client, err := NewClient(server.URL, "user", "pass", WithTracerProvider(tp), WithMiddleware(record),
+		WithStats(StatsCollectorFunc(func(stats OperationStats) { collected = append(collected, stats.Operation) })))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Login(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	requestSpans = nil
+	if _, err := client.GetResourceContext(ctx, "docs/a.txt"); err != nil {
+		t.Fatalf("GetResourceContext() error = %v", err)
+	}
+	parent.End()
+
+	var span sdktrace.ReadOnlySpan
+	for _, ended := range spans.Ended() {
+		if ended.Name() == "filebrowser."+string(OpGetResource) {
+			span = ended
+		}
+	}
+	if span == nil {
+		t.Fatal("no get_resource span")
+	}
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() || span.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("get_resource span parent = %v, want the caller's span %v", span.Parent().SpanID(), parent.SpanContext().SpanID())
+	}
+	if len(requestSpans) != 1 || requestSpans[0] != span.SpanContext().SpanID() {
+		t.Errorf("request spans = %v, want the get_resource span %v", requestSpans, span.SpanContext().SpanID())
+	}
+
+	// The collector of WithStats is kept next to the telemetry
+	if len(collected) != 2 || collected[0] != OpLogin || collected[1] != OpGetResource {
+		t.Errorf("collected operations = %v, want login and get_resource", collected)
+	}
+}
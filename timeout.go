@@ -0,0 +1,201 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+// Default timeouts applied when the fields of Timeouts are zero
+const (
+	defaultAuthTimeout     = 30 * time.Second
+	defaultMetadataTimeout = time.Minute
+	defaultIdleTimeout     = 2 * time.Minute
+)
+
+// ErrTransferStalled is returned when an upload or download makes no progress for longer
+// than the idle timeout
+var ErrTransferStalled = errors.New("transfer stalled")
+
+// Timeouts limit how long requests may take, per class of operation. Zero fields use
+// defaults, negative ones disable the timeout. A timed out request fails with an error
+// matching context.DeadlineExceeded, a stalled transfer with ErrTransferStalled.
+type Timeouts struct {
+	// Auth limits the duration of each login request, 30s by default
+	Auth time.Duration
+	// Metadata limits the duration of each API request other than transfers, e.g. to get
+	// resource information or create shares, 1m by default
+	Metadata time.Duration
+	// Idle limits the time uploads and downloads may go without sending or receiving
+	// data, 2m by default. Transfers have no total timeout, as large files take long.
+	Idle time.Duration
+}
+
+// timeoutOrDefault returns timeout, fallback if it is zero, or zero if it is negative
+func timeoutOrDefault(timeout time.Duration, fallback time.Duration) time.Duration {
+	switch {
+	case timeout < 0:
+		return 0
+	case timeout == 0:
+		return fallback
+	default:
+		return timeout
+	}
+}
+
+// withTimeout makes r fail if it takes longer than timeout, unless timeout is zero. The
+// response must be read before the request returns, so it cannot be used for streams.
+func withTimeout(r *req.Request, timeout time.Duration) *req.Request {
+	if timeout <= 0 {
+		return r
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return r.SetContext(ctx).OnAfterResponse(func(*req.Client, *req.Response) error {
+		cancel()
+		return nil
+	})
+}
+
+// idleWatch cancels a transfer that made no progress for its idle timeout
+type idleWatch struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	timer  *time.Timer
+	idle   time.Duration
+	once   sync.Once
+}
+
+// watchIdle starts watching a transfer running under ctx, returning the context to run it
+// with. A zero idle timeout only lets the transfer be stopped.
+func watchIdle(ctx context.Context, idle time.Duration) *idleWatch {
+	w := &idleWatch{idle: idle}
+	w.ctx, w.cancel = context.WithCancelCause(ctx)
+	if idle > 0 {
+		w.timer = time.AfterFunc(idle, func() {
+			w.cancel(fmt.Errorf("%w: no progress for %s", ErrTransferStalled, idle))
+		})
+	}
+	return w
+}
+
+// progress restarts the idle timeout
+func (w *idleWatch) progress() {
+	if w.timer != nil {
+		w.timer.Reset(w.idle)
+	}
+}
+
+// stop ends the watch and releases the context
+func (w *idleWatch) stop() {
+	w.once.Do(func() {
+		if w.timer != nil {
+			w.timer.Stop()
+		}
+		w.cancel(nil)
+	})
+}
+
+// err returns the error a transfer failed with, replaced by the stall if it was cancelled
+// for lack of progress
+func (w *idleWatch) err(err error) error {
+	return stallError(w.ctx, err)
+}
+
+// stallError returns err wrapped in the stall that cancelled ctx, if it was
+func stallError(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); err != nil && errors.Is(cause, ErrTransferStalled) && !errors.Is(err, ErrTransferStalled) {
+		return fmt.Errorf("%w: %w", cause, err)
+	}
+	return err
+}
+
+// reader wraps the body of the transfer, restarting the idle timeout on every read and
+// stopping the watch when closed
+func (w *idleWatch) reader(body io.ReadCloser) io.ReadCloser {
+	return &idleReader{body: body, watch: w}
+}
+
+// idleReader is a transfer body watched by an idleWatch
+type idleReader struct {
+	body  io.ReadCloser
+	watch *idleWatch
+}
+
+// Read implements io.Reader
+func (r *idleReader) Read(b []byte) (int, error) {
+	n, err := r.body.Read(b)
+	if n > 0 {
+		r.watch.progress()
+	}
+	if err != nil && err != io.EOF {
+		err = classifyTimeout(r.watch.err(err), TimeoutRead)
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (r *idleReader) Close() error {
+	defer r.watch.stop()
+	return r.body.Close()
+}
+
+// idleTransport applies an idle timeout to every request it sends, counting both the
+// request body being sent and the response body being received as progress
+type idleTransport struct {
+	base http.RoundTripper
+	idle time.Duration
+}
+
+// RoundTrip implements http.RoundTripper
+func (t idleTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	tracker := &phaseTracker{}
+	watch := watchIdle(r.Context(), t.idle)
+	r = r.WithContext(tracker.trace(watch.ctx))
+	if r.Body != nil && r.Body != http.NoBody {
+		// The transport closes the request body, which must not end the watch
+		r.Body = &progressBody{ReadCloser: r.Body, watch: watch}
+	}
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		err = classifyTimeout(watch.err(err), tracker.current())
+		watch.stop()
+		return nil, err
+	}
+	watch.progress()
+	resp.Body = watch.reader(resp.Body)
+	return resp, nil
+}
+
+// progressBody is a request body restarting the idle timeout of a watch on every read
+type progressBody struct {
+	io.ReadCloser
+	watch *idleWatch
+}
+
+// Read implements io.Reader
+func (b *progressBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.watch.progress()
+	}
+	return n, err
+}
+
+// transferHTTPClient returns a copy of client applying the idle timeout to transfers and
+// reporting their timeouts as TimeoutErrors. A zero idle timeout disables the former.
+func transferHTTPClient(client *http.Client, idle time.Duration) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transfer := *client
+	transfer.Transport = idleTransport{base: base, idle: idle}
+	return &transfer
+}
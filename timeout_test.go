@@ -0,0 +1,95 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stall blocks a handler until the client gives up or the test is long over. The body is
+// read first, as closed connections are only noticed afterwards.
+func stall(r *http.Request) {
+	io.Copy(io.Discard, r.Body)
+	select {
+	case <-r.Context().Done():
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/login" && r.Header.Get("X-Test-Stall") == "":
+			w.Write([]byte("test-token"))
+		case strings.HasPrefix(r.URL.Path, "/api/raw/steady"):
+			// Slow but steady downloads are not cut by the idle timeout
+			for i := 0; i < 6; i++ {
+				w.Write([]byte("chunk"))
+				w.(http.Flusher).Flush()
+				time.Sleep(40 * time.Millisecond)
+			}
+		case strings.HasPrefix(r.URL.Path, "/api/raw/stalled"):
+			w.Write([]byte("start"))
+			w.(http.Flusher).Flush()
+			stall(r)
+		default:
+			stall(r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	timeouts := Timeouts{Auth: 100 * time.Millisecond, Metadata: 100 * time.Millisecond, Idle: 150 * time.Millisecond}
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}, Timeouts: timeouts}
+
+	if _, err := client.GetResource("a.txt"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetResource() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	var steady strings.Builder
+	if err := client.Download("steady.txt", &steady); err != nil || steady.Len() != 30 {
+		t.Errorf("Download() of slow file = %d bytes, %v, want 30 bytes", steady.Len(), err)
+	}
+	if err := client.Download("stalled.txt", io.Discard); !errors.Is(err, ErrTransferStalled) {
+		t.Errorf("Download() of stalled file error = %v, want ErrTransferStalled", err)
+	}
+
+	stalledLogin := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}, Timeouts: timeouts}
+	stalledLogin.httpClient = defaultHTTPClient.Clone().SetCommonHeader("X-Test-Stall", "1")
+	if err := stalledLogin.Login(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Login() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestUploadIdleTimeout(t *testing.T) {
+	server := newMemServer(t, nil)
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			stall(r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"},
+		Timeouts: Timeouts{Idle: 150 * time.Millisecond}}
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := client.Upload(localPath, "a.txt"); !errors.Is(err, ErrTransferStalled) {
+		t.Errorf("Upload() error = %v, want ErrTransferStalled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Upload() gave up after %v", elapsed)
+	}
+}
@@ -0,0 +1,129 @@
+package filebrowser
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http/httptrace"
+	"sync/atomic"
+
+	"github.com/imroc/req/v3"
+)
+
+// TimeoutPhase is the phase a request was in when it timed out
+type TimeoutPhase string
+
+// Phases of a request
+const (
+	// TimeoutConnect is the DNS lookup and the TCP connection to the server
+	TimeoutConnect TimeoutPhase = "connect"
+	// TimeoutTLS is the TLS handshake with the server
+	TimeoutTLS TimeoutPhase = "tls"
+	// TimeoutServer is sending the request and waiting for the server to respond
+	TimeoutServer TimeoutPhase = "server"
+	// TimeoutRead is reading the response body
+	TimeoutRead TimeoutPhase = "read"
+)
+
+// Errors matched by TimeoutErrors of the respective phase
+var (
+	ErrConnectTimeout = errors.New("connect timeout")
+	ErrTLSTimeout     = errors.New("TLS handshake timeout")
+	ErrServerTimeout  = errors.New("server timeout")
+	ErrReadTimeout    = errors.New("read timeout")
+)
+
+// phaseErrors maps the phases to the errors they match
+var phaseErrors = map[TimeoutPhase]error{
+	TimeoutConnect: ErrConnectTimeout,
+	TimeoutTLS:     ErrTLSTimeout,
+	TimeoutServer:  ErrServerTimeout,
+	TimeoutRead:    ErrReadTimeout,
+}
+
+// TimeoutError reports a request that timed out or stalled, and in which phase, telling
+// network problems (connect, TLS) from a slow server. It matches the error of its phase,
+// e.g. ErrServerTimeout, as well as the error it wraps, e.g. context.DeadlineExceeded or
+// ErrTransferStalled.
+type TimeoutError struct {
+	Op    Operation // Operation of the client that timed out, empty for other requests
+	Phase TimeoutPhase
+	Err   error
+}
+
+// Error implements the error interface
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s: %v", phaseErrors[e.Phase], e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the error of the phase
+func (e *TimeoutError) Is(target error) bool {
+	return target == phaseErrors[e.Phase]
+}
+
+// Timeout reports that the error is a timeout, like a net.Error
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// isTimeout reports whether err is a timeout or a stall
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTransferStalled) ||
+		(errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// classifyTimeout wraps err in a TimeoutError of phase if it is a timeout that is not
+// classified yet
+func classifyTimeout(err error, phase TimeoutPhase) error {
+	var timeout *TimeoutError
+	if err == nil || errors.As(err, &timeout) || !isTimeout(err) {
+		return err
+	}
+	return &TimeoutError{Phase: phase, Err: err}
+}
+
+// phaseTracker follows the phases of a request
+type phaseTracker struct {
+	phase atomic.Value // TimeoutPhase
+}
+
+// current returns the phase the request is in
+func (t *phaseTracker) current() TimeoutPhase {
+	if phase, ok := t.phase.Load().(TimeoutPhase); ok {
+		return phase
+	}
+	return TimeoutConnect
+}
+
+// trace returns ctx tracing the phases of the request sent with it
+func (t *phaseTracker) trace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		TLSHandshakeStart:    func() { t.phase.Store(TimeoutTLS) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.phase.Store(TimeoutConnect) },
+		GotConn:              func(httptrace.GotConnInfo) { t.phase.Store(TimeoutServer) },
+		GotFirstResponseByte: func() { t.phase.Store(TimeoutRead) },
+	})
+}
+
+// traceTimeouts makes timeouts of r fail with a TimeoutError of the phase they occurred
+// in. Contexts set on r afterwards must be derived from its context.
+func traceTimeouts(r *req.Request) *req.Request {
+	tracker := &phaseTracker{}
+	ctx := tracker.trace(r.Context())
+	return r.SetContext(ctx).
+		OnAfterResponse(func(_ *req.Client, resp *req.Response) error {
+			// Returning the error would skip the other middlewares
+			if resp != nil {
+				resp.Err = classifyTimeout(stallError(ctx, resp.Err), tracker.current())
+			}
+			return nil
+		})
+}
@@ -0,0 +1,118 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutPhases(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login":
+			w.Write([]byte("test-token"))
+		case "/api/raw/slow-body.txt":
+			w.Header().Set("Content-Length", "100")
+			w.Write([]byte("start"))
+			w.(http.Flusher).Flush()
+			<-release
+		default:
+			<-release
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// A TCP listener never completing the TLS handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	// A transport never establishing a connection
+	unreachable := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}}
+
+	timeouts := Timeouts{Auth: 100 * time.Millisecond, Metadata: 100 * time.Millisecond, Idle: 100 * time.Millisecond}
+	newClient := func(url string, opts ...Option) *Client {
+		client, err := NewClient(url, "user", "pass", append(opts, WithTimeouts(timeouts))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		want    error
+		op      Operation
+		wrapped error
+	}{
+		{
+			name:    "connect",
+			call:    func() error { return newClient("http://files.invalid", WithStdHTTPClient(unreachable)).Login() },
+			want:    ErrConnectTimeout,
+			op:      OpLogin,
+			wrapped: context.DeadlineExceeded,
+		},
+		{
+			name:    "tls",
+			call:    func() error { return newClient("https://" + listener.Addr().String()).Login() },
+			want:    ErrTLSTimeout,
+			op:      OpLogin,
+			wrapped: context.DeadlineExceeded,
+		},
+		{
+			name: "server",
+			call: func() error {
+				_, err := newClient(server.URL).GetResource("slow.txt")
+				return err
+			},
+			want:    ErrServerTimeout,
+			op:      OpGetResource,
+			wrapped: context.DeadlineExceeded,
+		},
+		{
+			name: "read",
+			call: func() error {
+				return newClient(server.URL).Download("slow-body.txt", io.Discard)
+			},
+			want:    ErrReadTimeout,
+			op:      OpDownload,
+			wrapped: ErrTransferStalled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			var timeout *TimeoutError
+			if !errors.Is(err, tt.want) || !errors.Is(err, tt.wrapped) || !errors.As(err, &timeout) {
+				t.Fatalf("error = %v, want a %v wrapping %v", err, tt.want, tt.wrapped)
+			}
+			if timeout.Op != tt.op {
+				t.Errorf("operation = %q, want %q", timeout.Op, tt.op)
+			}
+		})
+	}
+}
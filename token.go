@@ -0,0 +1,264 @@
+package filebrowser
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpirySkew renews tokens slightly before they expire, so a request never carries
+// a token expiring in flight
+const tokenExpirySkew = 30 * time.Second
+
+// StoredToken is a login token persisted between runs, e.g. of a CLI. It never contains
+// the password.
+type StoredToken struct {
+	URL       string    `json:"url"`
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"` // Zero if the token does not expire
+}
+
+// expired reports whether the token is expired or about to expire at now
+func (t StoredToken) expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Add(tokenExpirySkew).Before(t.ExpiresAt)
+}
+
+// TokenStore persists login tokens per server and user.
+// Implementations must be safe for concurrent use.
+type TokenStore interface {
+	// Load returns the token of a user, ok is false if there is none
+	Load(url string, username string) (token StoredToken, ok bool, err error)
+	Save(token StoredToken) error
+	Delete(url string, username string) error
+}
+
+// FileTokenStore is a TokenStore backed by a JSON file readable only by its owner
+type FileTokenStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileTokenStore creates a token store persisted at the given path.
+// The file is created on the first save.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// tokenKey returns the key of a user's token in the store
+func tokenKey(url string, username string) string {
+	return strings.TrimSuffix(url, "/") + "|" + username
+}
+
+// Load implements TokenStore
+func (s *FileTokenStore) Load(url string, username string) (StoredToken, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return StoredToken{}, false, err
+	}
+	token, ok := tokens[tokenKey(url, username)]
+	return token, ok, nil
+}
+
+// Save adds or replaces the token of a user
+func (s *FileTokenStore) Save(token StoredToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	tokens[tokenKey(token.URL, token.Username)] = token
+	return s.store(tokens)
+}
+
+// Delete removes the token of a user, it is not an error if there is none
+func (s *FileTokenStore) Delete(url string, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	key := tokenKey(url, username)
+	if _, ok := tokens[key]; !ok {
+		return nil
+	}
+	delete(tokens, key)
+	return s.store(tokens)
+}
+
+// load reads all tokens from disk
+func (s *FileTokenStore) load() (map[string]StoredToken, error) {
+	tokens := map[string]StoredToken{}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode token store: %w", err)
+	}
+	return tokens, nil
+}
+
+// store writes all tokens to disk, replacing the file atomically
+func (s *FileTokenStore) store(tokens map[string]StoredToken) error {
+	if err := writeJSONFileAtomic(s.path, tokens); err != nil {
+		return fmt.Errorf("failed to write token store: %w", err)
+	}
+	return nil
+}
+
+// tokenExpiry returns the expiry of a JWT from its exp claim, the zero time if the token
+// is not a JWT or has no expiry. The signature is not verified, the server does that.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// credentials returns the password and token of the client
+func (c *Client) credentials() (password string, token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Password, c.Token
+}
+
+// token returns the token requests are authenticated with
+func (c *Client) token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Token
+}
+
+// setToken replaces the token requests are authenticated with
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Token = token
+}
+
+// authenticated reports whether the client holds a token not about to expire at now
+func (c *Client) authenticated(now time.Time) bool {
+	token := c.token()
+	return token != "" && !(StoredToken{ExpiresAt: tokenExpiry(token)}).expired(now)
+}
+
+// ensureAuthenticated ensures the client holds a valid token. An expired token is renewed
+// from the token store or by logging in again, which prompts for a missing password.
+// Concurrent callers share a single renewal.
+func (c *Client) ensureAuthenticated() error {
+	if c.authenticated(time.Now()) {
+		return nil
+	}
+	_, err, _ := c.logins.Do("authenticate", func() (any, error) { return nil, c.authenticate() })
+	return err
+}
+
+// renewRejectedToken is middleware replacing a token the server rejects with 401 before it
+// expires, e.g. a stored token revoked or signed with a rotated secret. The stored token is
+// deleted, the client logs in again once and the request is repeated with the new token
+// if its body can be sent again. Otherwise the 401 is returned, and later requests use the
+// new token.
+func (c *Client) renewRejectedToken(next Handler) Handler {
+	return func(r *http.Request) (*http.Response, error) {
+		resp, err := next(r)
+		rejected := r.Header.Get("X-Auth")
+		if err != nil || resp.StatusCode != http.StatusUnauthorized || rejected == "" || c.Tokens == nil {
+			return resp, err
+		}
+
+		if err := c.renewToken(rejected); err != nil {
+			c.log().Warn("Failed to renew rejected token", "error", err)
+			return resp, nil
+		}
+		retry := r.Clone(r.Context())
+		if r.Body != nil && r.Body != http.NoBody {
+			if r.GetBody == nil {
+				return resp, nil
+			}
+			if retry.Body, err = r.GetBody(); err != nil {
+				return resp, nil
+			}
+		}
+		resp.Body.Close()
+		retry.Header.Set("X-Auth", c.token())
+		return next(retry)
+	}
+}
+
+// renewToken deletes the stored token rejected by the server and logs in again, unless a
+// concurrent request already replaced it
+func (c *Client) renewToken(rejected string) error {
+	_, err, _ := c.logins.Do("renew", func() (any, error) {
+		if c.token() != rejected {
+			return nil, nil
+		}
+		if err := c.Tokens.Delete(c.URL, c.Username); err != nil {
+			c.log().Warn("Failed to delete rejected token", "error", err)
+		}
+		c.setToken("")
+		return nil, c.ensureAuthenticated()
+	})
+	return err
+}
+
+// authenticate renews the token for ensureAuthenticated
+func (c *Client) authenticate() error {
+	// A renewal finished just before this one started may have left a valid token
+	now := time.Now()
+	if c.authenticated(now) {
+		return nil
+	}
+
+	if c.Tokens != nil {
+		stored, ok, err := c.Tokens.Load(c.URL, c.Username)
+		if err != nil {
+			c.log().Warn("Failed to load stored token", "error", err)
+		} else if ok && !stored.expired(now) {
+			c.setToken(stored.Token)
+			return nil
+		}
+	}
+
+	if err := c.Login(); err != nil {
+		return err
+	}
+
+	if c.Tokens != nil {
+		token := c.token()
+		stored := StoredToken{URL: c.URL, Username: c.Username, Token: token, ExpiresAt: tokenExpiry(token)}
+		if err := c.Tokens.Save(stored); err != nil {
+			c.log().Warn("Failed to store token", "error", err)
+		}
+	}
+	return nil
+}
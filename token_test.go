@@ -0,0 +1,246 @@
+package filebrowser
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testJWT returns an unsigned JWT expiring at exp
+func testJWT(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix())))
+	return "header." + payload + ".signature"
+}
+
+func TestTokenStore(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("content")})
+
+	var logins atomic.Int32
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/login" {
+			logins.Add(1)
+			w.Write([]byte(testJWT(time.Now().Add(time.Hour))))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	storePath := filepath.Join(t.TempDir(), "tokens.json")
+	store := NewFileTokenStore(storePath)
+
+	// The first run prompts for the password and stores the token
+	prompts := 0
+	prompt := func(url string, username string) (string, error) {
+		prompts++
+		return "secret-password", nil
+	}
+	client, err := NewClient(server.URL, "user", "", WithTokenStore(store), WithPasswordPrompt(prompt))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := client.GetResource("a.txt"); err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if prompts != 1 || logins.Load() != 1 {
+		t.Fatalf("prompted %d times and logged in %d times, want 1 and 1", prompts, logins.Load())
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-password") {
+		t.Error("token store contains the password")
+	}
+	if info, err := os.Stat(storePath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("token store mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+
+	// A later run reuses the stored token without logging in
+	client, err = NewClient(server.URL, "user", "", WithTokenStore(store), WithPasswordPrompt(prompt))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := client.GetResource("a.txt"); err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if prompts != 1 || logins.Load() != 1 {
+		t.Errorf("prompted %d times and logged in %d times with a stored token, want 1 and 1", prompts, logins.Load())
+	}
+
+	// An expired token makes the client log in again
+	expired := StoredToken{URL: server.URL, Username: "user", Token: testJWT(time.Now().Add(-time.Minute))}
+	expired.ExpiresAt = tokenExpiry(expired.Token)
+	if err := store.Save(expired); err != nil {
+		t.Fatal(err)
+	}
+	client, err = NewClient(server.URL, "user", "", WithTokenStore(store), WithPasswordPrompt(prompt))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := client.GetResource("a.txt"); err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if prompts != 2 || logins.Load() != 2 {
+		t.Errorf("prompted %d times and logged in %d times with an expired token, want 2 and 2", prompts, logins.Load())
+	}
+	stored, ok, err := store.Load(server.URL, "user")
+	if err != nil || !ok || stored.expired(time.Now()) {
+		t.Errorf("Load() = %+v, %v, %v, want the renewed token", stored, ok, err)
+	}
+}
+
+func TestTokenStoresSharingFile(t *testing.T) {
+	dir := t.TempDir()
+	storePath := filepath.Join(dir, "tokens.json")
+
+	// Stores of different processes share the file but not their lock
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- NewFileTokenStore(storePath).Save(StoredToken{URL: "https://files.example.com", Username: fmt.Sprintf("user%d", i), Token: "token"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Save() error = %v", err)
+		}
+	}
+
+	// Concurrent processes may overwrite each other's tokens, but never corrupt the file
+	if _, _, err := NewFileTokenStore(storePath).Load("https://files.example.com", "user0"); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("files after saving = %v, want only the store", entries)
+	}
+}
+
+func TestRejectedStoredToken(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("content")})
+
+	// The server rejects the stored token, e.g. after rotating its secret
+	fresh := testJWT(time.Now().Add(time.Hour))
+	revoked := testJWT(time.Now().Add(2 * time.Hour))
+	var logins atomic.Int32
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/login" {
+			logins.Add(1)
+			w.Write([]byte(fresh))
+			return
+		}
+		if r.Header.Get("X-Auth") != fresh {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	if err := store.Save(StoredToken{URL: server.URL, Username: "user", Token: revoked}); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(server.URL, "user", "pass", WithTokenStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The request is repeated after logging in again, and the new token replaces the stored one
+	for range 2 {
+		if _, err := client.GetResource("a.txt"); err != nil {
+			t.Fatalf("GetResource() error = %v", err)
+		}
+	}
+	if logins.Load() != 1 {
+		t.Errorf("logged in %d times, want 1", logins.Load())
+	}
+	if stored, ok, err := store.Load(server.URL, "user"); err != nil || !ok || stored.Token != fresh {
+		t.Errorf("stored token = %+v, %v, %v, want the new token", stored, ok, err)
+	}
+
+	// Without a password the login fails and the 401 is returned
+	if err := store.Save(StoredToken{URL: server.URL, Username: "user", Token: revoked}); err != nil {
+		t.Fatal(err)
+	}
+	client, err = NewClient(server.URL, "user", "", WithTokenStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetResource("a.txt"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("GetResource() without a password error = %v, want ErrUnauthorized", err)
+	}
+	if _, ok, _ := store.Load(server.URL, "user"); ok {
+		t.Error("rejected token still stored")
+	}
+}
+
+func TestConcurrentLogin(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"a.txt": []byte("content")})
+
+	var logins atomic.Int32
+	next := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/login" {
+			logins.Add(1)
+			// Keep the login in flight while the other goroutines need a token
+			time.Sleep(50 * time.Millisecond)
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	localPath, _ := writeTestFile(t, 16)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetResource("a.txt")
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := client.Upload(localPath, fmt.Sprintf("upload-%d.txt", i))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent call error = %v", err)
+		}
+	}
+	if logins.Load() != 1 {
+		t.Errorf("logged in %d times, want 1", logins.Load())
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Unix(1700000000, 0)
+	if got := tokenExpiry(testJWT(exp)); !got.Equal(exp) {
+		t.Errorf("tokenExpiry(jwt) = %v, want %v", got, exp)
+	}
+	if got := tokenExpiry("test-token"); !got.IsZero() {
+		t.Errorf("tokenExpiry(opaque) = %v, want zero", got)
+	}
+}
@@ -0,0 +1,61 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// TransformFunc converts the content read from r into the content written to w, e.g. to
+// compress or watermark a file. It should stop once ctx is done.
+type TransformFunc func(ctx context.Context, r io.Reader, w io.Writer) error
+
+// TransformFile returns a Pipeline transform streaming the local file through fn into a
+// temporary file, which then replaces it as the file to upload. The expected FileSize is
+// set to the size of the result, so the check stage compares the remote file against it.
+// The downloaded file is kept for later runs, temporary files are removed.
+func TransformFile(fn TransformFunc) Stage {
+	return func(state *PipelineState) error {
+		if state.stream != nil {
+			return fmt.Errorf("cannot transform a streamed download, see StreamStage")
+		}
+		in, err := os.Open(state.LocalPath)
+		if err != nil {
+			return fmt.Errorf("failed to open file to transform: %w", err)
+		}
+		defer in.Close()
+
+		info, err := in.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat file to transform: %w", err)
+		}
+		out, err := os.CreateTemp(filepath.Dir(state.LocalPath), "transform-*"+filepath.Ext(state.LocalPath))
+		if err != nil {
+			return fmt.Errorf("failed to create transformed file: %w", err)
+		}
+
+		var r io.Reader = in
+		if progress := state.progress(StageTransform); progress != nil {
+			r = NewProgressReader(in, info.Size(), progress)
+		}
+		err = fn(state.Context, r, out)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(out.Name())
+			return fmt.Errorf("failed to transform file: %w", err)
+		}
+
+		size := localFileSize(out.Name())
+		in.Close()
+		if state.transformed != "" && state.transformed == state.LocalPath {
+			os.Remove(state.transformed)
+		}
+		state.LocalPath, state.transformed = out.Name(), out.Name()
+		state.Params.FileSize = size
+		return nil
+	}
+}
@@ -0,0 +1,148 @@
+package filebrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// TreeNode is a file or directory of a remote tree built by BuildTree
+type TreeNode struct {
+	Name     string      `json:"name"`
+	Path     string      `json:"path"`
+	Size     int64       `json:"size"`
+	IsDir    bool        `json:"is_dir"`
+	Children []*TreeNode `json:"children,omitempty"`
+	// Truncated marks directories below the depth limit, whose contents were not listed
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// BuildTree lists the remote tree rooted at root down to depth levels below it, all of
+// them if depth is not positive. Children are sorted by name.
+func (c *Client) BuildTree(root string, depth int) (_ *TreeNode, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	listing, err := c.lookupResource(resourcePath(root))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", root, err)
+	}
+	if listing == nil {
+		return nil, fmt.Errorf("cannot list tree of %s: %w", root, ErrNotFound)
+	}
+
+	node := newTreeNode(listing)
+	node.Name = path.Clean("/" + resourcePath(root))
+	if node.IsDir {
+		if err := c.fillTree(node, listing, depth-1); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// fillTree adds the items of listing to node, listing subdirectories down to depth more
+// levels, unlimited if depth is negative
+func (c *Client) fillTree(node *TreeNode, listing *RespResource, depth int) error {
+	for i := range listing.Items {
+		child := newTreeNode(&listing.Items[i])
+		node.Children = append(node.Children, child)
+		if !child.IsDir {
+			continue
+		}
+		if depth == 0 {
+			child.Truncated = true
+			continue
+		}
+
+		sub, err := c.lookupResource(resourcePath(child.Path))
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", child.Path, err)
+		}
+		if sub == nil {
+			// Removed while the tree is built
+			continue
+		}
+		if err := c.fillTree(child, sub, depth-1); err != nil {
+			return err
+		}
+	}
+	sort.Slice(node.Children, func(i, j int) bool { return node.Children[i].Name < node.Children[j].Name })
+	return nil
+}
+
+// newTreeNode creates the node of a resource without its children
+func newTreeNode(resource *RespResource) *TreeNode {
+	return &TreeNode{
+		Name:  resource.Name,
+		Path:  "/" + resourcePath(resource.Path),
+		Size:  resource.Size,
+		IsDir: resource.IsDir,
+	}
+}
+
+// Tree writes the remote tree rooted at root like the tree command, down to depth levels
+// below it or all of them if depth is not positive
+func (c *Client) Tree(root string, depth int, w io.Writer) error {
+	node, err := c.BuildTree(root, depth)
+	if err != nil {
+		return err
+	}
+	return node.WriteText(w, false)
+}
+
+// TreeJSON writes the remote tree rooted at root as indented JSON of TreeNodes, e.g. for
+// reports
+func (c *Client) TreeJSON(root string, depth int, w io.Writer) error {
+	node, err := c.BuildTree(root, depth)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(node)
+}
+
+// treeGlyphs are the branches drawn by WriteText, Unicode and ASCII
+var treeGlyphs = map[bool][5]string{
+	false: {"├── ", "└── ", "│   ", "    ", "/…"},
+	true:  {"|-- ", "`-- ", "|   ", "    ", "/..."},
+}
+
+// WriteText draws the tree like the tree command, followed by the number of directories
+// and files, with ASCII instead of Unicode characters if ascii is set. Truncated
+// directories are marked with an ellipsis.
+func (n *TreeNode) WriteText(w io.Writer, ascii bool) error {
+	var b strings.Builder
+	b.WriteString(n.Name + "\n")
+	dirs, files := n.writeChildren(&b, "", treeGlyphs[ascii])
+	fmt.Fprintf(&b, "\n%d directories, %d files\n", dirs, files)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeChildren draws the children of n below prefix and counts the directories and files
+func (n *TreeNode) writeChildren(b *strings.Builder, prefix string, glyphs [5]string) (dirs int, files int) {
+	for i, child := range n.Children {
+		branch, indent := glyphs[0], glyphs[2]
+		if i == len(n.Children)-1 {
+			branch, indent = glyphs[1], glyphs[3]
+		}
+		b.WriteString(prefix + branch + child.Name)
+		if child.Truncated {
+			b.WriteString(glyphs[4])
+		}
+		b.WriteString("\n")
+
+		if !child.IsDir {
+			files++
+			continue
+		}
+		subDirs, subFiles := child.writeChildren(b, prefix+indent, glyphs)
+		dirs, files = dirs+1+subDirs, files+subFiles
+	}
+	return dirs, files
+}
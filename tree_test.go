@@ -0,0 +1,64 @@
+package filebrowser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"docs/b.txt":        []byte("bb"),
+		"docs/a/one.txt":    []byte("1"),
+		"docs/a/deep/x.txt": []byte("x"),
+		"docs/c/two.txt":    []byte("22"),
+		"other/ignored.txt": []byte("-"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	var out bytes.Buffer
+	if err := client.Tree("docs", 0, &out); err != nil {
+		t.Fatalf("Tree() error = %v", err)
+	}
+	want := `/docs
+├── a
+│   ├── deep
+│   │   └── x.txt
+│   └── one.txt
+├── b.txt
+└── c
+    └── two.txt
+
+3 directories, 4 files
+`
+	if out.String() != want {
+		t.Errorf("Tree() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// Limited depth marks the directories not listed
+	node, err := client.BuildTree("docs", 1)
+	if err != nil {
+		t.Fatalf("BuildTree() error = %v", err)
+	}
+	out.Reset()
+	node.WriteText(&out, true)
+	want = "/docs\n|-- a/...\n|-- b.txt\n`-- c/...\n\n2 directories, 1 files\n"
+	if out.String() != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := client.TreeJSON("docs/c", 0, &out); err != nil {
+		t.Fatalf("TreeJSON() error = %v", err)
+	}
+	var decoded TreeNode
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || !decoded.IsDir || len(decoded.Children) != 1 ||
+		decoded.Children[0].Path != "/docs/c/two.txt" || decoded.Children[0].Size != 2 {
+		t.Errorf("TreeJSON() = %s, %v", out.String(), err)
+	}
+
+	if err := client.Tree("missing", 0, &out); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Tree() of missing root error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,432 @@
+package filebrowser
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eventials/go-tus"
+)
+
+// TUS extensions the SDK makes use of
+const (
+	tusExtensionCreation      = "creation"
+	tusExtensionChecksum      = "checksum"
+	tusExtensionConcatenation = "concatenation"
+	tusExtensionDeferLength   = "creation-defer-length"
+	tusExtensionTermination   = "termination"
+)
+
+// checksumPreference are the chunk checksum algorithms used, most preferred first. sha1 is
+// mandatory for servers implementing the checksum extension.
+var checksumPreference = []string{"sha1", "sha256", "md5"}
+
+// statusChecksumMismatch is the TUS specific status code returned when a chunk fails verification
+const statusChecksumMismatch = 460
+
+// ErrChecksumMismatch is matched by errors.Is for every ChecksumMismatchError
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ChecksumMismatchError reports a chunk rejected by the server's checksum verification
+type ChecksumMismatchError struct {
+	Offset    int64  // Offset of the first byte of the failing chunk
+	Algorithm string // Checksum algorithm used for the chunk
+}
+
+// Error implements the error interface
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("chunk checksum mismatch at offset %d (%s)", e.Offset, e.Algorithm)
+}
+
+// Is reports whether target is ErrChecksumMismatch
+func (e *ChecksumMismatchError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
+
+// tusServerInfo describes what a TUS endpoint advertises in its OPTIONS response
+type tusServerInfo struct {
+	Extensions         []string
+	ChecksumAlgorithms []string
+}
+
+// supports checks if the server advertises the given extension
+func (i *tusServerInfo) supports(extension string) bool {
+	for _, e := range i.Extensions {
+		if e == extension {
+			return true
+		}
+	}
+	return false
+}
+
+// checksumAlgorithm picks the checksum algorithm to use for chunks, or "" if none is usable
+func (i *tusServerInfo) checksumAlgorithm() string {
+	if !i.supports(tusExtensionChecksum) {
+		return ""
+	}
+	for _, preferred := range checksumPreference {
+		for _, algo := range i.ChecksumAlgorithms {
+			if algo == preferred {
+				return algo
+			}
+		}
+	}
+	return ""
+}
+
+// discoverTus queries the TUS endpoint for its supported extensions.
+// Servers that don't answer OPTIONS are treated as supporting the core protocol only.
+func discoverTus(tusClient *tus.Client, log Logger) *tusServerInfo {
+	info := &tusServerInfo{}
+
+	request, err := http.NewRequest(http.MethodOptions, tusClient.Url, nil)
+	if err != nil {
+		return info
+	}
+
+	resp, err := tusClient.Do(request)
+	if err != nil {
+		log.Warn("TUS capability discovery failed", "error", err)
+		return info
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return info
+	}
+
+	info.Extensions = splitHeaderList(resp.Header.Get("Tus-Extension"))
+	info.ChecksumAlgorithms = splitHeaderList(resp.Header.Get("Tus-Checksum-Algorithm"))
+	return info
+}
+
+// splitHeaderList splits a comma separated header value
+func splitHeaderList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newChecksumHash creates the hash for a checksum algorithm name
+func newChecksumHash(algo string) (hash.Hash, error) {
+	switch algo {
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	case "md5":
+		return md5.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm: %s", algo)
+	}
+}
+
+// chunkChecksum computes the base64 encoded checksum of the chunk starting at offset
+func chunkChecksum(r io.ReaderAt, offset int64, size int64, algo string) (string, error) {
+	h, err := newChecksumHash(algo)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, io.NewSectionReader(r, offset, size)); err != nil {
+		return "", fmt.Errorf("failed to read chunk at offset %d: %w", offset, err)
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// uploadChunks uploads the remaining chunks one by one, reporting each to progress. When
+// algo is set, every chunk carries an Upload-Checksum header and a verification failure is
+// reported as a ChecksumMismatchError.
+func uploadChunks(uploader *tus.Uploader, config *tus.Config, source io.ReaderAt, size int64, algo string, progress *transferProgress, retry retrier) error {
+	defer config.Header.Del("Upload-Checksum")
+
+	for uploader.Offset() < size {
+		offset := uploader.Offset()
+
+		if algo != "" {
+			checksum, err := chunkChecksum(source, offset, config.ChunkSize, algo)
+			if err != nil {
+				return err
+			}
+			config.Header.Set("Upload-Checksum", fmt.Sprintf("%s %s", algo, checksum))
+		}
+
+		if err := retry.tus(uploader.UploadChunck); err != nil {
+			var clientErr tus.ClientError
+			if errors.As(err, &clientErr) && clientErr.Code == statusChecksumMismatch {
+				return &ChecksumMismatchError{Offset: offset, Algorithm: algo}
+			}
+			return err
+		}
+		progress.add(uploader.Offset() - offset)
+	}
+
+	return nil
+}
+
+// uploadConcatenated splits the source into parts uploaded concurrently as partial
+// uploads, then asks the server to concatenate them into the final upload at endpoint.
+// newConfig must return a fresh configuration for every call as each part mutates its headers.
+// It returns the URL of the final upload.
+func uploadConcatenated(endpoint string, newConfig func() *tus.Config, source io.ReaderAt, size int64, parts int, algo string, track sessionTracker, progress *transferProgress, retry retrier) (string, error) {
+	partSize := (size + int64(parts) - 1) / int64(parts)
+	parts = int((size + partSize - 1) / partSize)
+
+	urls := make([]string, parts)
+	errs := make([]error, parts)
+	untracks := make([]func(), parts)
+
+	var wg sync.WaitGroup
+	for i := 0; i < parts; i++ {
+		offset := int64(i) * partSize
+		section := io.NewSectionReader(source, offset, min(partSize, size-offset))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			urls[i], untracks[i], errs[i] = uploadPartial(endpoint, newConfig(), section, offset, algo, track, progress, retry)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return "", err
+	}
+
+	finalURL, err := finishConcatenation(endpoint, newConfig(), urls)
+	if err != nil {
+		return "", err
+	}
+
+	// The parts are consumed by the final upload
+	for _, untrack := range untracks {
+		untrack()
+	}
+	return finalURL, nil
+}
+
+// uploadPartial uploads one part as a partial upload and returns its upload URL
+// together with the function forgetting its tracked session
+func uploadPartial(endpoint string, config *tus.Config, section *io.SectionReader, offset int64, algo string, track sessionTracker, progress *transferProgress, retry retrier) (string, func(), error) {
+	config.Header.Set("Upload-Concat", "partial")
+	tusClient, err := tus.NewClient(endpoint, config)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create TUS client: %w", err)
+	}
+
+	var uploader *tus.Uploader
+	err = retry.tus(func() (err error) {
+		uploader, err = tusClient.CreateUpload(tus.NewUpload(section, section.Size(), nil, ""))
+		return err
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create partial upload at offset %d: %w", offset, err)
+	}
+	config.Header.Del("Upload-Concat")
+	untrack := track(uploader.Url())
+
+	if err := uploadChunks(uploader, config, section, section.Size(), algo, progress, retry); err != nil {
+		var mismatch *ChecksumMismatchError
+		if errors.As(err, &mismatch) {
+			mismatch.Offset += offset
+		}
+		return "", nil, fmt.Errorf("failed to upload part at offset %d: %w", offset, err)
+	}
+
+	return uploader.Url(), untrack, nil
+}
+
+// finishConcatenation creates the final upload from the given partial upload URLs and
+// returns its URL, empty if the server did not report one
+func finishConcatenation(endpoint string, config *tus.Config, urls []string) (string, error) {
+	tusClient, err := tus.NewClient(endpoint, config)
+	if err != nil {
+		return "", fmt.Errorf("failed to create TUS client: %w", err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Upload-Concat", "final;"+strings.Join(urls, " "))
+
+	resp, err := tusClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("concatenation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("concatenation failed with %w", httpAPIError(resp))
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", nil
+	}
+	finalURL, err := request.URL.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid upload location: %w", err)
+	}
+	return finalURL.String(), nil
+}
+
+// uploadDeferred uploads a stream of unknown length using the TUS creation-defer-length
+// extension. The total length is declared together with the last chunk, so the stream
+// never needs to be buffered beyond a single chunk. Returns the number of bytes uploaded.
+func uploadDeferred(tusClient *tus.Client, r io.Reader, algo string, track sessionTracker, progress *transferProgress) (int64, error) {
+	request, err := http.NewRequest(http.MethodPost, tusClient.Url, nil)
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Length", "0")
+	request.Header.Set("Upload-Defer-Length", "1")
+
+	resp, err := tusClient.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create upload: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return 0, fmt.Errorf("failed to create upload with %w", httpAPIError(resp))
+	}
+
+	location, err := request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid upload location: %w", err)
+	}
+	untrack := track(location.String())
+
+	reader := bufio.NewReader(r)
+	chunk := make([]byte, tusClient.Config.ChunkSize)
+	var offset int64
+	for {
+		n, err := io.ReadFull(reader, chunk)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return offset, fmt.Errorf("failed to read stream: %w", err)
+		}
+
+		// The stream ends with this chunk if it is short or nothing follows it
+		last := err != nil
+		if !last {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				last = true
+			}
+		}
+
+		var length int64 = -1
+		if last {
+			length = offset + int64(n)
+		}
+		if err := patchChunk(tusClient, location.String(), chunk[:n], offset, length, algo); err != nil {
+			return offset, err
+		}
+		offset += int64(n)
+		progress.add(int64(n))
+
+		if last {
+			untrack()
+			return offset, nil
+		}
+	}
+}
+
+// uploadSized creates an upload of a known length at the TUS client's endpoint and sends
+// exactly size bytes read from r in chunks, so r does not need to be seekable
+func uploadSized(tusClient *tus.Client, r io.Reader, size int64, algo string, track sessionTracker, progress *transferProgress) error {
+	request, err := http.NewRequest(http.MethodPost, tusClient.Url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Length", "0")
+	request.Header.Set("Upload-Length", strconv.FormatInt(size, 10))
+
+	resp, err := tusClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to create upload: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to create upload with %w", httpAPIError(resp))
+	}
+
+	location, err := request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid upload location: %w", err)
+	}
+	untrack := track(location.String())
+
+	chunk := make([]byte, min(tusClient.Config.ChunkSize, max(size, 1)))
+	var offset int64
+	for offset < size {
+		n, err := io.ReadFull(r, chunk[:min(int64(len(chunk)), size-offset)])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("stream ended after %d of %d bytes", offset+int64(n), size)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read stream: %w", err)
+		}
+
+		if err := patchChunk(tusClient, location.String(), chunk[:n], offset, -1, algo); err != nil {
+			return err
+		}
+		offset += int64(n)
+		progress.add(int64(n))
+	}
+
+	untrack()
+	return nil
+}
+
+// patchChunk sends a single chunk at offset. A non-negative length is declared as the
+// final upload length.
+func patchChunk(tusClient *tus.Client, uploadURL string, data []byte, offset int64, length int64, algo string) error {
+	request, err := http.NewRequest(http.MethodPatch, uploadURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/offset+octet-stream")
+	request.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	if length >= 0 {
+		request.Header.Set("Upload-Length", strconv.FormatInt(length, 10))
+	}
+	if algo != "" {
+		checksum, err := chunkChecksum(bytes.NewReader(data), 0, int64(len(data)), algo)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Upload-Checksum", fmt.Sprintf("%s %s", algo, checksum))
+	}
+
+	resp, err := tusClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("chunk request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case statusChecksumMismatch:
+		return &ChecksumMismatchError{Offset: offset, Algorithm: algo}
+	default:
+		return fmt.Errorf("chunk at offset %d failed with %w", offset, httpAPIError(resp))
+	}
+}
@@ -0,0 +1,408 @@
+package filebrowser
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// tusTestServer is a minimal TUS server supporting the checksum extension
+type tusTestServer struct {
+	mu          sync.Mutex
+	data        bytes.Buffer
+	checksums   int
+	corruptFrom int64 // reject chunks at or after this offset, -1 to accept all
+	deleted     []string
+}
+
+func (s *tusTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/api/login" {
+		w.Write([]byte("test-token"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Tus-Extension", "creation,checksum")
+		w.Header().Set("Tus-Checksum-Algorithm", "md5,sha1")
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		w.Header().Set("Location", r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPatch:
+		offset, _ := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+		body, _ := io.ReadAll(r.Body)
+
+		algo, checksum, _ := strings.Cut(r.Header.Get("Upload-Checksum"), " ")
+		if algo != "" {
+			s.checksums++
+			sum := sha1.Sum(body)
+			if algo != "sha1" || checksum != base64.StdEncoding.EncodeToString(sum[:]) {
+				w.WriteHeader(statusChecksumMismatch)
+				return
+			}
+		}
+		if s.corruptFrom >= 0 && offset >= s.corruptFrom {
+			w.WriteHeader(statusChecksumMismatch)
+			return
+		}
+
+		s.data.Write(body)
+		w.Header().Set("Upload-Offset", strconv.Itoa(s.data.Len()))
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodHead:
+		w.Header().Set("Upload-Offset", strconv.Itoa(s.data.Len()))
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		s.deleted = append(s.deleted, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeTestFile(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), size/16+1)[:size]
+	localPath := filepath.Join(t.TempDir(), "upload.bin")
+	if err := os.WriteFile(localPath, content, 0o644); err != nil {
+		t.Fatalf("Failed to write local file: %v", err)
+	}
+	return localPath, content
+}
+
+func TestUploadWithChecksums(t *testing.T) {
+	// Larger than the default 2MB chunk size to exercise multiple chunks
+	localPath, content := writeTestFile(t, 5*1024*1024)
+
+	tusServer := &tusTestServer{corruptFrom: -1}
+	server := httptest.NewServer(tusServer)
+	defer server.Close()
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	result, err := client.UploadWithOptions(localPath, "dir/upload.bin", UploadOptions{Checksum: "sha256"})
+	if err != nil {
+		t.Fatalf("UploadWithOptions() error = %v", err)
+	}
+
+	if !bytes.Equal(tusServer.data.Bytes(), content) {
+		t.Error("uploaded content does not match local file")
+	}
+	if want := sha256Hex(content); result.Checksum != want || result.Bytes != int64(len(content)) {
+		t.Errorf("UploadResult = %+v, want checksum %v and %d bytes", result, want, len(content))
+	}
+	if want := server.URL + "/api/tus/dir/upload.bin"; result.TusURL != want || result.Duration <= 0 {
+		t.Errorf("UploadResult = %+v, want TUS URL %v and a duration", result, want)
+	}
+	if tusServer.checksums != 3 {
+		t.Errorf("server verified %d chunks, want 3", tusServer.checksums)
+	}
+}
+
+func TestUploadChecksumMismatch(t *testing.T) {
+	localPath, _ := writeTestFile(t, 5*1024*1024)
+
+	tusServer := &tusTestServer{corruptFrom: 2 * 1024 * 1024}
+	server := httptest.NewServer(tusServer)
+	defer server.Close()
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	_, err := client.Upload(localPath, "dir/upload.bin")
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Upload() error = %v, want ErrChecksumMismatch", err)
+	}
+
+	var mismatch *ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Upload() error = %v, want *ChecksumMismatchError", err)
+	}
+	if mismatch.Offset != 2*1024*1024 || mismatch.Algorithm != "sha1" {
+		t.Errorf("ChecksumMismatchError = %+v, want offset %d with sha1", mismatch, 2*1024*1024)
+	}
+}
+
+// concatTestServer is a minimal TUS server supporting the concatenation extension
+type concatTestServer struct {
+	mu      sync.Mutex
+	uploads map[string]*bytes.Buffer
+	final   []byte
+}
+
+func (s *concatTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/api/login" {
+		w.Write([]byte("test-token"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Tus-Extension", "creation,concatenation")
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		concat := r.Header.Get("Upload-Concat")
+		if urls, ok := strings.CutPrefix(concat, "final;"); ok {
+			for _, u := range strings.Fields(urls) {
+				partURL, _ := url.Parse(u)
+				s.final = append(s.final, s.uploads[partURL.Path].Bytes()...)
+			}
+			w.Header().Set("Location", "/uploads/final")
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		if concat != "partial" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		location := "/uploads/" + strconv.Itoa(len(s.uploads))
+		s.uploads[location] = &bytes.Buffer{}
+		w.Header().Set("Location", location)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPatch:
+		buf := s.uploads[r.URL.Path]
+		io.Copy(buf, r.Body)
+		w.Header().Set("Upload-Offset", strconv.Itoa(buf.Len()))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestUploadParallelParts(t *testing.T) {
+	localPath, content := writeTestFile(t, 3*1024*1024+7)
+
+	concatServer := &concatTestServer{uploads: map[string]*bytes.Buffer{}}
+	server := httptest.NewServer(concatServer)
+	defer server.Close()
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	result, err := client.UploadWithOptions(localPath, "dir/upload.bin", UploadOptions{ParallelParts: 4, Checksum: "sha256"})
+	if err != nil {
+		t.Fatalf("UploadWithOptions() error = %v", err)
+	}
+	if want := sha256Hex(content); result.Checksum != want {
+		t.Errorf("Checksum = %v, want %v", result.Checksum, want)
+	}
+	if want := server.URL + "/uploads/final"; result.TusURL != want {
+		t.Errorf("TusURL = %v, want %v", result.TusURL, want)
+	}
+
+	if len(concatServer.uploads) != 4 {
+		t.Errorf("server received %d partial uploads, want 4", len(concatServer.uploads))
+	}
+	if !bytes.Equal(concatServer.final, content) {
+		t.Error("concatenated content does not match local file")
+	}
+}
+
+// deferTestServer is a minimal TUS server supporting the creation-defer-length extension
+type deferTestServer struct {
+	mu     sync.Mutex
+	data   bytes.Buffer
+	length string
+}
+
+func (s *deferTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/api/login" {
+		w.Write([]byte("test-token"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Tus-Extension", "creation,creation-defer-length")
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		if r.Header.Get("Upload-Defer-Length") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Location", "/uploads/1")
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPatch:
+		if s.length != "" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.length = r.Header.Get("Upload-Length")
+		io.Copy(&s.data, r.Body)
+		w.Header().Set("Upload-Offset", strconv.Itoa(s.data.Len()))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestUploadStream(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "Empty stream", size: 0},
+		{name: "Partial last chunk", size: 3*1024*1024 + 5},
+		{name: "Exact chunk multiple", size: 4 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := bytes.Repeat([]byte("x"), tt.size)
+
+			deferServer := &deferTestServer{}
+			server := httptest.NewServer(deferServer)
+			defer server.Close()
+
+			client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+			// Hide the length from the SDK by wrapping the reader
+			stream := io.MultiReader(bytes.NewReader(content))
+			if err := client.UploadStream(stream, "dir/stream.bin"); err != nil {
+				t.Fatalf("UploadStream() error = %v", err)
+			}
+
+			if !bytes.Equal(deferServer.data.Bytes(), content) {
+				t.Error("uploaded content does not match stream")
+			}
+			if deferServer.length != strconv.Itoa(tt.size) {
+				t.Errorf("declared length = %q, want %d", deferServer.length, tt.size)
+			}
+		})
+	}
+}
+
+func TestUploadStreamSpoolsWithoutDeferLength(t *testing.T) {
+	content := bytes.Repeat([]byte("y"), 1024)
+
+	tusServer := &tusTestServer{corruptFrom: -1}
+	server := httptest.NewServer(tusServer)
+	defer server.Close()
+
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	if err := client.UploadStream(bytes.NewReader(content), "dir/stream.bin"); err != nil {
+		t.Fatalf("UploadStream() error = %v", err)
+	}
+
+	if !bytes.Equal(tusServer.data.Bytes(), content) {
+		t.Error("uploaded content does not match stream")
+	}
+}
+
+func TestUploadReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		send    int
+		wantErr bool
+	}{
+		{name: "Empty reader", size: 0, send: 0},
+		{name: "Multiple chunks", size: 5*1024*1024 + 3, send: 5*1024*1024 + 3},
+		{name: "Trailing data ignored", size: 10, send: 20},
+		{name: "Short reader", size: 3 * 1024 * 1024, send: 1024, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := bytes.Repeat([]byte("0123456789abcdef"), tt.send/16+1)[:tt.send]
+
+			tusServer := &tusTestServer{corruptFrom: -1}
+			server := httptest.NewServer(tusServer)
+			defer server.Close()
+
+			client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+			err := client.UploadReader(io.MultiReader(bytes.NewReader(content)), int64(tt.size), "dir/reader.bin")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UploadReader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !bytes.Equal(tusServer.data.Bytes(), content[:tt.size]) {
+				t.Error("uploaded content does not match reader")
+			}
+			if tt.size > 0 && tusServer.checksums == 0 {
+				t.Error("chunks were not verified")
+			}
+		})
+	}
+}
+
+func TestUploadProgress(t *testing.T) {
+	localPath, content := writeTestFile(t, 5*1024*1024)
+	size := int64(len(content))
+
+	t.Run("Sequential", func(t *testing.T) {
+		server := httptest.NewServer(&tusTestServer{corruptFrom: -1})
+		defer server.Close()
+
+		var reports []int64
+		client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+		_, err := client.UploadWithOptions(localPath, "dir/upload.bin", UploadOptions{
+			Progress: func(done, total int64) {
+				if total != size {
+					t.Errorf("progress total = %d, want %d", total, size)
+				}
+				reports = append(reports, done)
+			},
+		})
+		if err != nil {
+			t.Fatalf("UploadWithOptions() error = %v", err)
+		}
+
+		if len(reports) != 3 || reports[2] != size {
+			t.Errorf("progress reports = %v, want 3 ending at %d", reports, size)
+		}
+	})
+
+	t.Run("Parallel", func(t *testing.T) {
+		server := httptest.NewServer(&concatTestServer{uploads: map[string]*bytes.Buffer{}})
+		defer server.Close()
+
+		var mu sync.Mutex
+		var last int64
+		client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+		_, err := client.UploadWithOptions(localPath, "dir/upload.bin", UploadOptions{
+			ParallelParts: 4,
+			Progress: func(done, total int64) {
+				mu.Lock()
+				defer mu.Unlock()
+				if done < last {
+					t.Errorf("progress went backwards from %d to %d", last, done)
+				}
+				last = done
+			},
+		})
+		if err != nil {
+			t.Fatalf("UploadWithOptions() error = %v", err)
+		}
+
+		if last != size {
+			t.Errorf("final progress = %d, want %d", last, size)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package filebrowser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+)
+
+// UpdateOptions contains the changes UpdateResource makes to a resource. Unset fields are
+// left alone.
+type UpdateOptions struct {
+	// Content, if set, replaces the content of the file in a single request, e.g. a small
+	// text file edited in place. Large files should be uploaded instead.
+	Content io.Reader
+	// Mode, if set, becomes the permission bits of the resource, see Chmod
+	Mode fs.FileMode
+}
+
+// UpdateResource modifies the existing resource at remotePath in the ways Filebrowser
+// allows, replacing the content of a file and setting its mode. A missing resource fails
+// with an error matching ErrNotFound.
+func (c *Client) UpdateResource(remotePath string, opts UpdateOptions) (err error) {
+	var written int64
+	_, start := c.startOp(context.Background(), OpUpdateResource, remotePath)
+	defer func() {
+		err = c.finishOp(OpUpdateResource, remotePath, written, start, err)
+		c.postOp(OpResult{Operation: OpUpdateResource, Path: remotePath, Bytes: written, Err: err}, start.at)
+	}()
+
+	if remotePath == "" {
+		return fmt.Errorf("remote path cannot be empty")
+	}
+
+	if err := c.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	if opts.Content != nil {
+		content, err := io.ReadAll(opts.Content)
+		if err != nil {
+			return fmt.Errorf("failed to read content: %w", err)
+		}
+		if err := c.putContent(remotePath, content); err != nil {
+			return err
+		}
+		written = int64(len(content))
+	}
+
+	if opts.Mode != 0 {
+		if err := c.chmod(remotePath, opts.Mode); err != nil {
+			return err
+		}
+	}
+
+	c.log().Info("Updated resource", "path", remotePath)
+	return nil
+}
+
+// putContent replaces the content of the existing file at remotePath
+func (c *Client) putContent(remotePath string, content []byte) error {
+	url, err := c.apiURL("resources", remotePath)
+	if err != nil {
+		return err
+	}
+	resp, err := c.newRequest(context.Background()).SetBodyBytes(content).Put(url)
+	if err != nil {
+		return fmt.Errorf("update request failed: %w", err)
+	}
+
+	if err := checkDenied(resp, remotePath); err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("cannot update %s: %w", remotePath, newAPIError(resp))
+	default:
+		return fmt.Errorf("update request failed with %w", newAPIError(resp))
+	}
+}
@@ -0,0 +1,35 @@
+package filebrowser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUpdateResource(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{"conf/app.ini": []byte("debug=false\n")})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	err := client.UpdateResource("conf/app.ini", UpdateOptions{Content: strings.NewReader("debug=true\n"), Mode: 0o640})
+	if err != nil {
+		t.Fatalf("UpdateResource() error = %v", err)
+	}
+	if content, _ := server.file("conf/app.ini"); string(content) != "debug=true\n" {
+		t.Errorf("content = %q, want it replaced", content)
+	}
+
+	resource, err := client.GetResource("conf/app.ini")
+	if err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if resource.Mode.Perm() != 0o640 {
+		t.Errorf("Mode = %v, want -rw-r-----", resource.Mode)
+	}
+
+	if err := client.UpdateResource("conf/missing.ini", UpdateOptions{Content: strings.NewReader("x")}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateResource() of missing file error = %v, want ErrNotFound", err)
+	}
+	if _, ok := server.file("conf/missing.ini"); ok {
+		t.Error("UpdateResource() created a missing file")
+	}
+}
@@ -0,0 +1,135 @@
+package filebrowser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// UploadFSOptions contains optional parameters for UploadFSWithOptions
+type UploadFSOptions struct {
+	// Filter, if set, limits the files uploaded
+	Filter Filter
+}
+
+// UploadFS uploads the files below root in fsys to remoteDir, keeping their relative paths,
+// e.g. to publish assets embedded with go:embed on startup. Remote files with the content
+// of their source are left alone and others are replaced, so repeated calls only upload
+// what changed. It returns the remote paths of the uploaded files.
+func (c *Client) UploadFS(fsys fs.FS, root string, remoteDir string) ([]string, error) {
+	return c.UploadFSWithOptions(fsys, root, remoteDir, UploadFSOptions{})
+}
+
+// UploadFSWithOptions uploads the files of fsys like UploadFS, applying the given options
+func (c *Client) UploadFSWithOptions(fsys fs.FS, root string, remoteDir string, opts UploadFSOptions) ([]string, error) {
+	if fsys == nil {
+		return nil, fmt.Errorf("file system cannot be nil")
+	}
+	if root == "" {
+		root = "."
+	}
+
+	var uploaded []string
+	err := fs.WalkDir(fsys, root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel := path.Base(name)
+		if name != root {
+			rel = strings.TrimPrefix(name, root+"/")
+			if root == "." {
+				rel = name
+			}
+		}
+		if opts.Filter != nil {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if !opts.Filter.Match(FilterEntry{Path: rel, Size: info.Size(), Modified: info.ModTime()}) {
+				return nil
+			}
+		}
+		remotePath := path.Join(remoteDir, rel)
+
+		changed, err := c.uploadFSFile(fsys, name, remotePath)
+		if err != nil {
+			return fmt.Errorf("failed to upload %s: %w", name, err)
+		}
+		if changed {
+			uploaded = append(uploaded, remotePath)
+		}
+		return nil
+	})
+	return uploaded, err
+}
+
+// uploadFSFile uploads the file name of fsys to remotePath unless the remote file already
+// has its content, and reports whether it uploaded
+func (c *Client) uploadFSFile(fsys fs.FS, name string, remotePath string) (bool, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() {
+		return false, nil
+	}
+
+	resource, err := c.lookupResource(remotePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to get resource info: %w", err)
+	}
+	if resource != nil {
+		if resource.Size == info.Size() {
+			localSum, err := fsSHA256(fsys, name)
+			if err != nil {
+				return false, err
+			}
+			remoteSum, err := sha256Of(c, remotePath)
+			if err != nil {
+				return false, fmt.Errorf("failed to get remote checksum: %w", err)
+			}
+			if localSum == remoteSum {
+				c.log().Debug("Remote file unchanged, skipping upload", "path", remotePath)
+				return false, nil
+			}
+		}
+		if err := c.DeleteResource(remotePath); err != nil {
+			return false, fmt.Errorf("failed to delete existing resource: %w", err)
+		}
+	}
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	if err := c.UploadReader(file, info.Size(), remotePath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// fsSHA256 returns the hex encoded SHA-256 checksum of the file name of fsys
+func fsSHA256(fsys fs.FS, name string) (string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", name, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
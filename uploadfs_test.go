@@ -0,0 +1,44 @@
+package filebrowser
+
+import (
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUploadFS(t *testing.T) {
+	server := newMemServer(t, map[string][]byte{
+		"site/index.html":  []byte("<h1>old</h1>"),
+		"site/css/app.css": []byte("body{}"),
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	assets := fstest.MapFS{
+		"web/index.html":   {Data: []byte("<h1>new</h1>")},
+		"web/css/app.css":  {Data: []byte("body{}")},
+		"web/js/app.js":    {Data: []byte("main()")},
+		"other/readme.txt": {Data: []byte("not published")},
+	}
+
+	uploaded, err := client.UploadFS(assets, "web", "site")
+	if err != nil {
+		t.Fatalf("UploadFS() error = %v", err)
+	}
+	sort.Strings(uploaded)
+	if len(uploaded) != 2 || uploaded[0] != "site/index.html" || uploaded[1] != "site/js/app.js" {
+		t.Errorf("UploadFS() uploaded %v, want the changed index.html and the new app.js", uploaded)
+	}
+	for name, want := range map[string]string{"site/index.html": "<h1>new</h1>", "site/css/app.css": "body{}", "site/js/app.js": "main()"} {
+		if got, _ := server.file(name); string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if _, ok := server.file("site/readme.txt"); ok {
+		t.Error("file outside the root was uploaded")
+	}
+
+	// Nothing changed since the last call
+	if uploaded, err := client.UploadFS(assets, "web", "site"); err != nil || len(uploaded) != 0 {
+		t.Errorf("UploadFS() again = %v, %v, want nothing uploaded", uploaded, err)
+	}
+}
@@ -0,0 +1,125 @@
+package filebrowser
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/imroc/req/v3"
+)
+
+// defaultMaxRedirects is the number of redirects followed by a URLPolicy without a limit
+const defaultMaxRedirects = 5
+
+// ErrURLNotAllowed is returned when a URL or an address it resolves to is refused by a
+// URLPolicy
+var ErrURLNotAllowed = errors.New("URL not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range, private although netip does not
+// report it so
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// URLPolicy restricts the external URLs a download may fetch, for services passing
+// user-supplied URLs to SaveAndShare. Addresses are checked when connecting, after DNS
+// resolution and for every redirect, so hosts resolving to internal addresses are refused
+// too. The zero value allows http and https URLs of public addresses.
+type URLPolicy struct {
+	// Schemes are the allowed URL schemes, http and https if empty
+	Schemes []string
+	// Hosts, if set, are the only allowed host names; "*.example.com" matches subdomains
+	Hosts []string
+	// DeniedNetworks are refused in addition to the internal networks
+	DeniedNetworks []netip.Prefix
+	// AllowPrivateNetworks allows loopback and private addresses. Link-local addresses,
+	// which include cloud metadata endpoints, are always refused.
+	AllowPrivateNetworks bool
+	// MaxRedirects limits the redirects followed, 5 if zero; negative follows none
+	MaxRedirects int
+}
+
+// checkURL checks the scheme and host of u, and its address if it is an IP literal
+func (p *URLPolicy) checkURL(u *url.URL) error {
+	schemes := p.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	if !slices.Contains(schemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("%w: scheme %q", ErrURLNotAllowed, u.Scheme)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("%w: no host", ErrURLNotAllowed)
+	}
+	if len(p.Hosts) > 0 && !slices.ContainsFunc(p.Hosts, func(allowed string) bool {
+		return matchHost(strings.ToLower(allowed), host)
+	}) {
+		return fmt.Errorf("%w: host %s", ErrURLNotAllowed, host)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.checkAddr(addr)
+	}
+	return nil
+}
+
+// matchHost reports whether host matches the pattern, an exact name or "*." followed by a
+// domain matching its subdomains
+func matchHost(pattern string, host string) bool {
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+domain)
+	}
+	return host == pattern
+}
+
+// checkAddr checks an address the download connects to
+func (p *URLPolicy) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	switch {
+	case addr.IsLinkLocalUnicast(), addr.IsLinkLocalMulticast(), addr.IsUnspecified(), addr.IsMulticast():
+		return fmt.Errorf("%w: address %s", ErrURLNotAllowed, addr)
+	case !p.AllowPrivateNetworks && (addr.IsLoopback() || addr.IsPrivate() || sharedAddressSpace.Contains(addr)):
+		return fmt.Errorf("%w: internal address %s", ErrURLNotAllowed, addr)
+	}
+	for _, network := range p.DeniedNetworks {
+		if network.Contains(addr) {
+			return fmt.Errorf("%w: address %s in denied network %s", ErrURLNotAllowed, addr, network)
+		}
+	}
+	return nil
+}
+
+// httpClient creates an HTTP client enforcing the policy. It connects directly, as the
+// addresses of a proxy would be checked instead of the ones of the target.
+func (p *URLPolicy) httpClient() *req.Client {
+	dialer := &net.Dialer{
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: address %s", ErrURLNotAllowed, address)
+			}
+			return p.checkAddr(addrPort.Addr())
+		},
+	}
+
+	maxRedirects := p.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	return req.C().
+		SetCookieJar(nil).
+		SetProxy(nil).
+		SetDial(dialer.DialContext).
+		SetRedirectPolicy(func(r *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("%w: more than %d redirects", ErrURLNotAllowed, max(maxRedirects, 0))
+			}
+			return p.checkURL(r.URL)
+		})
+}
@@ -0,0 +1,99 @@
+package filebrowser
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestURLPolicyCheckURL(t *testing.T) {
+	tests := []struct {
+		policy URLPolicy
+		url    string
+		ok     bool
+	}{
+		{URLPolicy{}, "https://example.com/a.txt", true},
+		{URLPolicy{}, "ftp://example.com/a.txt", false},
+		{URLPolicy{}, "file:///etc/passwd", false},
+		{URLPolicy{}, "http://127.0.0.1/", false},
+		{URLPolicy{}, "http://10.1.2.3/", false},
+		{URLPolicy{}, "http://100.64.0.1/", false},
+		{URLPolicy{}, "http://[::1]/", false},
+		{URLPolicy{}, "http://[::ffff:192.168.0.1]/", false},
+		{URLPolicy{}, "http://169.254.169.254/latest/meta-data/", false},
+		{URLPolicy{}, "http://0.0.0.0/", false},
+		{URLPolicy{AllowPrivateNetworks: true}, "http://127.0.0.1/", true},
+		{URLPolicy{AllowPrivateNetworks: true}, "http://169.254.169.254/", false},
+		{URLPolicy{DeniedNetworks: []netip.Prefix{netip.MustParsePrefix("93.184.0.0/16")}}, "http://93.184.216.34/", false},
+		{URLPolicy{Hosts: []string{"*.example.com"}}, "https://cdn.example.com/a", true},
+		{URLPolicy{Hosts: []string{"*.example.com"}}, "https://example.com/a", false},
+		{URLPolicy{Hosts: []string{"example.com"}}, "https://EXAMPLE.com./a", true},
+		{URLPolicy{Hosts: []string{"example.com"}}, "https://example.com.evil.net/a", false},
+		{URLPolicy{Schemes: []string{"https"}}, "http://example.com/a", false},
+	}
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = test.policy.checkURL(u)
+		if (err == nil) != test.ok || (err != nil && !errors.Is(err, ErrURLNotAllowed)) {
+			t.Errorf("checkURL(%s) with %+v error = %v, want ok = %v", test.url, test.policy, err, test.ok)
+		}
+	}
+}
+
+func TestURLPolicyDownload(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/metadata":
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/hops/"):
+			n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hops/"))
+			if n > 0 {
+				http.Redirect(w, r, "/hops/"+strconv.Itoa(n-1), http.StatusFound)
+				return
+			}
+			w.Write([]byte("arrived"))
+		default:
+			w.Write([]byte("content"))
+		}
+	}))
+	defer origin.Close()
+	port := origin.URL[strings.LastIndex(origin.URL, ":"):]
+
+	// Host names resolving to internal addresses are refused when connecting
+	_, err := DownloadToLocalWithOptions("http://localhost"+port+"/a.txt", 0, DownloadOptions{URLPolicy: &URLPolicy{}})
+	if !errors.Is(err, ErrURLNotAllowed) {
+		t.Errorf("download via localhost error = %v, want ErrURLNotAllowed", err)
+	}
+
+	local := &URLPolicy{AllowPrivateNetworks: true, MaxRedirects: 2}
+	if _, err := DownloadToLocalWithOptions(origin.URL+"/a.txt", 0, DownloadOptions{URLPolicy: local}); err != nil {
+		t.Errorf("allowed download error = %v", err)
+	}
+	if _, err := DownloadToLocalWithOptions(origin.URL+"/metadata", 0, DownloadOptions{URLPolicy: local}); !errors.Is(err, ErrURLNotAllowed) {
+		t.Errorf("redirect to metadata endpoint error = %v, want ErrURLNotAllowed", err)
+	}
+	if _, err := DownloadToLocalWithOptions(origin.URL+"/hops/2", 0, DownloadOptions{URLPolicy: local}); err != nil {
+		t.Errorf("download with 2 redirects error = %v", err)
+	}
+	if _, err := DownloadToLocalWithOptions(origin.URL+"/hops/3", 0, DownloadOptions{URLPolicy: local}); !errors.Is(err, ErrURLNotAllowed) {
+		t.Errorf("download with 3 redirects error = %v, want ErrURLNotAllowed", err)
+	}
+
+	// SaveAndShare refuses internal addresses by default
+	server := newMemServer(t, nil)
+	auth := FilebrowserAuth{URL: server.URL, Username: "user", Password: "pass"}
+	_, err = SaveAndShare(auth, origin.URL+"/b.txt", func(name string) string { return name }, ActionParams{})
+	if !errors.Is(err, ErrURLNotAllowed) {
+		t.Errorf("SaveAndShare() of internal URL error = %v, want ErrURLNotAllowed", err)
+	}
+}
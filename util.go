@@ -2,8 +2,8 @@ package filebrowser
 
 import (
 	"fmt"
-	"log"
-	"path/filepath"
+	"io/fs"
+	"time"
 )
 
 // ActionParams contains parameters for file operations
@@ -11,19 +11,84 @@ type ActionParams struct {
 	ShareParams ShareParams
 	FileSize    int64
 	Force       bool
+	// Skip decides whether an existing remote file is kept instead of uploading, by its size
+	// if zero
+	Skip SkipStrategy
+
+	// Stats, if set, receives statistics for the download and every client operation
+	Stats StatsCollector
+	// PasswordPolicy, if set, is enforced for ShareParams.Password before anything is transferred
+	PasswordPolicy *SharePasswordPolicy
+	// Retry, if set, retries transient failures of the client operations instead of
+	// Defaults.Retry
+	Retry *RetryPolicy
+	// Logger, if set, receives the log messages of the download and the client instead of
+	// Defaults.Logger
+	Logger Logger
+	// PreUploadHook, if set, is consulted before the file is uploaded
+	PreUploadHook PreUploadHook
+	// CreateParents creates the missing parent directories of the remote path first
+	CreateParents bool
+	// FileMode, if set, is applied to the uploaded file, e.g. 0o755 to make scripts
+	// executable, see Client.FileMode
+	FileMode fs.FileMode
+	// ReuseShare returns an existing share of the file instead of creating another one if
+	// it has no password and stays valid at least as long as requested. Shares requested
+	// with a password are always created.
+	ReuseShare bool
+	// MaxDownloadBytes, if positive, aborts the download of the external URL once it
+	// exceeds this size, with a DownloadTooLargeError
+	MaxDownloadBytes int64
+	// DownloadConnections, if greater than one, downloads the external URL in parallel
+	// ranges over this many connections, see DownloadOptions.Connections
+	DownloadConnections int
+	// SourceAuth, if set, authenticates the download of the external URL
+	SourceAuth *SourceAuth
+	// URLPolicy restricts the external URLs that may be downloaded. If nil, the zero
+	// URLPolicy applies, refusing internal addresses.
+	URLPolicy *URLPolicy
+	// DryRun only plans the run: the result has no URLs but the Plan of what would be
+	// downloaded, uploaded and shared. Nothing is downloaded or changed, except that the
+	// client logs in. Strict mode and SkipByHash are not verified in dry runs,
+	// existing files are planned to be kept by their size.
+	DryRun bool
+	// Strict verifies the checksum of an existing remote file that would be kept instead of
+	// trusting its size, failing with a ContentMismatchError if the content differs
+	Strict bool
 }
 
 // ShareParams contains parameters for sharing files
 type ShareParams struct {
-	Expires  int64  // Expiration time
-	Password string // Optional password protection
-	Unit     string // Time unit (e.g., "hours", "days")
+	// Expiry is when the share expires, e.g. ExpireAfter(7*24*time.Hour). It replaces
+	// Expires and Unit, which must then be unset.
+	Expiry ShareExpiry
+
+	// Deprecated: Use Expiry. Expires is the expiration in Unit, zero never expires.
+	Expires int64
+	// Deprecated: Use Expiry. Unit is "seconds", "minutes", "hours" (if empty) or "days".
+	Unit string
+
+	Password string // Optional password protection, only applied to expiring shares
 }
 
 // ShareResult contains the URLs for viewing and downloading shared files
 type ShareResult struct {
 	ViewUrl     string
 	DownloadUrl string
+
+	Hash              string    // Hash identifying the share on the server
+	RemotePath        string    // Shared file
+	Expires           time.Time // Expiry of the share, zero if it never expires
+	PasswordProtected bool
+
+	// Reused is set if an existing share was returned instead of creating one
+	Reused bool
+	// CreatedAt is the creation time of the share. It is zero for reused shares, as the
+	// server does not record it.
+	CreatedAt time.Time
+
+	// Plan is the outcome of a dry run, see ActionParams.DryRun
+	Plan *Plan
 }
 
 // FilebrowserAuth contains authentication credentials for Filebrowser
@@ -49,88 +114,9 @@ func (auth *FilebrowserAuth) Validate() error {
 
 // SaveAndShare downloads a file from an external URL, uploads it to Filebrowser,
 // and creates a share link. It handles file size comparison and force overwrite.
+// Calls for the same remote file are serialized within the process, and a file changed
+// by someone else before it is replaced fails with ErrConcurrentModification.
+// It runs the default Pipeline, which allows adding steps.
 func SaveAndShare(auth FilebrowserAuth, externalURL string, remotePathFn func(string) string, actionParams ActionParams) (*ShareResult, error) {
-	// Validate authentication
-	if err := auth.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid authentication: %w", err)
-	}
-
-	// Validate input parameters
-	if externalURL == "" {
-		return nil, fmt.Errorf("external URL cannot be empty")
-	}
-	if remotePathFn == nil {
-		return nil, fmt.Errorf("remote path function cannot be nil")
-	}
-
-	// Download file to local
-	localPath, err := DownloadToLocal(externalURL, actionParams.FileSize)
-	if err != nil {
-		return nil, fmt.Errorf("failed to download file: %w", err)
-	}
-
-	// Generate remote path
-	name := filepath.Base(localPath)
-	remotePath := remotePathFn(name)
-	if remotePath == "" {
-		return nil, fmt.Errorf("remote path cannot be empty")
-	}
-
-	// Create client and authenticate
-	client := &Client{
-		URL: auth.URL,
-		ReqLogin: ReqLogin{
-			Username: auth.Username,
-			Password: auth.Password,
-		},
-	}
-
-	// Check if resource exists and handle size comparison
-	resourceRet, err := client.GetResource(remotePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get resource info: %w", err)
-	}
-
-	// Handle file size comparison and force overwrite
-	shouldUpload := true
-	if !resourceRet.NotExist {
-		if actionParams.Force {
-			log.Printf("Force flag set, deleting existing resource: %s", remotePath)
-			if err := client.DeleteResource(remotePath); err != nil {
-				return nil, fmt.Errorf("failed to delete existing resource: %w", err)
-			}
-		} else if actionParams.FileSize > 0 && resourceRet.Size != actionParams.FileSize {
-			log.Printf("File size mismatch, deleting existing resource: %s (local: %d, remote: %d)", 
-				remotePath, actionParams.FileSize, resourceRet.Size)
-			if err := client.DeleteResource(remotePath); err != nil {
-				return nil, fmt.Errorf("failed to delete mismatched resource: %w", err)
-			}
-		} else {
-			log.Printf("Resource already exists with same size, skipping upload: %s", remotePath)
-			shouldUpload = false
-		}
-	}
-
-	// Upload file if needed
-	if shouldUpload {
-		if err := client.Upload(localPath, remotePath); err != nil {
-			return nil, fmt.Errorf("failed to upload file: %w", err)
-		}
-		log.Printf("Successfully uploaded file to: %s", remotePath)
-	}
-
-	// Create share
-	hash, err := client.Share(remotePath, actionParams.ShareParams.Expires, 
-		actionParams.ShareParams.Password, actionParams.ShareParams.Unit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create share: %w", err)
-	}
-
-	result := &ShareResult{
-		ViewUrl:     fmt.Sprintf("%s/share/%s", client.URL, hash),
-		DownloadUrl: fmt.Sprintf("%s/api/public/dl/%s", client.URL, hash),
-	}
-
-	log.Printf("Successfully created share: %s", result.ViewUrl)
-	return result, nil
+	return (&Pipeline{}).Run(auth, externalURL, remotePathFn, actionParams)
 }
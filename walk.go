@@ -0,0 +1,223 @@
+package filebrowser
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// WalkFunc is called by Walk for every file and directory below the root, directories
+// before their contents. Returning fs.SkipDir for a directory skips its contents; any
+// other error stops the walk and is returned by Walk.
+type WalkFunc func(resource *RespResource) error
+
+// checkpointVersion is the version of the encoding of checkpoints
+const checkpointVersion = 1
+
+// WalkOptions contains optional parameters for WalkWithOptions
+type WalkOptions struct {
+	// Deadline, if set, interrupts the walk once passed. Directories are listed and
+	// visited as a whole, so the walk may overrun it by the time of one directory.
+	Deadline time.Time
+	// Checkpoint resumes the interrupted walk that returned it
+	Checkpoint Checkpoint
+	// Filter, if set, limits the files passed to the walk function, matching their paths
+	// relative to the root. Directories are always passed and walked.
+	Filter Filter
+}
+
+// Checkpoint is an opaque token returned by an interrupted traversal, to be persisted and
+// passed back to resume it, possibly in another process. The empty checkpoint marks a
+// traversal that started from the root or completed.
+type Checkpoint string
+
+// walkCheckpoint is the content of a Checkpoint
+type walkCheckpoint struct {
+	Version int      `json:"v"`
+	Root    string   `json:"root"`
+	Pending []string `json:"pending"` // Directories not listed yet, the next one last
+}
+
+// newCheckpoint encodes the state of a walk interrupted with pending directories
+func newCheckpoint(root string, pending []string) Checkpoint {
+	data, _ := json.Marshal(walkCheckpoint{Version: checkpointVersion, Root: resourcePath(root), Pending: pending})
+	return Checkpoint(base64.RawURLEncoding.EncodeToString(data))
+}
+
+// pending decodes the directories left by the walk of root that returned the checkpoint
+func (cp Checkpoint) pending(root string) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(string(cp))
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	var state walkCheckpoint
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	if state.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d", state.Version)
+	}
+	if state.Root != resourcePath(root) {
+		return nil, fmt.Errorf("cannot resume walk of %s at %s", state.Root, root)
+	}
+	return state.Pending, nil
+}
+
+// Walk traverses the remote tree rooted at root, calling fn for every resource below it.
+// Directories removed while the walk is in progress are skipped.
+func (c *Client) Walk(root string, fn WalkFunc) error {
+	_, err := c.WalkWithOptions(root, fn, WalkOptions{})
+	return err
+}
+
+// WalkWithOptions traverses the remote tree like Walk, applying the given options. If the
+// deadline passes before the walk completes, the checkpoint to resume it is returned
+// without error; a completed walk returns an empty checkpoint.
+func (c *Client) WalkWithOptions(root string, fn WalkFunc, opts WalkOptions) (_ Checkpoint, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if fn == nil {
+		return "", fmt.Errorf("walk function cannot be nil")
+	}
+
+	pending := []string{resourcePath(root)}
+	if opts.Checkpoint != "" {
+		if pending, err = opts.Checkpoint.pending(root); err != nil {
+			return "", err
+		}
+	}
+
+	for len(pending) > 0 {
+		if !opts.Deadline.IsZero() && time.Now().After(opts.Deadline) {
+			return newCheckpoint(root, pending), nil
+		}
+
+		dir := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		listing, err := c.lookupResource(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to list %s: %w", dir, err)
+		}
+		if listing == nil {
+			if dir == resourcePath(root) {
+				return "", fmt.Errorf("cannot walk %s: %w", root, ErrNotFound)
+			}
+			continue
+		}
+
+		var subdirs []string
+		for i := range listing.Items {
+			item := &listing.Items[i]
+			if !item.IsDir && !matchFilter(opts.Filter, FilterEntry{Path: relativePath(root, item.Path), Size: item.Size, Modified: item.Modified}) {
+				continue
+			}
+			if err := fn(item); err != nil {
+				if errors.Is(err, fs.SkipDir) && item.IsDir {
+					continue
+				}
+				return "", err
+			}
+			if item.IsDir {
+				subdirs = append(subdirs, resourcePath(item.Path))
+			}
+		}
+
+		// Push in reverse so directories are visited in listing order
+		for i := len(subdirs) - 1; i >= 0; i-- {
+			pending = append(pending, subdirs[i])
+		}
+	}
+
+	return "", nil
+}
+
+// ListChangedSince returns every file below root modified after t, so incremental
+// processors can fetch only what changed since their last run. The whole tree is
+// walked, as the server offers no modification time filter.
+func (c *Client) ListChangedSince(root string, t time.Time) ([]RespResource, error) {
+	changed, _, err := c.ListChangedSinceWithOptions(root, t, WalkOptions{})
+	return changed, err
+}
+
+// ListChangedSinceWithOptions lists changed files like ListChangedSince, applying the
+// given walk options. An interrupted walk returns the files found so far together with
+// the checkpoint to resume it.
+func (c *Client) ListChangedSinceWithOptions(root string, t time.Time, opts WalkOptions) ([]RespResource, Checkpoint, error) {
+	var changed []RespResource
+	checkpoint, err := c.WalkWithOptions(root, func(resource *RespResource) error {
+		if resource.IsDir {
+			return nil
+		}
+
+		if resource.Modified.After(t) {
+			changed = append(changed, *resource)
+		}
+		return nil
+	}, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return changed, checkpoint, nil
+}
+
+// LatestIn returns the most recently modified file directly in dir whose name matches
+// pattern, a path.Match pattern such as "export-*.csv", e.g. to consume the newest export
+// dropped into a folder. An empty pattern matches every file. Of files modified at the
+// same time the one with the greatest name is returned. Without a matching file the error
+// matches ErrNotFound.
+func (c *Client) LatestIn(dir string, pattern string) (_ *RespResource, err error) {
+	defer func() { err = c.redactError(err) }()
+
+	if pattern != "" {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	resource, err := c.GetResource(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !resource.IsDir {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	var latest *RespResource
+	for i, item := range resource.Items {
+		if item.IsDir {
+			continue
+		}
+		if matched, _ := path.Match(pattern, item.Name); pattern != "" && !matched {
+			continue
+		}
+		if latest == nil || item.Modified.After(latest.Modified) ||
+			(item.Modified.Equal(latest.Modified) && item.Name > latest.Name) {
+			latest = &resource.Items[i]
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no file matching %q in %s: %w", pattern, dir, ErrNotFound)
+	}
+	return latest, nil
+}
+
+// relativePath returns the slash-separated path of the resource at remotePath relative to
+// root, see FilterEntry.Path
+func relativePath(root string, remotePath string) string {
+	return strings.TrimPrefix("/"+strings.TrimPrefix(remotePath, "/"), manifestPrefix(root))
+}
+
+// resourcePath converts a path as returned by the server into one accepted by GetResource
+func resourcePath(p string) string {
+	if p = strings.TrimPrefix(p, "/"); p == "" {
+		return "/"
+	}
+	return p
+}
@@ -0,0 +1,166 @@
+package filebrowser
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWalk(t *testing.T) {
+	server := newTreeServer(t, map[string]testFile{
+		"a.txt":           {content: []byte("a")},
+		"dir/b.txt":       {content: []byte("b")},
+		"dir/sub/c.txt":   {content: []byte("c")},
+		"skipped/d.txt":   {content: []byte("d")},
+		"skipped/e/f.txt": {content: []byte("f")},
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	var visited []string
+	err := client.Walk("/", func(resource *RespResource) error {
+		visited = append(visited, resource.Path)
+		if resource.Path == "/skipped" {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	expected := []string{"/a.txt", "/dir", "/skipped", "/dir/b.txt", "/dir/sub", "/dir/sub/c.txt"}
+	if len(visited) != len(expected) {
+		t.Fatalf("Walk() visited %v, want %v", visited, expected)
+	}
+	for i := range expected {
+		if visited[i] != expected[i] {
+			t.Errorf("Walk() visited %v, want %v", visited, expected)
+			break
+		}
+	}
+
+	if err := client.Walk("missing", func(*RespResource) error { return nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Walk() on missing root error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListChangedSince(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	server := newTreeServer(t, map[string]testFile{
+		"old.txt":         {content: []byte("old"), modified: since.Add(-time.Hour)},
+		"new.txt":         {content: []byte("new"), modified: since.Add(time.Hour)},
+		"logs/old.log":    {content: []byte("old"), modified: since.Add(-time.Minute)},
+		"logs/recent.log": {content: []byte("recent"), modified: since.Add(time.Minute)},
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	changed, err := client.ListChangedSince("/", since)
+	if err != nil {
+		t.Fatalf("ListChangedSince() error = %v", err)
+	}
+
+	if len(changed) != 2 || changed[0].Path != "/new.txt" || changed[1].Path != "/logs/recent.log" {
+		t.Errorf("ListChangedSince() = %+v, want /new.txt and /logs/recent.log", changed)
+	}
+}
+
+func TestLatestIn(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	server := newTreeServer(t, map[string]testFile{
+		"exports/export-1.csv":  {content: []byte("1"), modified: day},
+		"exports/export-2.csv":  {content: []byte("2"), modified: day.Add(time.Hour)},
+		"exports/export-3.csv":  {content: []byte("3"), modified: day.Add(time.Hour)},
+		"exports/notes.txt":     {content: []byte("notes"), modified: day.Add(2 * time.Hour)},
+		"exports/old/later.csv": {content: []byte("nested"), modified: day.Add(3 * time.Hour)},
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"export-*.csv", "/exports/export-3.csv"}, // Tie broken by name, nested files ignored
+		{"", "/exports/notes.txt"},
+		{"*.txt", "/exports/notes.txt"},
+	}
+	for _, tt := range tests {
+		latest, err := client.LatestIn("exports", tt.pattern)
+		if err != nil || latest.Path != tt.want {
+			t.Errorf("LatestIn(%q) = %v, %v, want %s", tt.pattern, latest, err, tt.want)
+		}
+	}
+
+	if _, err := client.LatestIn("exports", "*.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LatestIn() without a match error = %v, want ErrNotFound", err)
+	}
+	if _, err := client.LatestIn("exports", "["); err == nil {
+		t.Error("LatestIn() with an invalid pattern succeeded")
+	}
+}
+
+func TestWalkDeadline(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	server := newTreeServer(t, map[string]testFile{
+		"a/1.txt": {modified: since.Add(time.Hour)},
+		"b/2.txt": {modified: since.Add(time.Hour)},
+		"c/3.txt": {modified: since.Add(time.Hour)},
+	})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	// A passed deadline interrupts before the first listing
+	changed, checkpoint, err := client.ListChangedSinceWithOptions("/", since, WalkOptions{Deadline: time.Now().Add(-time.Second)})
+	if err != nil || checkpoint == "" || len(changed) != 0 {
+		t.Fatalf("ListChangedSinceWithOptions() = %v, %q, %v, want interrupted without results", changed, checkpoint, err)
+	}
+
+	// Resume one directory at a time
+	var all []string
+	for runs := 0; checkpoint != ""; runs++ {
+		if runs > 10 {
+			t.Fatal("walk did not complete")
+		}
+
+		var visited int
+		checkpoint, err = client.WalkWithOptions("/", func(resource *RespResource) error {
+			visited++
+			if !resource.IsDir {
+				all = append(all, resource.Path)
+			}
+			return nil
+		}, WalkOptions{Checkpoint: checkpoint, Deadline: time.Now().Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("WalkWithOptions() error = %v", err)
+		}
+		if visited == 0 {
+			t.Fatal("resumed walk made no progress")
+		}
+	}
+
+	if strings.Join(all, ",") != "/a/1.txt,/b/2.txt,/c/3.txt" {
+		t.Errorf("resumed walks visited %v", all)
+	}
+}
+
+func TestWalkCheckpointMismatch(t *testing.T) {
+	server := newTreeServer(t, map[string]testFile{"a/1.txt": {}})
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+
+	checkpoint, err := client.WalkWithOptions("a", func(*RespResource) error { return nil }, WalkOptions{Deadline: time.Now().Add(-time.Second)})
+	if err != nil || checkpoint == "" {
+		t.Fatalf("WalkWithOptions() = %q, %v, want checkpoint", checkpoint, err)
+	}
+
+	for _, tt := range []struct {
+		root       string
+		checkpoint Checkpoint
+	}{
+		{"/", checkpoint},
+		{"a", "not a checkpoint"},
+	} {
+		if _, err := client.WalkWithOptions(tt.root, func(*RespResource) error { return nil }, WalkOptions{Checkpoint: tt.checkpoint}); err == nil {
+			t.Errorf("WalkWithOptions(%q) resuming %q should fail", tt.root, tt.checkpoint)
+		}
+	}
+}
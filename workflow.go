@@ -0,0 +1,280 @@
+package filebrowser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WorkflowState is the state of a publishing workflow
+type WorkflowState string
+
+// Workflow states. Workflows move from staged to approved or rejected, and from there to
+// published or cleaned respectively.
+const (
+	WorkflowStaged    WorkflowState = "staged"
+	WorkflowApproved  WorkflowState = "approved"
+	WorkflowRejected  WorkflowState = "rejected"
+	WorkflowPublished WorkflowState = "published"
+	WorkflowCleaned   WorkflowState = "cleaned"
+)
+
+// workflowTransitions lists the states each state may move to
+var workflowTransitions = map[WorkflowState][]WorkflowState{
+	WorkflowStaged:   {WorkflowApproved, WorkflowRejected},
+	WorkflowApproved: {WorkflowPublished},
+	WorkflowRejected: {WorkflowCleaned},
+}
+
+// ErrInvalidTransition is returned when a workflow cannot move to the requested state
+var ErrInvalidTransition = errors.New("invalid workflow transition")
+
+// Workflow tracks a staged upload through review and publishing
+type Workflow struct {
+	ID        string        `json:"id"`
+	State     WorkflowState `json:"state"`
+	Staged    StagedUpload  `json:"staged"`
+	Share     ShareParams   `json:"share"`
+	ShareHash string        `json:"share_hash,omitempty"` // Set once published
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// WorkflowStore persists workflows so they can be resumed by another process or after a
+// crash. Implementations must be safe for concurrent use.
+type WorkflowStore interface {
+	Save(workflow Workflow) error
+	// Load returns the workflow with the given ID, or an error matching ErrNotFound
+	Load(id string) (Workflow, error)
+	List() ([]Workflow, error)
+}
+
+// WorkflowEngine drives staged uploads through review and publishing, persisting every
+// state change before acting on it so interrupted workflows can be resumed
+type WorkflowEngine struct {
+	Client *Client
+	Store  WorkflowStore
+	// StagingDir is the remote directory uploads are staged in, "staging" if empty
+	StagingDir string
+}
+
+// Start stages a local file for review and records a workflow for it. The share
+// parameters are stored with the workflow and used when it is published.
+func (e *WorkflowEngine) Start(localPath string, publicPath string, share ShareParams) (*Workflow, error) {
+	staged, err := e.Client.Stage(localPath, publicPath, e.StagingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	workflow := Workflow{
+		ID:        path.Base(staged.Dir),
+		State:     WorkflowStaged,
+		Staged:    *staged,
+		Share:     share,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := e.Store.Save(workflow); err != nil {
+		return nil, fmt.Errorf("failed to save workflow: %w", err)
+	}
+
+	return &workflow, nil
+}
+
+// Approve approves a staged workflow and publishes it
+func (e *WorkflowEngine) Approve(id string) (*Workflow, error) {
+	return e.decide(id, WorkflowApproved)
+}
+
+// Reject rejects a staged workflow and cleans up its staged upload
+func (e *WorkflowEngine) Reject(id string) (*Workflow, error) {
+	return e.decide(id, WorkflowRejected)
+}
+
+// Resume completes every approved or rejected workflow that was interrupted before it
+// was published or cleaned, returning the number of workflows completed
+func (e *WorkflowEngine) Resume() (int, error) {
+	workflows, err := e.Store.List()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	var completed int
+	var errs []error
+	for _, workflow := range workflows {
+		if workflow.State != WorkflowApproved && workflow.State != WorkflowRejected {
+			continue
+		}
+		if err := e.complete(&workflow); err != nil {
+			errs = append(errs, fmt.Errorf("workflow %s: %w", workflow.ID, err))
+			continue
+		}
+		completed++
+	}
+
+	return completed, errors.Join(errs...)
+}
+
+// decide records a review decision for a workflow and completes it
+func (e *WorkflowEngine) decide(id string, state WorkflowState) (*Workflow, error) {
+	workflow, err := e.Store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.transition(&workflow, state); err != nil {
+		return nil, err
+	}
+	if err := e.complete(&workflow); err != nil {
+		return nil, err
+	}
+
+	return &workflow, nil
+}
+
+// complete publishes an approved workflow or cleans up a rejected one. Both are safe to
+// repeat after an interruption.
+func (e *WorkflowEngine) complete(workflow *Workflow) error {
+	c := e.Client
+	staged := workflow.Staged
+
+	switch workflow.State {
+	case WorkflowApproved:
+		resource, err := c.lookupResource(staged.StagedPath)
+		if err != nil {
+			return fmt.Errorf("failed to get staged resource info: %w", err)
+		}
+
+		// A missing staged file was already moved before an interruption
+		if resource == nil {
+			if err := c.DeleteResource(staged.Dir); err != nil {
+				c.log().Warn("Failed to clean up staging directory", "path", staged.Dir, "error", err)
+			}
+			workflow.ShareHash, err = c.ShareIfExists(staged.PublicPath, workflow.Share)
+		} else {
+			workflow.ShareHash, err = c.Publish(&staged, workflow.Share)
+		}
+		if err != nil {
+			return err
+		}
+
+		return e.transition(workflow, WorkflowPublished)
+	case WorkflowRejected:
+		if err := c.Discard(&staged); err != nil {
+			return err
+		}
+
+		return e.transition(workflow, WorkflowCleaned)
+	default:
+		return fmt.Errorf("cannot complete workflow in state %s: %w", workflow.State, ErrInvalidTransition)
+	}
+}
+
+// transition moves a workflow to the given state and persists it
+func (e *WorkflowEngine) transition(workflow *Workflow, state WorkflowState) error {
+	allowed := false
+	for _, next := range workflowTransitions[workflow.State] {
+		allowed = allowed || next == state
+	}
+	if !allowed {
+		return fmt.Errorf("cannot move workflow %s from %s to %s: %w", workflow.ID, workflow.State, state, ErrInvalidTransition)
+	}
+
+	workflow.State = state
+	workflow.UpdatedAt = time.Now()
+	if err := e.Store.Save(*workflow); err != nil {
+		return fmt.Errorf("failed to save workflow: %w", err)
+	}
+	return nil
+}
+
+// FileWorkflowStore is a WorkflowStore backed by a JSON file. Share passwords are stored
+// with their workflows, so the file is only readable by its owner.
+type FileWorkflowStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileWorkflowStore creates a workflow store persisted at the given path.
+// The file is created on the first save.
+func NewFileWorkflowStore(path string) *FileWorkflowStore {
+	return &FileWorkflowStore{path: path}
+}
+
+// Save adds or replaces a workflow
+func (s *FileWorkflowStore) Save(workflow Workflow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workflows, err := s.load()
+	if err != nil {
+		return err
+	}
+	workflows[workflow.ID] = workflow
+	return s.store(workflows)
+}
+
+// Load returns the workflow with the given ID
+func (s *FileWorkflowStore) Load(id string) (Workflow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workflows, err := s.load()
+	if err != nil {
+		return Workflow{}, err
+	}
+	workflow, ok := workflows[id]
+	if !ok {
+		return Workflow{}, fmt.Errorf("workflow %s: %w", id, ErrNotFound)
+	}
+	return workflow, nil
+}
+
+// List returns all workflows ordered by creation time
+func (s *FileWorkflowStore) List() ([]Workflow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workflows, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Workflow, 0, len(workflows))
+	for _, workflow := range workflows {
+		list = append(list, workflow)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+// load reads all workflows from disk
+func (s *FileWorkflowStore) load() (map[string]Workflow, error) {
+	workflows := map[string]Workflow{}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return workflows, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &workflows); err != nil {
+		return nil, fmt.Errorf("failed to decode workflow store: %w", err)
+	}
+	return workflows, nil
+}
+
+// store writes all workflows to disk, replacing the file atomically
+func (s *FileWorkflowStore) store(workflows map[string]Workflow) error {
+	if err := writeJSONFileAtomic(s.path, workflows); err != nil {
+		return fmt.Errorf("failed to write workflow store: %w", err)
+	}
+	return nil
+}
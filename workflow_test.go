@@ -0,0 +1,87 @@
+package filebrowser
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkflowEngine(t *testing.T) {
+	localPath, _ := writeTestFile(t, 64)
+	server := newMemServer(t, nil)
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	engine := &WorkflowEngine{Client: client, Store: NewFileWorkflowStore(filepath.Join(t.TempDir(), "workflows.json"))}
+
+	approved, err := engine.Start(localPath, "public/a.bin", ShareParams{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	rejected, err := engine.Start(localPath, "public/b.bin", ShareParams{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	workflow, err := engine.Approve(approved.ID)
+	if err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if workflow.State != WorkflowPublished || workflow.ShareHash == "" {
+		t.Errorf("Approve() = %+v, want published with share hash", workflow)
+	}
+	if _, ok := server.file("public/a.bin"); !ok {
+		t.Error("approved file was not published")
+	}
+
+	if workflow, err = engine.Reject(rejected.ID); err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+	if workflow.State != WorkflowCleaned {
+		t.Errorf("Reject() state = %v, want cleaned", workflow.State)
+	}
+	if _, ok := server.file(rejected.Staged.StagedPath); ok {
+		t.Error("rejected file was not cleaned up")
+	}
+
+	if _, err := engine.Approve(rejected.ID); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Approve() of cleaned workflow error = %v, want ErrInvalidTransition", err)
+	}
+	if _, err := engine.Approve("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Approve() of unknown workflow error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWorkflowEngineResume(t *testing.T) {
+	localPath, _ := writeTestFile(t, 64)
+	server := newMemServer(t, nil)
+	client := &Client{URL: server.URL, ReqLogin: ReqLogin{Username: "user", Password: "pass"}}
+	store := NewFileWorkflowStore(filepath.Join(t.TempDir(), "workflows.json"))
+	engine := &WorkflowEngine{Client: client, Store: store}
+
+	workflow, err := engine.Start(localPath, "public/a.bin", ShareParams{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Simulate a crash after the approval was recorded and the file moved
+	workflow.State = WorkflowApproved
+	if err := store.Save(*workflow); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := client.Move(workflow.Staged.StagedPath, workflow.Staged.PublicPath, false); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+
+	resumed := &WorkflowEngine{Client: client, Store: NewFileWorkflowStore(store.path)}
+	completed, err := resumed.Resume()
+	if err != nil || completed != 1 {
+		t.Fatalf("Resume() = %d, %v, want 1 completed", completed, err)
+	}
+
+	loaded, err := store.Load(workflow.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.State != WorkflowPublished || loaded.ShareHash == "" {
+		t.Errorf("resumed workflow = %+v, want published with share hash", loaded)
+	}
+}